@@ -0,0 +1,525 @@
+// Package aerospiketest provides utilities for testing code that uses aerospike without a server.
+package aerospiketest
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/aerospike/aerospike-client-go/v8/types"
+
+	goaerospike "github.com/viru-tech/go.aerospike"
+)
+
+const (
+	// maxBinNameLength is the longest bin name accepted by the server.
+	maxBinNameLength = 15
+	// maxGeneration is the generation after which the server wraps it around to 1.
+	maxGeneration = math.MaxUint16
+)
+
+var _ goaerospike.Client = (*Fake)(nil)
+
+// Fake is an in-memory implementation of goaerospike.Client for tests.
+// Like the server, it keeps record generations, expires records by TTL according to its clock,
+// enforces record exists actions and generation policies, and fails with errors unwrapping
+// to *aerospike.AerospikeError holding the same result codes. Values are stored the way the client reads them back,
+// e.g. all integers become int.
+//
+// Operations supported by Operate are reads of bins and headers, writes, Add, Append, Prepend,
+// Touch, Delete, ListGetByIndexOp and MapGetByKeyOp returning values. Queries support
+// secondary index filters on bins, without checking that the index exists.
+// Filter expressions and other operations fail with UNSUPPORTED_FEATURE.
+type Fake struct {
+	mu         sync.Mutex
+	now        time.Time
+	defaultTTL time.Duration
+	// records are keyed by the namespace and the digest of the key.
+	records map[string]*record
+}
+
+type record struct {
+	namespace string
+	set       string
+	digest    []byte
+	// userKey is the user key, stored only if the key was sent with a write.
+	userKey    aerospike.Value
+	bins       map[string]any
+	generation uint32
+	// expiresAt is zero for records that never expire.
+	expiresAt  time.Time
+	lastUpdate time.Time
+}
+
+// FakeOption configures a Fake.
+type FakeOption func(*Fake)
+
+// WithClock sets the initial time of the fake clock. Default is the current time.
+func WithClock(now time.Time) FakeOption {
+	return func(f *Fake) {
+		f.now = now
+	}
+}
+
+// WithDefaultTTL sets the TTL applied to records written with the server default expiration,
+// like default-ttl of a namespace. Default is 0, records never expire.
+func WithDefaultTTL(ttl time.Duration) FakeOption {
+	return func(f *Fake) {
+		f.defaultTTL = ttl
+	}
+}
+
+// NewFake creates an empty Fake.
+func NewFake(opts ...FakeOption) *Fake {
+	f := &Fake{
+		now:     time.Now(),
+		records: make(map[string]*record),
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+// Now returns the current time of the fake clock.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+// Advance moves the fake clock forward, expiring records whose TTL has passed.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+}
+
+// Len returns the number of live records.
+func (f *Fake) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := 0
+	for id := range f.records {
+		if f.live(id) != nil {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Get reads the bins of the record, all of them if binNames is empty.
+func (f *Fake) Get(policy *aerospike.BasePolicy, key *aerospike.Key, binNames ...string) (*aerospike.Record, aerospike.Error) {
+	if policy != nil && policy.FilterExpression != nil {
+		return nil, unsupported("filter expressions")
+	}
+
+	ops := []operation{{kind: opReadAll}}
+	if len(binNames) > 0 {
+		ops = make([]operation, len(binNames))
+		for i, name := range binNames {
+			ops[i] = operation{kind: opRead, bin: name}
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.execute(key, writeParams{}, ops)
+}
+
+// Put writes the bins into the record. Bins with nil values are removed.
+func (f *Fake) Put(policy *aerospike.WritePolicy, key *aerospike.Key, bins aerospike.BinMap) aerospike.Error {
+	params, err := writePolicyParams(policy)
+	if err != nil {
+		return err
+	}
+	if len(bins) == 0 {
+		return newError(types.PARAMETER_ERROR)
+	}
+
+	names := make([]string, 0, len(bins))
+	for name := range bins {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	ops := make([]operation, len(names))
+	for i, name := range names {
+		ops[i] = operation{kind: opWrite, bin: name, value: bins[name]}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, err = f.execute(key, params, ops)
+
+	return err
+}
+
+// Operate executes the operations on the record atomically.
+func (f *Fake) Operate(
+	policy *aerospike.WritePolicy,
+	key *aerospike.Key,
+	operations ...*aerospike.Operation,
+) (*aerospike.Record, aerospike.Error) {
+	params, err := writePolicyParams(policy)
+	if err != nil {
+		return nil, err
+	}
+	ops, err := decodeOperations(operations)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.execute(key, params, ops)
+}
+
+// Delete removes the record and reports whether it existed.
+func (f *Fake) Delete(policy *aerospike.WritePolicy, key *aerospike.Key) (bool, aerospike.Error) {
+	params, err := writePolicyParams(policy)
+	if err != nil {
+		return false, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.delete(key, params)
+}
+
+// BatchOperate executes reads, writes and deletes of the batch, setting results of every record.
+func (f *Fake) BatchOperate(policy *aerospike.BatchPolicy, records []aerospike.BatchRecordIfc) aerospike.Error {
+	if policy != nil && policy.FilterExpression != nil {
+		return unsupported("filter expressions")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, rec := range records {
+		f.batchRecord(rec)
+	}
+
+	return nil
+}
+
+func (f *Fake) batchRecord(rec aerospike.BatchRecordIfc) {
+	result := rec.BatchRec()
+	var (
+		record  *aerospike.Record
+		existed bool
+		err     aerospike.Error
+	)
+	switch r := rec.(type) {
+	case *aerospike.BatchRead:
+		record, err = f.batchRead(r)
+	case *aerospike.BatchWrite:
+		var params writeParams
+		params, err = batchWriteParams(r.Policy)
+		if err == nil {
+			var ops []operation
+			ops, err = decodeOperations(r.Ops)
+			if err == nil {
+				record, err = f.execute(r.Key, params, ops)
+			}
+		}
+	case *aerospike.BatchDelete:
+		var params writeParams
+		params, err = batchDeleteParams(r.Policy)
+		if err == nil {
+			existed, err = f.delete(r.Key, params)
+			if err == nil && !existed {
+				err = newError(types.KEY_NOT_FOUND_ERROR)
+			}
+		}
+	default:
+		err = unsupported("batch command")
+	}
+
+	if err != nil {
+		result.Record = nil
+		result.ResultCode = resultCode(err)
+		result.Err = err
+		return
+	}
+	result.Record = record
+	result.ResultCode = types.OK
+	result.Err = nil
+}
+
+func (f *Fake) batchRead(r *aerospike.BatchRead) (*aerospike.Record, aerospike.Error) {
+	if r.Policy != nil && r.Policy.FilterExpression != nil {
+		return nil, unsupported("filter expressions")
+	}
+
+	var ops []operation
+	switch {
+	case len(r.Ops) > 0:
+		var err aerospike.Error
+		ops, err = decodeOperations(r.Ops)
+		if err != nil {
+			return nil, err
+		}
+	case r.ReadAllBins:
+		ops = []operation{{kind: opReadAll}}
+	case len(r.BinNames) > 0:
+		for _, name := range r.BinNames {
+			ops = append(ops, operation{kind: opRead, bin: name})
+		}
+	default:
+		ops = []operation{{kind: opReadHeader}}
+	}
+
+	return f.execute(r.Key, writeParams{}, ops)
+}
+
+// writeParams are the write policy settings shared by single record and batch commands.
+type writeParams struct {
+	action           aerospike.RecordExistsAction
+	generationPolicy aerospike.GenerationPolicy
+	generation       uint32
+	expiration       uint32
+	sendKey          bool
+}
+
+func writePolicyParams(policy *aerospike.WritePolicy) (writeParams, aerospike.Error) {
+	if policy == nil {
+		policy = aerospike.NewWritePolicy(0, 0)
+	}
+	if policy.FilterExpression != nil {
+		return writeParams{}, unsupported("filter expressions")
+	}
+
+	return writeParams{
+		action:           policy.RecordExistsAction,
+		generationPolicy: policy.GenerationPolicy,
+		generation:       policy.Generation,
+		expiration:       policy.Expiration,
+		sendKey:          policy.SendKey,
+	}, nil
+}
+
+func batchWriteParams(policy *aerospike.BatchWritePolicy) (writeParams, aerospike.Error) {
+	if policy == nil {
+		policy = aerospike.NewBatchWritePolicy()
+	}
+	if policy.FilterExpression != nil {
+		return writeParams{}, unsupported("filter expressions")
+	}
+
+	return writeParams{
+		action:           policy.RecordExistsAction,
+		generationPolicy: policy.GenerationPolicy,
+		generation:       policy.Generation,
+		expiration:       policy.Expiration,
+		sendKey:          policy.SendKey,
+	}, nil
+}
+
+func batchDeleteParams(policy *aerospike.BatchDeletePolicy) (writeParams, aerospike.Error) {
+	if policy == nil {
+		policy = aerospike.NewBatchDeletePolicy()
+	}
+	if policy.FilterExpression != nil {
+		return writeParams{}, unsupported("filter expressions")
+	}
+
+	return writeParams{generationPolicy: policy.GenerationPolicy, generation: policy.Generation}, nil
+}
+
+// execute applies the operations to the record and commits the result if any of them writes.
+// The caller must hold the lock.
+func (f *Fake) execute(key *aerospike.Key, params writeParams, ops []operation) (*aerospike.Record, aerospike.Error) {
+	id := recordID(key)
+	rec := f.live(id)
+	write := slices.ContainsFunc(ops, operation.writes)
+	if !write {
+		if rec == nil {
+			return nil, newError(types.KEY_NOT_FOUND_ERROR)
+		}
+		results, err := applyOperations(rec.bins, ops)
+		if err != nil {
+			return nil, err
+		}
+		return f.result(key, rec, results), nil
+	}
+
+	if err := checkWrite(rec, params, ops); err != nil {
+		return nil, err
+	}
+
+	bins := make(map[string]any)
+	if rec != nil && params.action != aerospike.REPLACE && params.action != aerospike.REPLACE_ONLY {
+		bins = maps.Clone(rec.bins)
+	}
+	results, err := applyOperations(bins, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bins) == 0 {
+		delete(f.records, id)
+		return &aerospike.Record{Key: key, Bins: results}, nil
+	}
+
+	updated := &record{
+		namespace:  key.Namespace(),
+		set:        key.SetName(),
+		digest:     key.Digest(),
+		bins:       bins,
+		generation: 1,
+		expiresAt:  f.expiresAt(params.expiration, rec),
+		lastUpdate: f.now,
+	}
+	if rec != nil {
+		updated.userKey = rec.userKey
+		updated.generation = nextGeneration(rec.generation)
+	}
+	if params.sendKey {
+		updated.userKey = key.Value()
+	}
+	f.records[id] = updated
+
+	return f.result(key, updated, results), nil
+}
+
+// delete removes the record if the generation policy allows it. The caller must hold the lock.
+func (f *Fake) delete(key *aerospike.Key, params writeParams) (bool, aerospike.Error) {
+	id := recordID(key)
+	rec := f.live(id)
+	if rec == nil {
+		return false, nil
+	}
+	if err := checkGeneration(rec, params); err != nil {
+		return false, err
+	}
+	delete(f.records, id)
+
+	return true, nil
+}
+
+func checkWrite(rec *record, params writeParams, ops []operation) aerospike.Error {
+	switch {
+	case rec == nil && slices.ContainsFunc(ops, func(op operation) bool { return op.kind == opTouch }):
+		return newError(types.KEY_NOT_FOUND_ERROR)
+	case rec != nil && params.action == aerospike.CREATE_ONLY:
+		return newError(types.KEY_EXISTS_ERROR)
+	case rec == nil && (params.action == aerospike.UPDATE_ONLY || params.action == aerospike.REPLACE_ONLY):
+		return newError(types.KEY_NOT_FOUND_ERROR)
+	case rec == nil:
+		return nil
+	default:
+		return checkGeneration(rec, params)
+	}
+}
+
+func checkGeneration(rec *record, params writeParams) aerospike.Error {
+	switch {
+	case params.generationPolicy == aerospike.EXPECT_GEN_EQUAL && rec.generation != params.generation,
+		params.generationPolicy == aerospike.EXPECT_GEN_GT && params.generation <= rec.generation:
+		return newError(types.GENERATION_ERROR)
+	default:
+		return nil
+	}
+}
+
+func nextGeneration(generation uint32) uint32 {
+	if generation >= maxGeneration {
+		return 1
+	}
+
+	return generation + 1
+}
+
+// expiresAt returns the expiration time for the write policy expiration, zero if the record never expires.
+func (f *Fake) expiresAt(expiration uint32, rec *record) time.Time {
+	switch expiration {
+	case aerospike.TTLServerDefault:
+		if f.defaultTTL == 0 {
+			return time.Time{}
+		}
+		return f.now.Add(f.defaultTTL)
+	case aerospike.TTLDontExpire:
+		return time.Time{}
+	case aerospike.TTLDontUpdate:
+		if rec != nil {
+			return rec.expiresAt
+		}
+		return f.expiresAt(aerospike.TTLServerDefault, nil)
+	default:
+		return f.now.Add(time.Duration(expiration) * time.Second)
+	}
+}
+
+// live returns the record if it exists and has not expired, removing expired records.
+// The caller must hold the lock.
+func (f *Fake) live(id string) *record {
+	rec, ok := f.records[id]
+	if !ok {
+		return nil
+	}
+	if !rec.expiresAt.IsZero() && !f.now.Before(rec.expiresAt) {
+		delete(f.records, id)
+		return nil
+	}
+
+	return rec
+}
+
+func (f *Fake) result(key *aerospike.Key, rec *record, bins aerospike.BinMap) *aerospike.Record {
+	return &aerospike.Record{
+		Key:        key,
+		Bins:       bins,
+		Generation: rec.generation,
+		Expiration: f.ttl(rec),
+	}
+}
+
+// ttl returns the remaining TTL in seconds the way the client reports it.
+func (f *Fake) ttl(rec *record) uint32 {
+	if rec.expiresAt.IsZero() {
+		return math.MaxUint32
+	}
+
+	seconds := int64(rec.expiresAt.Sub(f.now) / time.Second)
+
+	return uint32(max(seconds, 1)) //nolint:gosec
+}
+
+func recordID(key *aerospike.Key) string {
+	return key.Namespace() + "\x00" + string(key.Digest())
+}
+
+func newError(code types.ResultCode) aerospike.Error {
+	return &aerospike.AerospikeError{ResultCode: code}
+}
+
+// unsupportedError is the UNSUPPORTED_FEATURE error naming the feature the Fake does not implement.
+// It wraps *aerospike.AerospikeError, whose message cannot be set outside the client.
+type unsupportedError struct {
+	*aerospike.AerospikeError
+	feature string
+}
+
+func (e *unsupportedError) Error() string {
+	return e.AerospikeError.Error() + "unsupported " + e.feature
+}
+
+func (e *unsupportedError) Unwrap() error {
+	return e.AerospikeError
+}
+
+func unsupported(feature string) aerospike.Error {
+	return &unsupportedError{AerospikeError: &aerospike.AerospikeError{ResultCode: types.UNSUPPORTED_FEATURE}, feature: feature}
+}
@@ -0,0 +1,350 @@
+package aerospiketest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/aerospike/aerospike-client-go/v8/types"
+	"github.com/stretchr/testify/require"
+
+	goaerospike "github.com/viru-tech/go.aerospike"
+)
+
+type user struct {
+	ID   string `as:",key"`
+	Gen  uint32 `as:",generation"`
+	Name string `as:"name"`
+	Age  int    `as:"age,omitempty"`
+}
+
+type session struct {
+	ID   string        `as:",key"`
+	TTL  time.Duration `as:",ttl"`
+	Name string        `as:"name"`
+}
+
+type item struct {
+	ID   int    `as:",key"`
+	Name string `as:"name"`
+	Tags []int  `as:"tags"`
+}
+
+func TestFakeStore(t *testing.T) {
+	t.Parallel()
+
+	store, err := goaerospike.NewStore[user](NewFake(), "test", "users")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	v := user{ID: "1", Name: "John", Age: 30}
+	require.ErrorIs(t, store.Update(ctx, &v), goaerospike.ErrNotFound)
+	require.NoError(t, store.Create(ctx, &v))
+	require.ErrorIs(t, store.Create(ctx, &v), goaerospike.ErrAlreadyExists)
+
+	got, err := store.Get(ctx, "1")
+	require.NoError(t, err)
+	require.Equal(t, user{ID: "1", Gen: 1, Name: "John", Age: 30}, got)
+
+	got.Name = "Jane"
+	require.NoError(t, store.Update(ctx, &got))
+	got.Name = "stale"
+	require.ErrorIs(t, store.Update(ctx, &got), goaerospike.ErrConflict)
+
+	exists, err := store.Exists(ctx, "1")
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.NoError(t, store.Touch(ctx, "1"))
+	require.ErrorIs(t, store.Touch(ctx, "2"), goaerospike.ErrNotFound)
+
+	many, err := store.GetMany(ctx, []any{"1", "2"})
+	require.NoError(t, err)
+	require.Len(t, many, 2)
+	require.Equal(t, "Jane", many[0].Name)
+	require.Equal(t, uint32(3), many[0].Gen)
+	require.Nil(t, many[1])
+
+	existed, err := store.Delete(ctx, "1")
+	require.NoError(t, err)
+	require.True(t, existed)
+	existed, err = store.Delete(ctx, "1")
+	require.NoError(t, err)
+	require.False(t, existed)
+}
+
+func TestFakeExpiration(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := NewFake(WithClock(start), WithDefaultTTL(time.Hour))
+	store, err := goaerospike.NewStore[session](fake, "test", "sessions")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, store.Put(ctx, &session{ID: "default", Name: "a"}))
+	require.NoError(t, store.Put(ctx, &session{ID: "short", Name: "b", TTL: time.Minute}))
+	require.NoError(t, store.Put(ctx, &session{ID: "never", Name: "c", TTL: -time.Second}))
+
+	fake.Advance(30 * time.Second)
+	require.Equal(t, start.Add(30*time.Second), fake.Now())
+	got, err := store.Get(ctx, "short")
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Second, got.TTL)
+
+	fake.Advance(time.Minute)
+	_, err = store.Get(ctx, "short")
+	require.ErrorIs(t, err, goaerospike.ErrNotFound)
+	require.Equal(t, 2, fake.Len())
+
+	fake.Advance(time.Hour)
+	exists, err := store.Exists(ctx, "never")
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.Equal(t, 1, fake.Len())
+}
+
+func TestFakeOperate(t *testing.T) {
+	t.Parallel()
+
+	key, err := aerospike.NewKey("test", "ops", "key")
+	require.NoError(t, err)
+	initial := aerospike.BinMap{
+		"int":  1,
+		"str":  "b",
+		"list": []any{1, []any{2, 3}},
+		"map":  map[any]any{"a": map[any]any{"b": "c"}},
+	}
+
+	tests := []struct {
+		name     string
+		ops      []*aerospike.Operation
+		wantBins aerospike.BinMap
+		wantCode types.ResultCode
+	}{
+		{
+			name: "add append prepend",
+			ops: []*aerospike.Operation{
+				aerospike.AddOp(aerospike.NewBin("int", 2)),
+				aerospike.AppendOp(aerospike.NewBin("str", "c")),
+				aerospike.PrependOp(aerospike.NewBin("str", "a")),
+				aerospike.GetBinOp("int"),
+				aerospike.GetBinOp("str"),
+			},
+			wantBins: aerospike.BinMap{"int": 3, "str": "abc"},
+		},
+		{
+			name: "repeated reads",
+			ops: []*aerospike.Operation{
+				aerospike.GetBinOp("int"),
+				aerospike.PutOp(aerospike.NewBin("int", 5)),
+				aerospike.GetBinOp("int"),
+			},
+			wantBins: aerospike.BinMap{"int": aerospike.OpResults{1, 5}},
+		},
+		{
+			name: "cdt reads",
+			ops: []*aerospike.Operation{
+				aerospike.ListGetByIndexOp("list", -1, aerospike.ListReturnTypeValue, aerospike.CtxListIndex(1)),
+				aerospike.MapGetByKeyOp("map", "b", aerospike.MapReturnType.VALUE, aerospike.CtxMapKey(aerospike.NewValue("a"))),
+			},
+			wantBins: aerospike.BinMap{"list": 3, "map": "c"},
+		},
+		{
+			name:     "add to string",
+			ops:      []*aerospike.Operation{aerospike.AddOp(aerospike.NewBin("str", 1))},
+			wantCode: types.BIN_TYPE_ERROR,
+		},
+		{
+			name:     "index out of range",
+			ops:      []*aerospike.Operation{aerospike.ListGetByIndexOp("list", 5, aerospike.ListReturnTypeValue)},
+			wantCode: types.OP_NOT_APPLICABLE,
+		},
+		{
+			name:     "bin name too long",
+			ops:      []*aerospike.Operation{aerospike.PutOp(aerospike.NewBin("sixteen_bytes_xx", 1))},
+			wantCode: types.BIN_NAME_TOO_LONG,
+		},
+		{
+			name:     "unsupported",
+			ops:      []*aerospike.Operation{aerospike.ListAppendOp("list", 4)},
+			wantCode: types.UNSUPPORTED_FEATURE,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake := NewFake()
+			require.NoError(t, fake.Put(nil, key, initial))
+
+			record, err := fake.Operate(nil, key, tt.ops...)
+			if tt.wantCode != types.OK {
+				requireResultCode(t, tt.wantCode, err)
+				got, err := fake.Get(nil, key)
+				require.NoError(t, err)
+				require.Equal(t, initial, got.Bins)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantBins, record.Bins)
+		})
+	}
+}
+
+func TestFakeWritePolicy(t *testing.T) {
+	t.Parallel()
+
+	key, err := aerospike.NewKey("test", "policy", "key")
+	require.NoError(t, err)
+	withPolicy := func(modify func(*aerospike.WritePolicy)) *aerospike.WritePolicy {
+		policy := aerospike.NewWritePolicy(0, 0)
+		modify(policy)
+		return policy
+	}
+
+	tests := []struct {
+		name     string
+		policy   *aerospike.WritePolicy
+		wantBins aerospike.BinMap
+		wantCode types.ResultCode
+	}{
+		{
+			name:     "update",
+			policy:   withPolicy(func(p *aerospike.WritePolicy) {}),
+			wantBins: aerospike.BinMap{"a": 1, "b": 2},
+		},
+		{
+			name:     "replace",
+			policy:   withPolicy(func(p *aerospike.WritePolicy) { p.RecordExistsAction = aerospike.REPLACE }),
+			wantBins: aerospike.BinMap{"b": 2},
+		},
+		{
+			name:     "create only",
+			policy:   withPolicy(func(p *aerospike.WritePolicy) { p.RecordExistsAction = aerospike.CREATE_ONLY }),
+			wantCode: types.KEY_EXISTS_ERROR,
+		},
+		{
+			name: "generation equal",
+			policy: withPolicy(func(p *aerospike.WritePolicy) {
+				p.GenerationPolicy = aerospike.EXPECT_GEN_EQUAL
+				p.Generation = 1
+			}),
+			wantBins: aerospike.BinMap{"a": 1, "b": 2},
+		},
+		{
+			name: "generation mismatch",
+			policy: withPolicy(func(p *aerospike.WritePolicy) {
+				p.GenerationPolicy = aerospike.EXPECT_GEN_EQUAL
+				p.Generation = 2
+			}),
+			wantCode: types.GENERATION_ERROR,
+		},
+		{
+			name:     "filter expression",
+			policy:   withPolicy(func(p *aerospike.WritePolicy) { p.FilterExpression = aerospike.ExpBoolVal(true) }),
+			wantCode: types.UNSUPPORTED_FEATURE,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake := NewFake()
+			require.NoError(t, fake.Put(nil, key, aerospike.BinMap{"a": 1}))
+
+			err := fake.Put(tt.policy, key, aerospike.BinMap{"b": 2})
+			if tt.wantCode != types.OK {
+				requireResultCode(t, tt.wantCode, err)
+				return
+			}
+			require.NoError(t, err)
+			got, err := fake.Get(nil, key)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantBins, got.Bins)
+			require.Equal(t, uint32(2), got.Generation)
+		})
+	}
+}
+
+func TestFakeBatchQuery(t *testing.T) {
+	t.Parallel()
+
+	fake := NewFake()
+	ctx := context.Background()
+	items := make([]item, 10)
+	for i := range items {
+		items[i] = item{ID: i, Name: fmt.Sprint(i), Tags: []int{i % 2}}
+	}
+	report, err := goaerospike.BatchPut(ctx, fake, "test", "items", items, goaerospike.BatchPutChunkSize(3))
+	require.NoError(t, err)
+	require.Empty(t, report.Failed())
+
+	var scanned int
+	for _, err := range goaerospike.Scan[item](ctx, fake, nil, "test", "items") {
+		require.NoError(t, err)
+		scanned++
+	}
+	require.Equal(t, len(items), scanned)
+
+	stmt := aerospike.NewStatement("test", "items")
+	require.NoError(t, stmt.SetFilter(aerospike.NewContainsFilter("tags", aerospike.ICT_LIST, 1)))
+	var odd []string
+	for v, err := range goaerospike.Query[item](ctx, fake, nil, stmt) {
+		require.NoError(t, err)
+		odd = append(odd, v.Name)
+	}
+	require.ElementsMatch(t, []string{"1", "3", "5", "7", "9"}, odd)
+
+	seen := make(map[string]bool)
+	cursor := ""
+	for {
+		page, err := goaerospike.QueryPage[item](ctx, fake, nil, aerospike.NewStatement("test", "items"), 3, cursor)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page.Items), 3)
+		for _, v := range page.Items {
+			require.False(t, seen[v.Name])
+			seen[v.Name] = true
+		}
+		if page.Cursor == "" {
+			break
+		}
+		cursor = page.Cursor
+	}
+	require.Len(t, seen, len(items))
+}
+
+func TestFakeMutate(t *testing.T) {
+	t.Parallel()
+
+	type counter struct {
+		Value int `as:"value"`
+	}
+	fake := NewFake()
+	key, err := aerospike.NewKey("test", "mutate", "counter")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for range 3 {
+		_, err := goaerospike.Mutate(ctx, fake, key, func(c *counter) error {
+			c.Value++
+			return nil
+		}, goaerospike.MutateCreateIfMissing())
+		require.NoError(t, err)
+	}
+
+	record, err := fake.Get(nil, key)
+	require.NoError(t, err)
+	require.Equal(t, aerospike.BinMap{"value": 3}, record.Bins)
+	require.Equal(t, uint32(3), record.Generation)
+}
+
+func requireResultCode(t *testing.T, want types.ResultCode, err error) {
+	t.Helper()
+
+	var aerr *aerospike.AerospikeError
+	require.True(t, errors.As(err, &aerr), "unexpected error %v", err)
+	require.Equal(t, want, aerr.ResultCode)
+}
@@ -0,0 +1,427 @@
+package aerospiketest
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/aerospike/aerospike-client-go/v8/types"
+
+	goaerospike "github.com/viru-tech/go.aerospike"
+)
+
+var _ goaerospike.Client = (*Faulty)(nil)
+
+// Method is a method of goaerospike.Client.
+type Method string
+
+// Methods of goaerospike.Client.
+const (
+	MethodGet          Method = "Get"
+	MethodPut          Method = "Put"
+	MethodOperate      Method = "Operate"
+	MethodDelete       Method = "Delete"
+	MethodBatchOperate Method = "BatchOperate"
+	MethodQuery        Method = "Query"
+	MethodScan         Method = "Scan"
+)
+
+// Call is a call recorded by Faulty.
+type Call struct {
+	Method Method
+	// Keys are the key of a single record command or the keys of batch records.
+	Keys []*aerospike.Key
+	// Namespace and Set are set for queries and scans.
+	Namespace string
+	Set       string
+	// Delay is the injected latency.
+	Delay time.Duration
+	// Injected reports whether the call failed, or records of the batch failed, because of a fault.
+	Injected bool
+	// Err is the error returned by the call.
+	Err aerospike.Error
+}
+
+// Faulty is a goaerospike.Client decorator injecting errors and latencies into calls of
+// the wrapped client, e.g. *aerospike.Client adapted with goaerospike.WrapClient or a Fake.
+// It records every call for assertions.
+type Faulty struct {
+	client goaerospike.Client
+
+	mu     sync.Mutex
+	rand   *rand.Rand
+	faults []*Fault
+	calls  []Call
+}
+
+// FaultyOption configures a Faulty.
+type FaultyOption func(*Faulty)
+
+// WithSeed seeds the random source deciding on faults with a probability,
+// making them reproducible. By default the source is seeded randomly.
+func WithSeed(seed uint64) FaultyOption {
+	return func(f *Faulty) {
+		f.rand = rand.New(rand.NewPCG(seed, seed)) //nolint:gosec
+	}
+}
+
+// NewFaulty wraps the client.
+func NewFaulty(client goaerospike.Client, opts ...FaultyOption) *Faulty {
+	f := &Faulty{
+		client: client,
+		rand:   rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())), //nolint:gosec
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+// Inject adds the faults. All matching faults delay a call, the first matching one failing it
+// decides the error.
+func (f *Faulty) Inject(faults ...*Fault) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.faults = append(f.faults, faults...)
+}
+
+// Reset removes all faults and recorded calls.
+func (f *Faulty) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.faults = nil
+	f.calls = nil
+}
+
+// Calls returns the recorded calls in order.
+func (f *Faulty) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Clone(f.calls)
+}
+
+// Get reads the record unless a fault fails the call.
+func (f *Faulty) Get(policy *aerospike.BasePolicy, key *aerospike.Key, binNames ...string) (*aerospike.Record, aerospike.Error) {
+	call := Call{Method: MethodGet, Keys: []*aerospike.Key{key}}
+	if err := f.before(&call); err != nil {
+		return nil, err
+	}
+
+	record, err := f.client.Get(policy, key, binNames...)
+	f.record(call, err)
+
+	return record, err
+}
+
+// Put writes the record unless a fault fails the call.
+func (f *Faulty) Put(policy *aerospike.WritePolicy, key *aerospike.Key, bins aerospike.BinMap) aerospike.Error {
+	call := Call{Method: MethodPut, Keys: []*aerospike.Key{key}}
+	if err := f.before(&call); err != nil {
+		return err
+	}
+
+	err := f.client.Put(policy, key, bins)
+	f.record(call, err)
+
+	return err
+}
+
+// Operate executes the operations unless a fault fails the call.
+func (f *Faulty) Operate(
+	policy *aerospike.WritePolicy,
+	key *aerospike.Key,
+	operations ...*aerospike.Operation,
+) (*aerospike.Record, aerospike.Error) {
+	call := Call{Method: MethodOperate, Keys: []*aerospike.Key{key}}
+	if err := f.before(&call); err != nil {
+		return nil, err
+	}
+
+	record, err := f.client.Operate(policy, key, operations...)
+	f.record(call, err)
+
+	return record, err
+}
+
+// Delete removes the record unless a fault fails the call.
+func (f *Faulty) Delete(policy *aerospike.WritePolicy, key *aerospike.Key) (bool, aerospike.Error) {
+	call := Call{Method: MethodDelete, Keys: []*aerospike.Key{key}}
+	if err := f.before(&call); err != nil {
+		return false, err
+	}
+
+	existed, err := f.client.Delete(policy, key)
+	f.record(call, err)
+
+	return existed, err
+}
+
+// BatchOperate executes the batch unless a fault fails the call. Records failed by faults created
+// with FailRecords get the error, other records are passed to the wrapped client.
+func (f *Faulty) BatchOperate(policy *aerospike.BatchPolicy, records []aerospike.BatchRecordIfc) aerospike.Error {
+	call := Call{Method: MethodBatchOperate, Keys: make([]*aerospike.Key, len(records))}
+	for i, rec := range records {
+		call.Keys[i] = rec.BatchRec().Key
+	}
+	if err := f.before(&call); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	failed := make([]types.ResultCode, len(records))
+	for _, fault := range f.faults {
+		if fault.records {
+			fault.failRecords(f.rand, call, failed)
+		}
+	}
+	f.mu.Unlock()
+
+	pass := make([]aerospike.BatchRecordIfc, 0, len(records))
+	for i, rec := range records {
+		if failed[i] == types.OK {
+			pass = append(pass, rec)
+			continue
+		}
+		call.Injected = true
+		result := rec.BatchRec()
+		result.Record = nil
+		result.ResultCode = failed[i]
+		result.Err = newError(failed[i])
+	}
+
+	var err aerospike.Error
+	if len(pass) > 0 {
+		err = f.client.BatchOperate(policy, pass)
+	}
+	f.record(call, err)
+
+	return err
+}
+
+// Query executes the statement unless a fault fails the call.
+func (f *Faulty) Query(
+	policy *aerospike.QueryPolicy,
+	stmt *aerospike.Statement,
+	filter *aerospike.PartitionFilter,
+) (goaerospike.Recordset, aerospike.Error) {
+	call := Call{Method: MethodQuery, Namespace: stmt.Namespace, Set: stmt.SetName}
+	if err := f.before(&call); err != nil {
+		return nil, err
+	}
+
+	rs, err := f.client.Query(policy, stmt, filter)
+	f.record(call, err)
+
+	return rs, err
+}
+
+// Scan reads records of the set unless a fault fails the call.
+func (f *Faulty) Scan(
+	policy *aerospike.ScanPolicy,
+	filter *aerospike.PartitionFilter,
+	namespace, set string,
+	binNames ...string,
+) (goaerospike.Recordset, aerospike.Error) {
+	call := Call{Method: MethodScan, Namespace: namespace, Set: set}
+	if err := f.before(&call); err != nil {
+		return nil, err
+	}
+
+	rs, err := f.client.Scan(policy, filter, namespace, set, binNames...)
+	f.record(call, err)
+
+	return rs, err
+}
+
+// before applies faults to the call, sleeping for injected latencies. It records the call
+// and returns the error if a fault fails it.
+func (f *Faulty) before(call *Call) aerospike.Error {
+	f.mu.Lock()
+	var err aerospike.Error
+	for _, fault := range f.faults {
+		if fault.records || !fault.apply(f.rand, call) {
+			continue
+		}
+		call.Delay += fault.latency
+		if fault.fail && err == nil {
+			err = newError(fault.code)
+		}
+	}
+	f.mu.Unlock()
+
+	time.Sleep(call.Delay)
+	if err != nil {
+		call.Injected = true
+		f.record(*call, err)
+	}
+
+	return err
+}
+
+func (f *Faulty) record(call Call, err aerospike.Error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	call.Err = err
+	f.calls = append(f.calls, call)
+}
+
+// Fault injects an error or a latency into calls matching all of its conditions.
+type Fault struct {
+	code    types.ResultCode
+	fail    bool
+	latency time.Duration
+	// records is set for faults failing records of batches.
+	records bool
+
+	methods     []Method
+	keys        []*aerospike.Key
+	namespace   string
+	set         *string
+	probability float64
+	calls       []int
+	times       int
+
+	// matched counts calls matching the methods, keys and the set.
+	matched  int
+	injected int
+}
+
+// FaultOption narrows the calls a fault applies to.
+type FaultOption func(*Fault)
+
+// FailWith returns the fault failing calls with *aerospike.AerospikeError holding the code,
+// e.g. types.TIMEOUT or types.GENERATION_ERROR. The wrapped client is not called.
+func FailWith(code types.ResultCode, opts ...FaultOption) *Fault {
+	return newFault(&Fault{code: code, fail: true}, opts)
+}
+
+// FailRecords returns the fault failing records of batches with the code, while other records
+// are executed. Conditions on keys and sets, and the probability, apply to every record,
+// call numbers and limits apply to batch calls. It panics if OnMethods is passed.
+func FailRecords(code types.ResultCode, opts ...FaultOption) *Fault {
+	return newFault(&Fault{code: code, fail: true, records: true, methods: []Method{MethodBatchOperate}}, opts)
+}
+
+// Delay returns the fault delaying calls by the latency before they are executed.
+func Delay(latency time.Duration, opts ...FaultOption) *Fault {
+	return newFault(&Fault{latency: latency}, opts)
+}
+
+func newFault(fault *Fault, opts []FaultOption) *Fault {
+	fault.probability = 1
+	for _, opt := range opts {
+		opt(fault)
+	}
+
+	return fault
+}
+
+// OnMethods applies the fault to calls of the methods only.
+// It panics when used with FailRecords, whose faults apply to BatchOperate calls only.
+func OnMethods(methods ...Method) FaultOption {
+	return func(f *Fault) {
+		if f.records {
+			panic("aerospiketest: OnMethods cannot be used with FailRecords")
+		}
+		f.methods = methods
+	}
+}
+
+// OnKeys applies the fault to calls with any of the keys, compared by namespace and digest.
+func OnKeys(keys ...*aerospike.Key) FaultOption {
+	return func(f *Fault) {
+		f.keys = keys
+	}
+}
+
+// OnSet applies the fault to calls with keys in the set, queries and scans of the set.
+func OnSet(namespace, set string) FaultOption {
+	return func(f *Fault) {
+		f.namespace = namespace
+		f.set = &set
+	}
+}
+
+// WithProbability applies the fault to matching calls with the probability between 0 and 1.
+func WithProbability(p float64) FaultOption {
+	return func(f *Fault) {
+		f.probability = p
+	}
+}
+
+// OnCalls applies the fault to the matching calls with the numbers only, starting from 1.
+// Calls are numbered by the other conditions, without the probability.
+func OnCalls(numbers ...int) FaultOption {
+	return func(f *Fault) {
+		f.calls = numbers
+	}
+}
+
+// Times limits the number of times the fault is injected.
+func Times(n int) FaultOption {
+	return func(f *Fault) {
+		f.times = n
+	}
+}
+
+// apply reports whether the fault is injected into the call. The caller must hold the lock of Faulty.
+func (f *Fault) apply(r *rand.Rand, call *Call) bool {
+	if len(f.methods) > 0 && !slices.Contains(f.methods, call.Method) {
+		return false
+	}
+	if len(call.Keys) > 0 {
+		if !slices.ContainsFunc(call.Keys, f.matchKey) {
+			return false
+		}
+	} else if len(f.keys) > 0 || !f.matchSet(call.Namespace, call.Set) {
+		return false
+	}
+
+	f.matched++
+	if len(f.calls) > 0 && !slices.Contains(f.calls, f.matched) {
+		return false
+	}
+	if f.times > 0 && f.injected >= f.times {
+		return false
+	}
+	if !f.records && r.Float64() >= f.probability {
+		return false
+	}
+	f.injected++
+
+	return true
+}
+
+// failRecords sets the code of batch records the fault fails unless they already failed.
+func (f *Fault) failRecords(r *rand.Rand, call Call, failed []types.ResultCode) {
+	if !f.apply(r, &call) {
+		return
+	}
+	for i, key := range call.Keys {
+		if failed[i] == types.OK && f.matchKey(key) && r.Float64() < f.probability {
+			failed[i] = f.code
+		}
+	}
+}
+
+func (f *Fault) matchKey(key *aerospike.Key) bool {
+	if key == nil || !f.matchSet(key.Namespace(), key.SetName()) {
+		return false
+	}
+
+	return len(f.keys) == 0 || slices.ContainsFunc(f.keys, func(k *aerospike.Key) bool {
+		return k.Namespace() == key.Namespace() && bytes.Equal(k.Digest(), key.Digest())
+	})
+}
+
+func (f *Fault) matchSet(namespace, set string) bool {
+	return f.set == nil || (f.namespace == namespace && *f.set == set)
+}
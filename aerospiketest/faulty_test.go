@@ -0,0 +1,222 @@
+package aerospiketest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/aerospike/aerospike-client-go/v8/types"
+	"github.com/stretchr/testify/require"
+
+	goaerospike "github.com/viru-tech/go.aerospike"
+)
+
+func TestFaulty(t *testing.T) {
+	t.Parallel()
+
+	key := must(aerospike.NewKey("test", "users", "1"))
+	other := must(aerospike.NewKey("test", "users", "2"))
+
+	tests := []struct {
+		name  string
+		fault *Fault
+		// calls are made in order, one Get of the key per element, true for an injected error.
+		keys []*aerospike.Key
+		want []bool
+	}{
+		{
+			name:  "always",
+			fault: FailWith(types.TIMEOUT),
+			keys:  []*aerospike.Key{key, other},
+			want:  []bool{true, true},
+		},
+		{
+			name:  "call numbers",
+			fault: FailWith(types.TIMEOUT, OnCalls(2, 3)),
+			keys:  []*aerospike.Key{key, key, key, key},
+			want:  []bool{false, true, true, false},
+		},
+		{
+			name:  "times",
+			fault: FailWith(types.TIMEOUT, Times(1)),
+			keys:  []*aerospike.Key{key, key},
+			want:  []bool{true, false},
+		},
+		{
+			name:  "keys",
+			fault: FailWith(types.TIMEOUT, OnKeys(other), OnCalls(2)),
+			keys:  []*aerospike.Key{other, key, other},
+			want:  []bool{false, false, true},
+		},
+		{
+			name:  "set",
+			fault: FailWith(types.TIMEOUT, OnSet("test", "sessions")),
+			keys:  []*aerospike.Key{key},
+			want:  []bool{false},
+		},
+		{
+			name:  "other method",
+			fault: FailWith(types.TIMEOUT, OnMethods(MethodPut)),
+			keys:  []*aerospike.Key{key},
+			want:  []bool{false},
+		},
+		{
+			name:  "zero probability",
+			fault: FailWith(types.TIMEOUT, WithProbability(0)),
+			keys:  []*aerospike.Key{key, key},
+			want:  []bool{false, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake := NewFake()
+			require.NoError(t, fake.Put(nil, key, aerospike.BinMap{"name": "John"}))
+			require.NoError(t, fake.Put(nil, other, aerospike.BinMap{"name": "Jane"}))
+			faulty := NewFaulty(fake)
+			faulty.Inject(tt.fault)
+
+			for i, k := range tt.keys {
+				_, err := faulty.Get(nil, k)
+				if tt.want[i] {
+					requireResultCode(t, types.TIMEOUT, err)
+				} else {
+					require.NoError(t, err)
+				}
+			}
+
+			calls := faulty.Calls()
+			require.Len(t, calls, len(tt.keys))
+			for i, call := range calls {
+				require.Equal(t, MethodGet, call.Method)
+				require.Equal(t, []*aerospike.Key{tt.keys[i]}, call.Keys)
+				require.Equal(t, tt.want[i], call.Injected)
+				require.Equal(t, tt.want[i], call.Err != nil)
+			}
+		})
+	}
+}
+
+func TestFaultyStore(t *testing.T) {
+	t.Parallel()
+
+	faulty := NewFaulty(NewFake(), WithSeed(1))
+	store, err := goaerospike.NewStore[user](faulty, "test", "users")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	v := user{ID: "1", Name: "John"}
+	require.NoError(t, store.Create(ctx, &v))
+
+	faulty.Inject(
+		FailWith(types.GENERATION_ERROR, OnMethods(MethodPut), Times(1)),
+		Delay(10*time.Millisecond, OnMethods(MethodGet)),
+	)
+	v.Name = "Jane"
+	require.ErrorIs(t, store.Update(ctx, &v), goaerospike.ErrConflict)
+	require.NoError(t, store.Update(ctx, &v))
+
+	start := time.Now()
+	got, err := store.Get(ctx, "1")
+	require.NoError(t, err)
+	require.Equal(t, "Jane", got.Name)
+	require.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+
+	calls := faulty.Calls()
+	require.Len(t, calls, 4)
+	require.True(t, calls[1].Injected)
+	require.False(t, calls[2].Injected)
+	require.Equal(t, 10*time.Millisecond, calls[3].Delay)
+
+	faulty.Reset()
+	require.Empty(t, faulty.Calls())
+	_, err = store.Get(ctx, "1")
+	require.NoError(t, err)
+}
+
+func TestFaultyBatch(t *testing.T) {
+	t.Parallel()
+
+	fake := NewFake()
+	keys := make([]*aerospike.Key, 3)
+	for i := range keys {
+		keys[i] = must(aerospike.NewKey("test", "items", i))
+		require.NoError(t, fake.Put(nil, keys[i], aerospike.BinMap{"name": "item"}))
+	}
+	faulty := NewFaulty(fake)
+	faulty.Inject(FailRecords(types.DEVICE_OVERLOAD, OnKeys(keys[1]), Times(1)))
+
+	records := make([]aerospike.BatchRecordIfc, len(keys))
+	for i, key := range keys {
+		records[i] = aerospike.NewBatchRead(nil, key, nil)
+	}
+	require.NoError(t, faulty.BatchOperate(nil, records))
+	require.Equal(t, types.OK, records[0].BatchRec().ResultCode)
+	require.Equal(t, types.DEVICE_OVERLOAD, records[1].BatchRec().ResultCode)
+	requireResultCode(t, types.DEVICE_OVERLOAD, records[1].BatchRec().Err)
+	require.Nil(t, records[1].BatchRec().Record)
+	require.Equal(t, aerospike.BinMap{"name": "item"}, records[2].BatchRec().Record.Bins)
+
+	records[1] = aerospike.NewBatchRead(nil, keys[1], nil)
+	require.NoError(t, faulty.BatchOperate(nil, records))
+	require.Equal(t, types.OK, records[1].BatchRec().ResultCode)
+
+	calls := faulty.Calls()
+	require.Len(t, calls, 2)
+	require.Equal(t, keys, calls[0].Keys)
+	require.True(t, calls[0].Injected)
+	require.False(t, calls[1].Injected)
+
+	require.Panics(t, func() { FailRecords(types.DEVICE_OVERLOAD, OnMethods(MethodGet)) })
+}
+
+func TestFaultyGetMany(t *testing.T) {
+	t.Parallel()
+
+	faulty := NewFaulty(NewFake())
+	store, err := goaerospike.NewStore[user](faulty, "test", "users")
+	require.NoError(t, err)
+	ctx := context.Background()
+	for _, id := range []string{"1", "2"} {
+		require.NoError(t, store.Put(ctx, &user{ID: id, Name: "John"}))
+	}
+
+	faulty.Inject(FailRecords(types.FILTERED_OUT, OnKeys(must(aerospike.NewKey("test", "users", "2"))), Times(1)))
+	many, err := store.GetMany(ctx, []any{"1", "2", "3"})
+	require.NoError(t, err)
+	require.Equal(t, []*user{{ID: "1", Gen: 1, Name: "John"}, nil, nil}, many)
+
+	faulty.Inject(FailRecords(types.DEVICE_OVERLOAD, OnKeys(must(aerospike.NewKey("test", "users", "2"))), Times(1)))
+	_, err = store.GetMany(ctx, []any{"1", "2"})
+	requireResultCode(t, types.DEVICE_OVERLOAD, err)
+}
+
+func TestFaultyMutate(t *testing.T) {
+	t.Parallel()
+
+	faulty := NewFaulty(NewFake())
+	key := must(aerospike.NewKey("test", "users", "1"))
+	require.NoError(t, faulty.Put(nil, key, aerospike.BinMap{"name": "John", "age": 30}))
+	faulty.Inject(FailWith(types.GENERATION_ERROR, OnMethods(MethodPut), Times(1)))
+
+	got, err := goaerospike.Mutate(context.Background(), faulty, key, func(u *user) error {
+		u.Age++
+		return nil
+	}, goaerospike.MutateBackoff(time.Millisecond, time.Millisecond))
+	require.NoError(t, err)
+	require.Equal(t, 31, got.Age)
+
+	calls := faulty.Calls()
+	require.Len(t, calls, 5)
+	requireResultCode(t, types.GENERATION_ERROR, calls[2].Err)
+	require.NoError(t, calls[4].Err)
+
+	faulty.Inject(FailWith(types.GENERATION_ERROR, OnMethods(MethodPut)))
+	_, err = goaerospike.Mutate(context.Background(), faulty, key, func(u *user) error {
+		u.Age++
+		return nil
+	}, goaerospike.MutateMaxRetries(2), goaerospike.MutateBackoff(time.Millisecond, time.Millisecond))
+	require.ErrorIs(t, err, goaerospike.ErrConflict)
+}
@@ -0,0 +1,287 @@
+package aerospiketest
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	particle "github.com/aerospike/aerospike-client-go/v8/types/particle_type"
+)
+
+var errMsgpack = errors.New("invalid msgpack")
+
+// msgpackReader decodes msgpack the way aerospike encodes list and map particles and CDT operations:
+// strings and blobs carry their particle type in the first byte, extensions marking ordered
+// collections take the place of an element.
+type msgpackReader struct {
+	buf []byte
+	pos int
+}
+
+func unpackValue(buf []byte) (any, error) {
+	r := &msgpackReader{buf: buf}
+	v, _, err := r.value()
+
+	return v, err
+}
+
+func (r *msgpackReader) next(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > len(r.buf) {
+		return nil, fmt.Errorf("unexpected end of data: %w", errMsgpack)
+	}
+	b := r.buf[r.pos : r.pos+n]
+	r.pos += n
+
+	return b, nil
+}
+
+func (r *msgpackReader) readUint(n int) (uint64, error) {
+	b, err := r.next(n)
+	if err != nil {
+		return 0, err
+	}
+
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+
+	return v, nil
+}
+
+// value decodes the next object in the client representation. ext is true for extensions,
+// which carry no value.
+func (r *msgpackReader) value() (v any, ext bool, err error) {
+	b, err := r.next(1)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch t := b[0]; {
+	case t < 0x80:
+		return int(t), false, nil
+	case t >= 0xe0:
+		return int(int8(t)), false, nil
+	case t&0xf0 == 0x80:
+		v, err = r.mapValue(int(t & 0x0f))
+	case t&0xf0 == 0x90:
+		v, err = r.list(int(t & 0x0f))
+	case t&0xe0 == 0xa0:
+		v, err = r.blob(int(t & 0x1f))
+	case t == 0xc0:
+		return nil, false, nil
+	case t == 0xc2, t == 0xc3:
+		return t == 0xc3, false, nil
+	case t == 0xca:
+		var bits uint64
+		bits, err = r.readUint(4)
+		v = float64(math.Float32frombits(uint32(bits)))
+	case t == 0xcb:
+		var bits uint64
+		bits, err = r.readUint(8)
+		v = math.Float64frombits(bits)
+	case t >= 0xcc && t <= 0xcf:
+		var u uint64
+		if u, err = r.readUint(1 << (t - 0xcc)); err == nil && u > math.MaxInt64 {
+			err = fmt.Errorf("integer %d overflows int64: %w", u, errMsgpack)
+		}
+		v = int(u) //nolint:gosec
+	case t >= 0xd0 && t <= 0xd3:
+		size := 1 << (t - 0xd0)
+		var u uint64
+		u, err = r.readUint(size)
+		shift := 64 - 8*size
+		v = int(int64(u<<shift) >> shift) //nolint:gosec
+	case t == 0xc4, t == 0xd9, t == 0xc5, t == 0xda, t == 0xc6, t == 0xdb:
+		var n uint64
+		if n, err = r.readUint(lengthSize(t)); err == nil {
+			v, err = r.blob(int(n)) //nolint:gosec
+		}
+	case t == 0xdc, t == 0xdd:
+		var n uint64
+		if n, err = r.readUint(lengthSize(t)); err == nil {
+			v, err = r.list(int(n)) //nolint:gosec
+		}
+	case t == 0xde, t == 0xdf:
+		var n uint64
+		if n, err = r.readUint(lengthSize(t)); err == nil {
+			v, err = r.mapValue(int(n)) //nolint:gosec
+		}
+	case t >= 0xd4 && t <= 0xd8:
+		_, err = r.next(1 + 1<<(t-0xd4))
+		return nil, true, err
+	case t >= 0xc7 && t <= 0xc9:
+		var n uint64
+		if n, err = r.readUint(1 << (t - 0xc7)); err == nil {
+			_, err = r.next(1 + int(n)) //nolint:gosec
+		}
+		return nil, true, err
+	default:
+		err = fmt.Errorf("unsupported type 0x%x: %w", t, errMsgpack)
+	}
+
+	return v, false, err
+}
+
+// lengthSize returns the size of the length of str, bin, array and map types.
+func lengthSize(t byte) int {
+	switch t {
+	case 0xc4, 0xd9:
+		return 1
+	case 0xc5, 0xda, 0xdc, 0xde:
+		return 2
+	default:
+		return 4
+	}
+}
+
+func (r *msgpackReader) blob(n int) (any, error) {
+	b, err := r.next(n)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("blob without particle type: %w", errMsgpack)
+	}
+
+	switch b[0] {
+	case particle.STRING:
+		return string(b[1:]), nil
+	case particle.BLOB:
+		return append([]byte{}, b[1:]...), nil
+	case particle.GEOJSON:
+		return aerospike.GeoJSONValue(b[1:]), nil
+	default:
+		return nil, fmt.Errorf("unsupported particle type %d: %w", b[0], errMsgpack)
+	}
+}
+
+func (r *msgpackReader) list(n int) ([]any, error) {
+	list := make([]any, 0, n)
+	for range n {
+		v, ext, err := r.value()
+		if err != nil {
+			return nil, err
+		}
+		if !ext {
+			list = append(list, v)
+		}
+	}
+
+	return list, nil
+}
+
+func (r *msgpackReader) mapValue(n int) (map[any]any, error) {
+	m := make(map[any]any, n)
+	for range n {
+		key, ext, err := r.value()
+		if err != nil {
+			return nil, err
+		}
+		v, _, err := r.value()
+		if err != nil {
+			return nil, err
+		}
+		if ext {
+			continue
+		}
+		if _, ok := key.([]byte); ok {
+			return nil, fmt.Errorf("blob map keys are not supported: %w", errMsgpack)
+		}
+		m[key] = v
+	}
+
+	return m, nil
+}
+
+// packValue appends the msgpack encoding of the value in the client representation to buf.
+func packValue(buf []byte, v any) ([]byte, error) {
+	switch val := v.(type) {
+	case nil:
+		return append(buf, 0xc0), nil
+	case bool:
+		if val {
+			return append(buf, 0xc3), nil
+		}
+		return append(buf, 0xc2), nil
+	case int:
+		switch {
+		case val >= 0 && val < 0x80:
+			return append(buf, byte(val)), nil
+		case val >= -32 && val < 0:
+			return append(buf, byte(val)), nil //nolint:gosec
+		default:
+			return binary.BigEndian.AppendUint64(append(buf, 0xd3), uint64(val)), nil //nolint:gosec
+		}
+	case float64:
+		return binary.BigEndian.AppendUint64(append(buf, 0xcb), math.Float64bits(val)), nil
+	case string:
+		return packBlob(buf, particle.STRING, []byte(val)), nil
+	case []byte:
+		return packBlob(buf, particle.BLOB, val), nil
+	case aerospike.GeoJSONValue:
+		return packBlob(buf, particle.GEOJSON, []byte(val)), nil
+	case []any:
+		buf = packHeader(buf, 0x90, 0xdc, len(val))
+		for _, item := range val {
+			var err error
+			if buf, err = packValue(buf, item); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case map[any]any:
+		keys := make([]any, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		// Sort keys to keep the encoding stable.
+		slices.SortFunc(keys, func(a, b any) int { return strings.Compare(fmt.Sprint(a), fmt.Sprint(b)) })
+		buf = packHeader(buf, 0x80, 0xde, len(val))
+		for _, key := range keys {
+			var err error
+			if buf, err = packValue(buf, key); err != nil {
+				return nil, err
+			}
+			if buf, err = packValue(buf, val[key]); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T: %w", v, errMsgpack)
+	}
+}
+
+func packBlob(buf []byte, particleType byte, b []byte) []byte {
+	n := len(b) + 1
+	switch {
+	case n < 32:
+		buf = append(buf, 0xa0|byte(n))
+	case n < 1<<8:
+		buf = append(buf, 0xd9, byte(n))
+	case n < 1<<16:
+		buf = binary.BigEndian.AppendUint16(append(buf, 0xda), uint16(n))
+	default:
+		buf = binary.BigEndian.AppendUint32(append(buf, 0xdb), uint32(n)) //nolint:gosec
+	}
+
+	return append(append(buf, particleType), b...)
+}
+
+// packHeader appends the header of an array or a map, fix is the type of the short form
+// and long the type with the 16 bit length.
+func packHeader(buf []byte, fix, long byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, fix|byte(n))
+	case n < 1<<16:
+		return binary.BigEndian.AppendUint16(append(buf, long), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(buf, long+1), uint32(n)) //nolint:gosec
+	}
+}
@@ -0,0 +1,446 @@
+package aerospiketest
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/aerospike/aerospike-client-go/v8/types"
+)
+
+// Operation type identifiers of aerospike.OperationType, its unexported enumDist field,
+// pinned by TestDecodeOperation.
+const (
+	enumRead       = 0
+	enumReadHeader = 1
+	enumWrite      = 2
+	enumCDTRead    = 3
+	enumMapRead    = 5
+	enumAdd        = 7
+	enumAppend     = 10
+	enumPrepend    = 11
+	enumTouch      = 12
+	enumDelete     = 15
+)
+
+// Identifiers of the supported CDT commands and contexts.
+const (
+	cdtListGetByIndex = 19
+	cdtMapGetByKey    = 97
+	ctxListIndex      = 0x10
+	ctxMapKey         = 0x22
+	// ctxTypeMask drops the flags creating missing levels from context identifiers.
+	ctxTypeMask = 0x3f
+)
+
+type operationKind int
+
+const (
+	opRead operationKind = iota
+	opReadAll
+	opReadHeader
+	opWrite
+	opAdd
+	opAppend
+	opPrepend
+	opTouch
+	opDelete
+	opListGetByIndex
+	opMapGetByKey
+)
+
+// operation is a decoded *aerospike.Operation.
+type operation struct {
+	kind operationKind
+	bin  string
+	// value is the written value for writes, the index or the key for CDT reads.
+	value any
+	ctx   []*aerospike.CDTContext
+}
+
+func (o operation) writes() bool {
+	switch o.kind {
+	case opWrite, opAdd, opAppend, opPrepend, opTouch, opDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func decodeOperations(ops []*aerospike.Operation) ([]operation, aerospike.Error) {
+	if len(ops) == 0 {
+		return nil, newError(types.PARAMETER_ERROR)
+	}
+
+	decoded := make([]operation, len(ops))
+	for i, op := range ops {
+		var err aerospike.Error
+		if decoded[i], err = decodeOperation(op); err != nil {
+			return nil, err
+		}
+	}
+
+	return decoded, nil
+}
+
+// clientLayout lists the unexported fields of client types the Fake reads operations and filters from,
+// as the client has no accessors for them, with their kinds.
+var clientLayout = map[reflect.Type]map[string]reflect.Kind{
+	reflect.TypeFor[aerospike.Operation](): {
+		"opType": reflect.Struct, "opSubType": reflect.Pointer, "ctx": reflect.Slice,
+		"binName": reflect.String, "binValue": reflect.Interface,
+	},
+	reflect.TypeFor[aerospike.OperationType](): {"enumDist": reflect.Uint8},
+	reflect.TypeFor[aerospike.Filter](): {
+		"name": reflect.String, "indexName": reflect.String, "idxType": reflect.Int, "begin": reflect.Interface,
+		"end": reflect.Interface, "ctx": reflect.Slice, "expression": reflect.Pointer,
+	},
+}
+
+// layoutErr is the error of checkClientLayout, checked once.
+var layoutErr = sync.OnceValue(checkClientLayout)
+
+// checkClientLayout fails if a client release renamed or retyped the fields in clientLayout,
+// so that the Fake reports the incompatible client rather than panicking.
+func checkClientLayout() aerospike.Error {
+	for typ, fields := range clientLayout {
+		for name, kind := range fields {
+			if field, ok := typ.FieldByName(name); !ok || field.Type.Kind() != kind {
+				return unsupported(fmt.Sprintf("client version: field %s of %s is not a %s", name, typ, kind))
+			}
+		}
+	}
+
+	return nil
+}
+
+// decodeOperation reads the unexported fields of the operation.
+func decodeOperation(op *aerospike.Operation) (operation, aerospike.Error) {
+	if err := layoutErr(); err != nil {
+		return operation{}, err
+	}
+
+	rv := reflect.ValueOf(op).Elem()
+	enum := rv.FieldByName("opType").FieldByName("enumDist").Uint()
+	bin := rv.FieldByName("binName").String()
+	ctx, _ := unexported(rv.FieldByName("ctx")).([]*aerospike.CDTContext)
+	value, _ := unexported(rv.FieldByName("binValue")).(aerospike.Value)
+	var subType int
+	if ptr := rv.FieldByName("opSubType"); !ptr.IsNil() {
+		subType = int(ptr.Elem().Int())
+	}
+
+	decoded := operation{bin: bin, ctx: ctx}
+	switch enum {
+	case enumRead:
+		decoded.kind = opRead
+		if bin == "" {
+			decoded.kind = opReadAll
+		}
+	case enumReadHeader:
+		decoded.kind = opReadHeader
+	case enumWrite, enumAdd, enumAppend, enumPrepend:
+		decoded.kind = map[uint64]operationKind{
+			enumWrite: opWrite, enumAdd: opAdd, enumAppend: opAppend, enumPrepend: opPrepend,
+		}[enum]
+		if value != nil {
+			decoded.value = value.GetObject()
+		}
+	case enumTouch:
+		decoded.kind = opTouch
+	case enumDelete:
+		decoded.kind = opDelete
+	case enumCDTRead:
+		// ListValue{command, returnType, index}.
+		params, ok := value.(aerospike.ListValue)
+		if !ok || len(params) != 3 || !isInt(params[0], cdtListGetByIndex) ||
+			!isInt(params[1], int(aerospike.ListReturnTypeValue)) {
+			return operation{}, unsupported("list operation")
+		}
+		decoded.kind = opListGetByIndex
+		decoded.value, _ = storedValue(params[2])
+	case enumMapRead:
+		// ListValue{returnType, key}.
+		params, ok := value.(aerospike.ListValue)
+		if !ok || subType != cdtMapGetByKey || len(params) != 2 ||
+			!isInt(params[0], int(aerospike.MapReturnType.VALUE)) {
+			return operation{}, unsupported("map operation")
+		}
+		decoded.kind = opMapGetByKey
+		decoded.value = params[1]
+	default:
+		return operation{}, unsupported("operation")
+	}
+
+	return decoded, nil
+}
+
+// isInt reports whether the operation parameter is the integer want.
+func isInt(param any, want int) bool {
+	v, _ := storedValue(param)
+	return v == want
+}
+
+// unexported returns the value of an unexported field of an addressable struct.
+func unexported(field reflect.Value) any {
+	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem().Interface()
+}
+
+// applyOperations executes the operations on the bins in order and returns the results of reads.
+// Write operations modify the bins, so the caller passes a copy if it may need to discard them.
+func applyOperations(bins map[string]any, ops []operation) (aerospike.BinMap, aerospike.Error) {
+	results := aerospike.BinMap{}
+	for _, op := range ops {
+		switch op.kind {
+		case opReadAll:
+			for name, value := range bins {
+				results[name] = value
+			}
+		case opRead:
+			if value, ok := bins[op.bin]; ok {
+				addResult(results, op.bin, value)
+			}
+		case opListGetByIndex, opMapGetByKey:
+			value, err := readCDT(bins[op.bin], op)
+			if err != nil {
+				return nil, err
+			}
+			addResult(results, op.bin, value)
+		case opReadHeader, opTouch:
+		case opDelete:
+			clear(bins)
+		default:
+			if err := writeBin(bins, op); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// addResult adds the read value to the results, collecting values of repeated reads of a bin
+// into aerospike.OpResults like the client does.
+func addResult(results aerospike.BinMap, bin string, value any) {
+	prev, ok := results[bin]
+	if !ok {
+		results[bin] = value
+		return
+	}
+	if list, ok := prev.(aerospike.OpResults); ok {
+		results[bin] = append(list, value)
+		return
+	}
+	results[bin] = aerospike.OpResults{prev, value}
+}
+
+func writeBin(bins map[string]any, op operation) aerospike.Error {
+	if len(op.bin) > maxBinNameLength {
+		return newError(types.BIN_NAME_TOO_LONG)
+	}
+	value, err := storedValue(op.value)
+	if err != nil {
+		return err
+	}
+
+	current, exists := bins[op.bin]
+	switch {
+	case op.kind == opWrite:
+		if value == nil {
+			delete(bins, op.bin)
+			return nil
+		}
+	case op.kind == opAdd:
+		if value, err = add(current, exists, value); err != nil {
+			return err
+		}
+	case exists:
+		if value, err = concat(current, value, op.kind == opPrepend); err != nil {
+			return err
+		}
+	default:
+		if _, ok := value.(string); !ok {
+			if _, ok := value.([]byte); !ok {
+				return newError(types.PARAMETER_ERROR)
+			}
+		}
+	}
+	bins[op.bin] = value
+
+	return nil
+}
+
+func add(current any, exists bool, delta any) (any, aerospike.Error) {
+	switch d := delta.(type) {
+	case int:
+		if !exists {
+			return d, nil
+		}
+		if c, ok := current.(int); ok {
+			return c + d, nil
+		}
+	case float64:
+		if !exists {
+			return d, nil
+		}
+		if c, ok := current.(float64); ok {
+			return c + d, nil
+		}
+	default:
+		return nil, newError(types.PARAMETER_ERROR)
+	}
+
+	return nil, newError(types.BIN_TYPE_ERROR)
+}
+
+func concat(current, value any, prepend bool) (any, aerospike.Error) {
+	switch c := current.(type) {
+	case string:
+		if v, ok := value.(string); ok {
+			if prepend {
+				return v + c, nil
+			}
+			return c + v, nil
+		}
+	case []byte:
+		if v, ok := value.([]byte); ok {
+			if prepend {
+				return append(append([]byte{}, v...), c...), nil
+			}
+			return append(append([]byte{}, c...), v...), nil
+		}
+	}
+
+	return nil, newError(types.BIN_TYPE_ERROR)
+}
+
+// readCDT reads the list element or the map value the operation selects.
+func readCDT(value any, op operation) (any, aerospike.Error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	steps := make([]*aerospike.CDTContext, 0, len(op.ctx)+1)
+	steps = append(steps, op.ctx...)
+	if op.kind == opListGetByIndex {
+		steps = append(steps, aerospike.CtxListIndex(op.value.(int))) //nolint:forcetypeassert
+	} else {
+		steps = append(steps, &aerospike.CDTContext{Id: ctxMapKey, Value: aerospike.NewValue(op.value)})
+	}
+
+	for _, step := range steps {
+		if step.Expression != nil {
+			return nil, unsupported("expression context")
+		}
+		var err aerospike.Error
+		if value, err = cdtStep(value, step); err != nil || value == nil {
+			return nil, err
+		}
+	}
+
+	return value, nil
+}
+
+func cdtStep(value any, step *aerospike.CDTContext) (any, aerospike.Error) {
+	switch step.Id & ctxTypeMask {
+	case ctxListIndex:
+		list, ok := value.([]any)
+		if !ok {
+			return nil, newError(types.BIN_TYPE_ERROR)
+		}
+		index, _ := step.Value.GetObject().(int)
+		if index < 0 {
+			index += len(list)
+		}
+		if index < 0 || index >= len(list) {
+			return nil, newError(types.OP_NOT_APPLICABLE)
+		}
+		return list[index], nil
+	case ctxMapKey:
+		m, ok := value.(map[any]any)
+		if !ok {
+			return nil, newError(types.BIN_TYPE_ERROR)
+		}
+		key, err := storedValue(step.Value)
+		if err != nil {
+			return nil, err
+		}
+		if key != nil && !reflect.TypeOf(key).Comparable() {
+			return nil, newError(types.PARAMETER_ERROR)
+		}
+		return m[key], nil
+	default:
+		return nil, unsupported("context")
+	}
+}
+
+// storedValue converts v into the representation the client reads it back in: integers become int,
+// floats float64, slices and arrays []any, maps map[any]any.
+func storedValue(v any) (any, aerospike.Error) {
+	switch val := v.(type) {
+	case nil, bool, int, float64, string, []byte, aerospike.GeoJSONValue:
+		return v, nil
+	case aerospike.Value:
+		obj := val.GetObject()
+		if _, ok := obj.(aerospike.Value); ok {
+			return nil, newError(types.PARAMETER_ERROR)
+		}
+		return storedValue(obj)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return storedValue(rv.Elem().Interface())
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return nil, newError(types.PARAMETER_ERROR)
+		}
+		return int(rv.Uint()), nil //nolint:gosec
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Bytes(), nil
+		}
+		fallthrough
+	case reflect.Array:
+		out := make([]any, rv.Len())
+		for i := range out {
+			var err aerospike.Error
+			if out[i], err = storedValue(rv.Index(i).Interface()); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	case reflect.Map:
+		out := make(map[any]any, rv.Len())
+		for iter := rv.MapRange(); iter.Next(); {
+			key, err := storedValue(iter.Key().Interface())
+			if err != nil {
+				return nil, err
+			}
+			if key != nil && !reflect.TypeOf(key).Comparable() {
+				return nil, newError(types.PARAMETER_ERROR)
+			}
+			if out[key], err = storedValue(iter.Value().Interface()); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	default:
+		return nil, newError(types.PARAMETER_ERROR)
+	}
+}
@@ -0,0 +1,118 @@
+package aerospiketest
+
+import (
+	"testing"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/aerospike/aerospike-client-go/v8/types"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClientLayout fails when a client release changes the unexported fields the Fake reads.
+func TestClientLayout(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, checkClientLayout())
+}
+
+// TestDecodeOperation pins the operation type identifiers of the client.
+func TestDecodeOperation(t *testing.T) {
+	t.Parallel()
+
+	ctx := []*aerospike.CDTContext{aerospike.CtxMapKey(aerospike.NewValue("a"))}
+	tests := []struct {
+		name string
+		op   *aerospike.Operation
+		want operation
+	}{
+		{name: "read", op: aerospike.GetBinOp("a"), want: operation{kind: opRead, bin: "a"}},
+		{name: "read all", op: aerospike.GetOp(), want: operation{kind: opReadAll}},
+		{name: "read header", op: aerospike.GetHeaderOp(), want: operation{kind: opReadHeader}},
+		{name: "write", op: aerospike.PutOp(aerospike.NewBin("a", 1)), want: operation{kind: opWrite, bin: "a", value: 1}},
+		{name: "add", op: aerospike.AddOp(aerospike.NewBin("a", 1)), want: operation{kind: opAdd, bin: "a", value: 1}},
+		{
+			name: "append", op: aerospike.AppendOp(aerospike.NewBin("a", "x")),
+			want: operation{kind: opAppend, bin: "a", value: "x"},
+		},
+		{
+			name: "prepend", op: aerospike.PrependOp(aerospike.NewBin("a", "x")),
+			want: operation{kind: opPrepend, bin: "a", value: "x"},
+		},
+		{name: "touch", op: aerospike.TouchOp(), want: operation{kind: opTouch}},
+		{name: "delete", op: aerospike.DeleteOp(), want: operation{kind: opDelete}},
+		{
+			name: "list get by index", op: aerospike.ListGetByIndexOp("a", 2, aerospike.ListReturnTypeValue, ctx...),
+			want: operation{kind: opListGetByIndex, bin: "a", value: 2, ctx: ctx},
+		},
+		{
+			name: "map get by key", op: aerospike.MapGetByKeyOp("a", "k", aerospike.MapReturnType.VALUE),
+			want: operation{kind: opMapGetByKey, bin: "a", value: "k"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := decodeOperation(tt.op)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := decodeOperation(aerospike.ListSizeOp("a"))
+	requireResultCode(t, types.UNSUPPORTED_FEATURE, err)
+	require.ErrorContains(t, err, "unsupported list operation")
+}
+
+// TestFilterMatcher pins the fields of secondary index filters.
+func TestFilterMatcher(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		filter *aerospike.Filter
+		match  map[string]any
+		miss   map[string]any
+	}{
+		{
+			name:   "equal",
+			filter: aerospike.NewEqualFilter("a", "x"),
+			match:  map[string]any{"a": "x"},
+			miss:   map[string]any{"a": "y"},
+		},
+		{
+			name:   "range",
+			filter: aerospike.NewRangeFilter("a", 1, 3),
+			match:  map[string]any{"a": 2},
+			miss:   map[string]any{"a": 4},
+		},
+		{
+			name:   "nested list",
+			filter: aerospike.NewContainsFilter("a", aerospike.ICT_LIST, "x", aerospike.CtxMapKey(aerospike.NewValue("k"))),
+			match:  map[string]any{"a": map[any]any{"k": []any{"x"}}},
+			miss:   map[string]any{"a": map[any]any{"k": []any{"y"}}},
+		},
+		{
+			name:   "map keys",
+			filter: aerospike.NewContainsFilter("a", aerospike.ICT_MAPKEYS, "k"),
+			match:  map[string]any{"a": map[any]any{"k": 1}},
+			miss:   map[string]any{"a": map[any]any{"j": 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			matches, err := filterMatcher(tt.filter)
+			require.NoError(t, err)
+			require.True(t, matches(tt.match))
+			require.False(t, matches(tt.miss))
+		})
+	}
+
+	_, err := filterMatcher(aerospike.NewEqualWithExpressionFilter(aerospike.ExpIntBin("a"), 1))
+	requireResultCode(t, types.UNSUPPORTED_FEATURE, err)
+	require.ErrorContains(t, err, "unsupported expression index")
+	_, err = filterMatcher(aerospike.NewEqualWithIndexNameFilter("idx", 1))
+	require.ErrorContains(t, err, "unsupported index name filter")
+}
@@ -0,0 +1,246 @@
+package aerospiketest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"slices"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+
+	goaerospike "github.com/viru-tech/go.aerospike"
+)
+
+// partitionCount is the number of partitions of a namespace.
+const partitionCount = 4096
+
+// Query returns records of the statement set matching its index filter in the partitions of the filter.
+// Records of a partition are returned in digest order, so MaxRecords of the policy paginates queries
+// the same way the server does.
+func (f *Fake) Query(
+	policy *aerospike.QueryPolicy,
+	stmt *aerospike.Statement,
+	filter *aerospike.PartitionFilter,
+) (goaerospike.Recordset, aerospike.Error) {
+	if policy == nil {
+		policy = aerospike.NewQueryPolicy()
+	}
+
+	match := func(map[string]any) bool { return true }
+	if stmt.Filter != nil {
+		var err aerospike.Error
+		if match, err = filterMatcher(stmt.Filter); err != nil {
+			return nil, err
+		}
+	}
+
+	return f.scan(&policy.MultiPolicy, filter, stmt.Namespace, stmt.SetName, stmt.BinNames, match)
+}
+
+// Scan returns records of the set in the partitions of the filter, all records of the namespace
+// if set is empty.
+func (f *Fake) Scan(
+	policy *aerospike.ScanPolicy,
+	filter *aerospike.PartitionFilter,
+	namespace, set string,
+	binNames ...string,
+) (goaerospike.Recordset, aerospike.Error) {
+	if policy == nil {
+		policy = aerospike.NewScanPolicy()
+	}
+
+	return f.scan(&policy.MultiPolicy, filter, namespace, set, binNames, func(map[string]any) bool { return true })
+}
+
+func (f *Fake) scan(
+	policy *aerospike.MultiPolicy,
+	filter *aerospike.PartitionFilter,
+	namespace, set string,
+	binNames []string,
+	match func(map[string]any) bool,
+) (goaerospike.Recordset, aerospike.Error) {
+	if policy.FilterExpression != nil {
+		return nil, unsupported("filter expressions")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	partitions := make(map[int][]*record)
+	for id, rec := range f.records {
+		if rec.namespace != namespace || (set != "" && rec.set != set) {
+			continue
+		}
+		if rec = f.live(id); rec != nil && match(rec.bins) {
+			partition := partitionID(rec.digest)
+			partitions[partition] = append(partitions[partition], rec)
+		}
+	}
+
+	if len(filter.Partitions) == 0 {
+		filter.Partitions = make([]*aerospike.PartitionStatus, filter.Count)
+		for i := range filter.Partitions {
+			filter.Partitions[i] = &aerospike.PartitionStatus{Id: filter.Begin + i, Retry: true}
+		}
+		if len(filter.Partitions) > 0 {
+			filter.Partitions[0].Digest = filter.Digest
+		}
+		filter.Retry = true
+	} else if policy.MaxRecords <= 0 {
+		filter.Retry = true
+	}
+
+	var results []*aerospike.Result
+	for _, status := range filter.Partitions {
+		if status == nil || (!filter.Retry && !status.Retry) {
+			continue
+		}
+
+		records := partitions[status.Id]
+		slices.SortFunc(records, func(a, b *record) int { return bytes.Compare(a.digest, b.digest) })
+		if status.Digest != nil {
+			records = slices.DeleteFunc(records, func(rec *record) bool {
+				return bytes.Compare(rec.digest, status.Digest) <= 0
+			})
+		}
+
+		status.Retry = false
+		for _, rec := range records {
+			if policy.MaxRecords > 0 && int64(len(results)) >= policy.MaxRecords {
+				status.Retry = true
+				break
+			}
+			result, err := f.scanResult(rec, binNames, policy.IncludeBinData)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+			status.Digest = rec.digest
+		}
+	}
+
+	filter.Retry = false
+	filter.Done = !slices.ContainsFunc(filter.Partitions, func(status *aerospike.PartitionStatus) bool {
+		return status != nil && status.Retry
+	})
+
+	return newRecordset(results), nil
+}
+
+func (f *Fake) scanResult(rec *record, binNames []string, includeBins bool) (*aerospike.Result, aerospike.Error) {
+	key, err := aerospike.NewKeyWithDigest(rec.namespace, rec.set, rec.userKey, rec.digest)
+	if err != nil {
+		return nil, err
+	}
+
+	var bins aerospike.BinMap
+	if includeBins {
+		bins = make(aerospike.BinMap, len(rec.bins))
+		for name, value := range rec.bins {
+			if len(binNames) == 0 || slices.Contains(binNames, name) {
+				bins[name] = value
+			}
+		}
+	}
+
+	return &aerospike.Result{Record: f.result(key, rec, bins)}, nil
+}
+
+// filterMatcher returns the function reporting whether the bins match the secondary index filter.
+func filterMatcher(filter *aerospike.Filter) (func(map[string]any) bool, aerospike.Error) {
+	if err := layoutErr(); err != nil {
+		return nil, err
+	}
+
+	rv := reflect.ValueOf(filter).Elem()
+	if !rv.FieldByName("expression").IsNil() {
+		return nil, unsupported("expression index")
+	}
+	if rv.FieldByName("indexName").String() != "" {
+		return nil, unsupported("index name filter")
+	}
+	bin := rv.FieldByName("name").String()
+	collection := aerospike.IndexCollectionType(rv.FieldByName("idxType").Int())
+	ctx, _ := unexported(rv.FieldByName("ctx")).([]*aerospike.CDTContext)
+	begin, err := storedValue(unexported(rv.FieldByName("begin")))
+	if err != nil {
+		return nil, err
+	}
+	end, err := storedValue(unexported(rv.FieldByName("end")))
+	if err != nil {
+		return nil, err
+	}
+
+	matchValue := func(v any) bool {
+		if b, ok := begin.(int); ok {
+			e, _ := end.(int)
+			i, ok := v.(int)
+			return ok && b <= i && i <= e
+		}
+		if b, ok := begin.([]byte); ok {
+			v, ok := v.([]byte)
+			return ok && bytes.Equal(b, v)
+		}
+		return begin != nil && v == begin
+	}
+
+	return func(bins map[string]any) bool {
+		value := bins[bin]
+		for _, step := range ctx {
+			if step.Expression != nil || value == nil {
+				return false
+			}
+			var err aerospike.Error
+			if value, err = cdtStep(value, step); err != nil {
+				return false
+			}
+		}
+
+		switch collection {
+		case aerospike.ICT_LIST:
+			list, _ := value.([]any)
+			return slices.ContainsFunc(list, matchValue)
+		case aerospike.ICT_MAPKEYS, aerospike.ICT_MAPVALUES:
+			m, _ := value.(map[any]any)
+			for k, v := range m {
+				if collection == aerospike.ICT_MAPKEYS && matchValue(k) ||
+					collection == aerospike.ICT_MAPVALUES && matchValue(v) {
+					return true
+				}
+			}
+			return false
+		default:
+			return matchValue(value)
+		}
+	}, nil
+}
+
+// partitionID returns the partition of the record digest, like (*aerospike.Key).PartitionId.
+func partitionID(digest []byte) int {
+	return int(binary.LittleEndian.Uint32(digest[:4]) % partitionCount)
+}
+
+// recordset streams results collected by a query or a scan.
+type recordset struct {
+	results chan *aerospike.Result
+}
+
+func newRecordset(results []*aerospike.Result) *recordset {
+	rs := &recordset{results: make(chan *aerospike.Result, len(results))}
+	for _, result := range results {
+		rs.results <- result
+	}
+	close(rs.results)
+
+	return rs
+}
+
+// Results returns the channel of results.
+func (rs *recordset) Results() <-chan *aerospike.Result {
+	return rs.results
+}
+
+// Close does nothing, all results are already collected.
+func (rs *recordset) Close() aerospike.Error {
+	return nil
+}
@@ -0,0 +1,191 @@
+package aerospiketest
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/stretchr/testify/require"
+
+	goaerospike "github.com/viru-tech/go.aerospike"
+)
+
+// maxFuzzLength limits lengths of generated strings, slices and maps, maxFuzzDepth the nesting
+// of generated pointers and structs.
+const (
+	maxFuzzLength = 8
+	maxFuzzDepth  = 4
+)
+
+// RoundTripOption configures RoundTrip and Fuzz.
+type RoundTripOption func(*roundTripOptions)
+
+type roundTripOptions struct {
+	codec *goaerospike.Codec
+}
+
+// WithCodec sets the codec values are marshaled and unmarshaled with, goaerospike.DefaultCodec by default.
+func WithCodec(codec *goaerospike.Codec) RoundTripOption {
+	return func(o *roundTripOptions) {
+		o.codec = codec
+	}
+}
+
+func newRoundTripOptions(opts []RoundTripOption) roundTripOptions {
+	o := roundTripOptions{codec: goaerospike.DefaultCodec()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// RoundTrip marshals v, converts the bins into the representation the client reads them back in
+// from the server, unmarshals them into a new value and asserts it equals v. Fields not stored
+// as bins, e.g. the key and metadata fields, are copied from v.
+//
+// Bins are converted with Codec.Normalize. Nil slices and maps are read back empty.
+func RoundTrip[T any](t testing.TB, v *T, opts ...RoundTripOption) {
+	t.Helper()
+
+	codec := newRoundTripOptions(opts).codec
+	bins, err := codec.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal %T: %v", v, err)
+		return
+	}
+
+	for name := range bins {
+		if len(name) > maxBinNameLength {
+			t.Errorf("bin name %q is longer than %d characters", name, maxBinNameLength)
+		}
+	}
+	stored := codec.Normalize(bins)
+
+	got := new(T)
+	*got = *v
+	clearBinFields(codec, reflect.ValueOf(got).Elem())
+	if err := codec.Unmarshal(&aerospike.Record{Bins: stored}, got); err != nil {
+		t.Fatalf("failed to unmarshal %T from %v: %v", v, stored, err)
+		return
+	}
+
+	require.Equal(t, v, got, "bins %v", stored)
+}
+
+// Fuzz fuzzes round trips of T with values generated from the fuzzing input, see RoundTrip.
+// Call it from a fuzz test:
+//
+//	func FuzzUser(f *testing.F) {
+//		aerospiketest.Fuzz[User](f)
+//	}
+//
+// Generated values have all exported fields set, pointers allocated, slices and maps non-nil,
+// times in UTC with seconds precision, interfaces, channels and functions are left nil.
+func Fuzz[T any](f *testing.F, opts ...RoundTripOption) {
+	f.Add([]byte{})
+	f.Add([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	f.Add([]byte(strings.Repeat("\xff\x00\x7f\x80", 16)))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		v := new(T)
+		generate(reflect.ValueOf(v).Elem(), &fuzzInput{data: data}, 0)
+		RoundTrip(t, v, opts...)
+	})
+}
+
+// clearBinFields zeroes the fields of the struct the codec stores as bins.
+func clearBinFields(codec *goaerospike.Codec, v reflect.Value) {
+	for _, bin := range codec.Describe(v.Type()).Bins {
+		v.FieldByName(bin.Field).SetZero()
+	}
+}
+
+// fuzzInput hands out bytes of the fuzzing input, zeros once it is exhausted.
+type fuzzInput struct {
+	data []byte
+}
+
+func (in *fuzzInput) bytes(n int) []byte {
+	b := make([]byte, n)
+	in.data = in.data[copy(b, in.data):]
+
+	return b
+}
+
+func (in *fuzzInput) uint64() uint64 {
+	return binary.LittleEndian.Uint64(in.bytes(8))
+}
+
+func (in *fuzzInput) length() int {
+	return int(in.bytes(1)[0]) % (maxFuzzLength + 1)
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// generate sets v to a value read from the input.
+func generate(v reflect.Value, in *fuzzInput, depth int) {
+	if v.Type() == timeType {
+		v.Set(reflect.ValueOf(time.Unix(int64(int32(in.uint64())), 0).UTC())) //nolint:gosec
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(in.bytes(1)[0]&1 == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(in.uint64())) //nolint:gosec
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(in.uint64())
+	case reflect.Float32, reflect.Float64:
+		f := math.Float64frombits(in.uint64())
+		if v.Kind() == reflect.Float32 {
+			f = float64(math.Float32frombits(uint32(math.Float64bits(f))))
+		}
+		if math.IsNaN(f) {
+			f = 0
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		v.SetString(string(in.bytes(in.length())))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(in.bytes(in.length()))
+			return
+		}
+		v.Set(reflect.MakeSlice(v.Type(), in.length(), maxFuzzLength))
+		fallthrough
+	case reflect.Array:
+		for i := range v.Len() {
+			generate(v.Index(i), in, depth)
+		}
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+		for range in.length() {
+			key := reflect.New(v.Type().Key()).Elem()
+			generate(key, in, depth)
+			value := reflect.New(v.Type().Elem()).Elem()
+			generate(value, in, depth)
+			v.SetMapIndex(key, value)
+		}
+	case reflect.Pointer:
+		if depth < maxFuzzDepth {
+			v.Set(reflect.New(v.Type().Elem()))
+			generate(v.Elem(), in, depth+1)
+		}
+	case reflect.Struct:
+		if depth >= maxFuzzDepth {
+			return
+		}
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				generate(v.Field(i), in, depth+1)
+			}
+		}
+	default:
+	}
+}
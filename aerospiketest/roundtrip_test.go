@@ -0,0 +1,138 @@
+package aerospiketest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	goaerospike "github.com/viru-tech/go.aerospike"
+)
+
+type profile struct {
+	ID      string           `as:",key"`
+	Gen     uint32           `as:",generation"`
+	Name    string           `as:"name"`
+	Age     uint8            `as:"age"`
+	Score   float32          `as:"score"`
+	Active  bool             `as:"active"`
+	Avatar  []byte           `as:"avatar"`
+	Tags    []string         `as:"tags"`
+	Counts  map[string]int64 `as:"counts"`
+	Created time.Time        `as:"created"`
+	Nick    *string          `as:"nick"`
+	Address address          `as:"address"`
+	Extra   map[int]string   `as:"extra,omitempty"`
+}
+
+type address struct {
+	City string `as:"city"`
+	Zip  int    `as:"zip"`
+}
+
+// recorder records failures of assertions instead of failing the test.
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(string, ...any) { r.failed = true }
+
+func (r *recorder) Fatalf(string, ...any) { r.failed = true }
+
+func (r *recorder) FailNow() { r.failed = true }
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	nick := "jd"
+	tests := []struct {
+		name       string
+		roundTrip  func(t testing.TB)
+		wantFailed bool
+	}{
+		{
+			name: "all types",
+			roundTrip: func(t testing.TB) {
+				RoundTrip(t, &profile{
+					ID:      "1",
+					Gen:     2,
+					Name:    "John",
+					Age:     30,
+					Score:   1.5,
+					Active:  true,
+					Avatar:  []byte{1, 2},
+					Tags:    []string{"a", "b"},
+					Counts:  map[string]int64{"a": -1},
+					Created: time.Unix(1700000000, 0).UTC(),
+					Nick:    &nick,
+					Address: address{City: "Paris", Zip: 75001},
+				})
+			},
+		},
+		{
+			name: "nil slice",
+			roundTrip: func(t testing.TB) {
+				RoundTrip(t, &profile{Counts: map[string]int64{}, Nick: &nick})
+			},
+			wantFailed: true,
+		},
+		{
+			name: "time precision",
+			roundTrip: func(t testing.TB) {
+				RoundTrip(t, &profile{
+					Tags:    []string{},
+					Counts:  map[string]int64{},
+					Created: time.Unix(1700000000, 5).UTC(),
+					Nick:    &nick,
+				})
+			},
+			wantFailed: true,
+		},
+		{
+			name: "codec",
+			roundTrip: func(t testing.TB) {
+				codec := goaerospike.NewCodec(goaerospike.WithTagName("db"), goaerospike.WithTimeFormat(goaerospike.TimeUnixMilli))
+				RoundTrip(t, &struct {
+					ID      string    `db:",key"`
+					Name    string    `db:"name"`
+					Created time.Time `db:"created"`
+				}{ID: "1", Name: "John", Created: time.UnixMilli(1700000000005).UTC()}, WithCodec(codec))
+			},
+		},
+		{
+			name: "codec time precision",
+			roundTrip: func(t testing.TB) {
+				codec := goaerospike.NewCodec(goaerospike.WithNaming(goaerospike.SnakeCase))
+				RoundTrip(t, &struct {
+					CreatedAt time.Time
+				}{CreatedAt: time.UnixMilli(1700000000005).UTC()}, WithCodec(codec))
+			},
+			wantFailed: true,
+		},
+		{
+			name: "long bin name",
+			roundTrip: func(t testing.TB) {
+				RoundTrip(t, &struct {
+					Value int `as:"a_very_long_bin_name"`
+				}{Value: 1})
+			},
+			wantFailed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := &recorder{TB: t}
+			tt.roundTrip(r)
+			require.Equal(t, tt.wantFailed, r.failed)
+		})
+	}
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	Fuzz[profile](f)
+}
@@ -0,0 +1,297 @@
+package aerospiketest
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Protocol message types.
+const (
+	protoVersion        = 2
+	protoInfo           = 1
+	protoMessage        = 3
+	protoMessageCompact = 4
+	// protoHeaderSize is the size of the protocol header holding the version, the type and the size.
+	protoHeaderSize = 8
+	// maxMessageSize protects the server from allocating memory for corrupted sizes.
+	maxMessageSize = 128 << 20
+)
+
+// serverBuild is the server version reported to clients. Clients use batch and partition query
+// protocols of servers 6.0 and newer.
+const serverBuild = "7.2.0.0"
+
+var errProtocol = errors.New("protocol error")
+
+// Server is a local TCP server speaking enough of the aerospike wire protocol for *aerospike.Client
+// to work with it: info commands of cluster discovery and partition maps, single record reads,
+// writes, deletes and Operate, and batch commands. Records are kept by a Fake, so they follow
+// its semantics and support the same operations. The server is a single node cluster owning all
+// partitions of its namespaces.
+// Queries, scans, UDFs, filter expressions and transactions fail with UNSUPPORTED_FEATURE.
+type Server struct {
+	fake        *Fake
+	namespaces  []string
+	clusterName string
+	listener    net.Listener
+	node        string
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// ServerOption configures a Server.
+type ServerOption func(*Server)
+
+// WithFake serves records of the fake. Default is an empty Fake.
+func WithFake(fake *Fake) ServerOption {
+	return func(s *Server) {
+		s.fake = fake
+	}
+}
+
+// WithNamespaces sets the namespaces of the server. Default is "test".
+func WithNamespaces(namespaces ...string) ServerOption {
+	return func(s *Server) {
+		s.namespaces = namespaces
+	}
+}
+
+// WithClusterName sets the cluster name the server reports, checked by clients configured
+// with ClientPolicy.ClusterName.
+func WithClusterName(name string) ServerOption {
+	return func(s *Server) {
+		s.clusterName = name
+	}
+}
+
+// NewServer starts a server listening on a random port of 127.0.0.1.
+// Connect to it with aerospike.NewClient(server.Host(), server.Port()) and stop it with Close.
+func NewServer(opts ...ServerOption) (*Server, error) {
+	s := &Server{
+		namespaces: []string{"test"},
+		conns:      make(map[net.Conn]struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.fake == nil {
+		s.fake = NewFake()
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	s.listener = listener
+	s.node = fmt.Sprintf("BB9%013X", s.Port())
+
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+// Host returns the address the server listens on.
+func (s *Server) Host() string {
+	return s.listener.Addr().(*net.TCPAddr).IP.String() //nolint:forcetypeassert
+}
+
+// Port returns the port the server listens on.
+func (s *Server) Port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port //nolint:forcetypeassert
+}
+
+// Fake returns the fake holding records of the server, e.g. to seed them or advance its clock.
+func (s *Server) Fake() *Fake {
+	return s.fake
+}
+
+// Close stops the server, closing all client connections.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+
+	err := s.listener.Close()
+	s.wg.Wait()
+
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.handle(conn)
+	}
+}
+
+// handle serves requests of the connection until the client closes it or sends a malformed message.
+func (s *Server) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	for {
+		typ, body, err := readProto(r)
+		if err != nil {
+			return
+		}
+
+		var response []byte
+		switch typ {
+		case protoInfo:
+			response = proto(protoInfo, s.info(body))
+		case protoMessage:
+			response = proto(protoMessage, s.message(body))
+		default:
+			return
+		}
+		if _, err := conn.Write(response); err != nil {
+			return
+		}
+	}
+}
+
+// readProto reads a protocol message, decompressing compressed ones.
+func readProto(r io.Reader) (byte, []byte, error) {
+	var header [protoHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	if header[0] != protoVersion {
+		return 0, nil, fmt.Errorf("unsupported version %d: %w", header[0], errProtocol)
+	}
+	size := binary.BigEndian.Uint64(header[:]) & (1<<48 - 1)
+	if size > maxMessageSize {
+		return 0, nil, fmt.Errorf("message size %d: %w", size, errProtocol)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	if header[1] != protoMessageCompact {
+		return header[1], body, nil
+	}
+
+	// Compressed messages hold the size of the original message followed by the zlib stream of it.
+	if len(body) < 8 {
+		return 0, nil, fmt.Errorf("compressed message too short: %w", errProtocol)
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(body[8:]))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer zr.Close()
+
+	return readProto(zr)
+}
+
+func proto(typ byte, body []byte) []byte {
+	header := uint64(protoVersion)<<56 | uint64(typ)<<48 | uint64(len(body))
+
+	return append(binary.BigEndian.AppendUint64(nil, header), body...)
+}
+
+// info answers the info commands of the request, one per line.
+func (s *Server) info(request []byte) []byte {
+	var response strings.Builder
+	for _, command := range strings.Split(strings.TrimSpace(string(request)), "\n") {
+		if command == "" {
+			continue
+		}
+		response.WriteString(command)
+		response.WriteByte('\t')
+		response.WriteString(s.infoValue(command))
+		response.WriteByte('\n')
+	}
+
+	return []byte(response.String())
+}
+
+func (s *Server) infoValue(command string) string {
+	name, _, _ := strings.Cut(command, ":")
+	switch name {
+	case "node":
+		return s.node
+	case "build":
+		return serverBuild
+	case "cluster-name":
+		return s.clusterName
+	case "partition-generation", "peers-generation", "rebalance-generation":
+		return "1"
+	case "peers-clear-std", "peers-clear-alt", "peers-tls-std", "peers-tls-alt":
+		// Generation, default port and an empty list of other nodes.
+		return "1," + strconv.Itoa(s.Port()) + ",[]"
+	case "service-clear-std", "service-clear-alt", "service-tls-std", "service-tls-alt", "services":
+		return net.JoinHostPort(s.Host(), strconv.Itoa(s.Port()))
+	case "replicas", "replicas-all":
+		return s.replicas(name == "replicas")
+	case "namespaces":
+		return strings.Join(s.namespaces, ";")
+	case "user-agent-set":
+		return "ok"
+	default:
+		return "ERROR:4:unsupported command"
+	}
+}
+
+// replicas returns the partition map with a single replica of all partitions of every namespace
+// owned by the server.
+func (s *Server) replicas(withRegime bool) string {
+	bitmap := make([]byte, partitionCount/8)
+	for i := range bitmap {
+		bitmap[i] = 0xff
+	}
+	encoded := base64.StdEncoding.EncodeToString(bitmap)
+
+	var b strings.Builder
+	for _, namespace := range s.namespaces {
+		b.WriteString(namespace)
+		b.WriteByte(':')
+		if withRegime {
+			b.WriteString("0,")
+		}
+		b.WriteString("1,")
+		b.WriteString(encoded)
+		b.WriteByte(';')
+	}
+
+	return b.String()
+}
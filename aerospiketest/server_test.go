@@ -0,0 +1,288 @@
+package aerospiketest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/aerospike/aerospike-client-go/v8/types"
+	"github.com/stretchr/testify/require"
+
+	goaerospike "github.com/viru-tech/go.aerospike"
+)
+
+func newTestClient(t *testing.T, opts ...ServerOption) (*Server, *aerospike.Client) {
+	t.Helper()
+
+	server, err := NewServer(opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, server.Close()) })
+
+	client, err := aerospike.NewClient(server.Host(), server.Port())
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	return server, client
+}
+
+func TestServerCommands(t *testing.T) {
+	t.Parallel()
+
+	server, client := newTestClient(t)
+	key, err := aerospike.NewKey("test", "commands", "key")
+	require.NoError(t, err)
+
+	bins := aerospike.BinMap{
+		"int":   1,
+		"float": 1.5,
+		"str":   "b",
+		"bytes": []byte{1, 2},
+		"bool":  true,
+		"list":  []any{1, "a", []any{2, 3}},
+		"map":   map[any]any{"a": map[any]any{"b": "c"}, 1: 2.5},
+	}
+	policy := aerospike.NewWritePolicy(0, 100)
+	policy.SendKey = true
+	require.NoError(t, client.Put(policy, key, bins))
+
+	record, err := client.Get(nil, key)
+	require.NoError(t, err)
+	require.Equal(t, bins, record.Bins)
+	require.Equal(t, uint32(1), record.Generation)
+	require.InDelta(t, 100, record.Expiration, 1)
+
+	record, err = client.Get(nil, key, "int", "missing")
+	require.NoError(t, err)
+	require.Equal(t, aerospike.BinMap{"int": 1}, record.Bins)
+
+	record, err = client.Operate(nil, key,
+		aerospike.AddOp(aerospike.NewBin("int", 2)),
+		aerospike.AppendOp(aerospike.NewBin("str", "c")),
+		aerospike.PrependOp(aerospike.NewBin("str", "a")),
+		aerospike.GetBinOp("int"),
+		aerospike.GetBinOp("str"),
+		aerospike.PutOp(aerospike.NewBin("int", 5)),
+		aerospike.GetBinOp("int"),
+		aerospike.ListGetByIndexOp("list", -1, aerospike.ListReturnTypeValue, aerospike.CtxListIndex(2)),
+		aerospike.MapGetByKeyOp("map", "b", aerospike.MapReturnType.VALUE, aerospike.CtxMapKey(aerospike.NewValue("a"))),
+	)
+	require.NoError(t, err)
+	require.Equal(t, aerospike.BinMap{"int": aerospike.OpResults{3, 5}, "str": "abc", "list": 3, "map": "c"}, record.Bins)
+	require.Equal(t, uint32(2), record.Generation)
+
+	exists, err := client.Exists(nil, key)
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.NoError(t, client.Touch(nil, key))
+
+	header, err := client.GetHeader(nil, key)
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), header.Generation)
+
+	require.NoError(t, client.Put(nil, key, aerospike.BinMap{"str": nil}))
+	record, err = client.Get(nil, key, "str")
+	require.NoError(t, err)
+	require.Empty(t, record.Bins)
+
+	existed, err := client.Delete(nil, key)
+	require.NoError(t, err)
+	require.True(t, existed)
+	existed, err = client.Delete(nil, key)
+	require.NoError(t, err)
+	require.False(t, existed)
+	_, err = client.Get(nil, key)
+	require.ErrorIs(t, err, aerospike.ErrKeyNotFound)
+	require.Zero(t, server.Fake().Len())
+}
+
+func TestServerErrors(t *testing.T) {
+	t.Parallel()
+
+	_, client := newTestClient(t)
+	key, err := aerospike.NewKey("test", "errors", "key")
+	require.NoError(t, err)
+	require.NoError(t, client.Put(nil, key, aerospike.BinMap{"str": "a", "list": []any{1}}))
+
+	withPolicy := func(modify func(*aerospike.WritePolicy)) *aerospike.WritePolicy {
+		policy := aerospike.NewWritePolicy(0, 0)
+		modify(policy)
+		return policy
+	}
+
+	tests := []struct {
+		name     string
+		policy   *aerospike.WritePolicy
+		ops      []*aerospike.Operation
+		wantCode types.ResultCode
+	}{
+		{
+			name:     "create only",
+			policy:   withPolicy(func(p *aerospike.WritePolicy) { p.RecordExistsAction = aerospike.CREATE_ONLY }),
+			ops:      []*aerospike.Operation{aerospike.PutOp(aerospike.NewBin("int", 1))},
+			wantCode: types.KEY_EXISTS_ERROR,
+		},
+		{
+			name: "generation mismatch",
+			policy: withPolicy(func(p *aerospike.WritePolicy) {
+				p.GenerationPolicy = aerospike.EXPECT_GEN_EQUAL
+				p.Generation = 2
+			}),
+			ops:      []*aerospike.Operation{aerospike.PutOp(aerospike.NewBin("int", 1))},
+			wantCode: types.GENERATION_ERROR,
+		},
+		{
+			name:     "add to string",
+			ops:      []*aerospike.Operation{aerospike.AddOp(aerospike.NewBin("str", 1))},
+			wantCode: types.BIN_TYPE_ERROR,
+		},
+		{
+			name:     "index out of range",
+			ops:      []*aerospike.Operation{aerospike.ListGetByIndexOp("list", 5, aerospike.ListReturnTypeValue)},
+			wantCode: types.OP_NOT_APPLICABLE,
+		},
+		{
+			name:     "unsupported operation",
+			ops:      []*aerospike.Operation{aerospike.ListAppendOp("list", 2)},
+			wantCode: types.UNSUPPORTED_FEATURE,
+		},
+		{
+			name:     "filter expression",
+			policy:   withPolicy(func(p *aerospike.WritePolicy) { p.FilterExpression = aerospike.ExpBoolVal(true) }),
+			ops:      []*aerospike.Operation{aerospike.GetOp()},
+			wantCode: types.UNSUPPORTED_FEATURE,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := client.Operate(tt.policy, key, tt.ops...)
+			requireResultCode(t, tt.wantCode, err)
+		})
+	}
+}
+
+func TestServerStore(t *testing.T) {
+	t.Parallel()
+
+	fake := NewFake()
+	_, client := newTestClient(t, WithFake(fake), WithNamespaces("test", "other"))
+	store, err := goaerospike.NewStore[user](goaerospike.WrapClient(client), "test", "users")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	v := user{ID: "1", Name: "John", Age: 30}
+	require.ErrorIs(t, store.Update(ctx, &v), goaerospike.ErrNotFound)
+	require.NoError(t, store.Create(ctx, &v))
+	require.ErrorIs(t, store.Create(ctx, &v), goaerospike.ErrAlreadyExists)
+	require.Equal(t, 1, fake.Len())
+
+	got, err := store.Get(ctx, "1")
+	require.NoError(t, err)
+	require.Equal(t, user{ID: "1", Gen: 1, Name: "John", Age: 30}, got)
+	got.Name = "Jane"
+	require.NoError(t, store.Update(ctx, &got))
+	got.Name = "stale"
+	require.ErrorIs(t, store.Update(ctx, &got), goaerospike.ErrConflict)
+
+	many, err := store.GetMany(ctx, []any{"1", "2", "1"})
+	require.NoError(t, err)
+	require.Len(t, many, 3)
+	require.Equal(t, "Jane", many[0].Name)
+	require.Nil(t, many[1])
+	require.Equal(t, "Jane", many[2].Name)
+
+	items := make([]item, 20)
+	for i := range items {
+		items[i] = item{ID: i, Name: strings.Repeat(fmt.Sprint(i), 100), Tags: []int{i}}
+	}
+	policy := aerospike.NewBatchPolicy()
+	policy.UseCompression = true
+	report, err := goaerospike.BatchPut(ctx, goaerospike.WrapClient(client), "other", "items", items,
+		goaerospike.BatchPutPolicy(policy))
+	require.NoError(t, err)
+	require.Empty(t, report.Failed())
+	require.Equal(t, 1+len(items), fake.Len())
+
+	key, err := aerospike.NewKey("other", "items", 7)
+	require.NoError(t, err)
+	record, err := fake.Get(nil, key)
+	require.NoError(t, err)
+	require.Equal(t, aerospike.BinMap{"name": items[7].Name, "tags": []any{7}}, record.Bins)
+
+	existed, err := store.Delete(ctx, "1")
+	require.NoError(t, err)
+	require.True(t, existed)
+}
+
+func TestServerBatch(t *testing.T) {
+	t.Parallel()
+
+	_, client := newTestClient(t)
+	keys := make([]*aerospike.Key, 3)
+	for i := range keys {
+		var err error
+		keys[i], err = aerospike.NewKey("test", "batch", i)
+		require.NoError(t, err)
+	}
+	require.NoError(t, client.Put(nil, keys[0], aerospike.BinMap{"a": 1, "b": 2}))
+	require.NoError(t, client.Put(nil, keys[1], aerospike.BinMap{"a": 3}))
+
+	records := []aerospike.BatchRecordIfc{
+		aerospike.NewBatchRead(nil, keys[0], []string{"a"}),
+		aerospike.NewBatchReadHeader(nil, keys[1]),
+		aerospike.NewBatchRead(nil, keys[2], nil),
+		aerospike.NewBatchWrite(nil, keys[2], aerospike.PutOp(aerospike.NewBin("c", 4))),
+		aerospike.NewBatchDelete(nil, keys[1]),
+	}
+	require.NoError(t, client.BatchOperate(nil, records))
+
+	results := make([]types.ResultCode, len(records))
+	for i, rec := range records {
+		results[i] = rec.BatchRec().ResultCode
+	}
+	require.Equal(t, []types.ResultCode{
+		types.OK, types.OK, types.KEY_NOT_FOUND_ERROR, types.OK, types.OK,
+	}, results)
+	require.Equal(t, aerospike.BinMap{"a": 1}, records[0].BatchRec().Record.Bins)
+	require.Equal(t, uint32(1), records[1].BatchRec().Record.Generation)
+
+	got, err := client.BatchGet(nil, keys)
+	require.NoError(t, err)
+	require.Equal(t, aerospike.BinMap{"a": 1, "b": 2}, got[0].Bins)
+	require.Nil(t, got[1])
+	require.Equal(t, aerospike.BinMap{"c": 4}, got[2].Bins)
+}
+
+func TestServerUnsupported(t *testing.T) {
+	t.Parallel()
+
+	server, client := newTestClient(t)
+	require.NoError(t, server.Fake().Put(nil, must(aerospike.NewKey("test", "set", 1)), aerospike.BinMap{"a": 1}))
+
+	rs, err := client.ScanAll(nil, "test", "set")
+	require.NoError(t, err)
+	var scanErr error
+	for result := range rs.Results() {
+		if result.Err != nil {
+			scanErr = result.Err
+		}
+	}
+	requireResultCode(t, types.UNSUPPORTED_FEATURE, scanErr)
+
+	policy := aerospike.NewPolicy()
+	policy.TotalTimeout = time.Second
+	_, err = client.Get(policy, must(aerospike.NewKey("missing", "set", 1)))
+	require.Error(t, err)
+}
+
+func must[T any](v T, err aerospike.Error) T {
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
@@ -0,0 +1,562 @@
+package aerospiketest
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/aerospike/aerospike-client-go/v8/types"
+	particle "github.com/aerospike/aerospike-client-go/v8/types/particle_type"
+)
+
+const (
+	// messageHeaderSize is the size of the message header following the protocol header.
+	messageHeaderSize = 22
+	// digestSize is the size of record digests.
+	digestSize = 20
+)
+
+// Message header flags.
+const (
+	info1GetAll          = 1 << 1
+	info1Batch           = 1 << 3
+	info2Write           = 1 << 0
+	info2Delete          = 1 << 1
+	info2Generation      = 1 << 2
+	info2GenerationGT    = 1 << 3
+	info2CreateOnly      = 1 << 5
+	info3Last            = 1 << 0
+	info3UpdateOnly      = 1 << 3
+	info3CreateOrReplace = 1 << 4
+	info3ReplaceOnly     = 1 << 5
+)
+
+// Operation codes of the wire protocol.
+const (
+	wireRead    = 1
+	wireWrite   = 2
+	wireCDTRead = 3
+	wireAdd     = 5
+	wireAppend  = 9
+	wirePrepend = 10
+	wireTouch   = 11
+	wireDelete  = 14
+)
+
+// Types of batch rows.
+const (
+	batchRead   = 0x0
+	batchRepeat = 0x1
+	batchInfo   = 0x2
+	batchGen    = 0x4
+	batchTTL    = 0x8
+	batchInfo4  = 0x10
+)
+
+// cdtContextMarker starts CDT operations with a context.
+const cdtContextMarker = 0xff
+
+// request is a single record command or a row of a batch command.
+type request struct {
+	info1, info2, info3 byte
+	generation          uint32
+	expiration          uint32
+	namespace           string
+	set                 string
+	digest              []byte
+	userKey             any
+	sendKey             bool
+	// batch is the BATCH_INDEX field of batch commands.
+	batch []byte
+	// unsupported is set by fields of features the server does not implement.
+	unsupported bool
+	ops         []wireOperation
+}
+
+type wireOperation struct {
+	op           byte
+	particleType byte
+	name         string
+	value        []byte
+}
+
+// wireReader reads big endian values, remembering whether the data ended prematurely.
+type wireReader struct {
+	buf       []byte
+	pos       int
+	truncated bool
+}
+
+func (r *wireReader) bytes(n int) []byte {
+	if r.truncated || n < 0 || r.pos+n > len(r.buf) {
+		r.truncated = true
+		return make([]byte, max(n, 0))
+	}
+	b := r.buf[r.pos : r.pos+n]
+	r.pos += n
+
+	return b
+}
+
+func (r *wireReader) uint8() byte {
+	return r.bytes(1)[0]
+}
+
+func (r *wireReader) uint16() uint16 {
+	return binary.BigEndian.Uint16(r.bytes(2))
+}
+
+func (r *wireReader) uint32() uint32 {
+	return binary.BigEndian.Uint32(r.bytes(4))
+}
+
+// message executes the command of the message and returns the response message.
+func (s *Server) message(body []byte) []byte {
+	r := &wireReader{buf: body}
+	if r.uint8() != messageHeaderSize {
+		return appendResult(nil, types.PARAMETER_ERROR, nil, 0, info3Last)
+	}
+	req := request{info1: r.uint8(), info2: r.uint8(), info3: r.uint8()}
+	r.uint8() // info4
+	r.uint8() // result code
+	req.generation = r.uint32()
+	req.expiration = r.uint32()
+	r.uint32() // timeout
+	readFieldsAndOps(r, &req, int(r.uint16()), int(r.uint16()))
+	if r.truncated {
+		return appendResult(nil, types.PARAMETER_ERROR, nil, 0, info3Last)
+	}
+
+	switch {
+	case req.info1&info1Batch != 0 && req.batch != nil && !req.unsupported:
+		return s.batch(req.batch)
+	case req.digest == nil || req.batch != nil:
+		return appendResult(nil, types.UNSUPPORTED_FEATURE, nil, 0, info3Last)
+	default:
+		code, record := s.execute(req)
+		return appendResult(nil, code, record, 0, 0)
+	}
+}
+
+func readFieldsAndOps(r *wireReader, req *request, fieldCount, opCount int) {
+	for range fieldCount {
+		size := int(r.uint32())
+		typ := r.uint8()
+		req.setField(aerospike.FieldType(typ), r.bytes(size-1))
+	}
+	for range opCount {
+		size := int(r.uint32())
+		op := wireOperation{op: r.uint8(), particleType: r.uint8()}
+		r.uint8()
+		nameLength := int(r.uint8())
+		op.name = string(r.bytes(nameLength))
+		op.value = r.bytes(size - 4 - nameLength)
+		req.ops = append(req.ops, op)
+	}
+}
+
+func (req *request) setField(typ aerospike.FieldType, data []byte) {
+	switch typ {
+	case aerospike.NAMESPACE:
+		req.namespace = string(data)
+	case aerospike.TABLE:
+		req.set = string(data)
+	case aerospike.DIGEST_RIPE:
+		req.digest = data
+	case aerospike.KEY:
+		if len(data) == 0 {
+			req.unsupported = true
+			return
+		}
+		key, err := particleValue(data[0], data[1:])
+		if err != nil {
+			req.unsupported = true
+			return
+		}
+		req.userKey = key
+		req.sendKey = true
+	case aerospike.BATCH_INDEX:
+		req.batch = data
+	default:
+		// Filter expressions, transactions, queries, UDFs and the legacy batch protocol.
+		req.unsupported = true
+	}
+}
+
+// batch executes rows of the BATCH_INDEX field and returns a response for each of them
+// followed by the last message.
+func (s *Server) batch(field []byte) []byte {
+	r := &wireReader{buf: field}
+	count := int(r.uint32())
+	r.uint8() // flags
+
+	var (
+		response []byte
+		prev     *request
+	)
+	for range count {
+		index := r.uint32()
+		digest := r.bytes(digestSize)
+		row := request{}
+		switch typ := r.uint8(); {
+		case typ == batchRepeat:
+			if prev == nil {
+				return appendResult(nil, types.PARAMETER_ERROR, nil, 0, info3Last)
+			}
+			row = *prev
+		case typ == batchRead:
+			row.info1 = r.uint8()
+			readFieldsAndOps(r, &row, int(r.uint16()), int(r.uint16()))
+		default:
+			if typ&batchInfo != 0 {
+				row.info1, row.info2, row.info3 = r.uint8(), r.uint8(), r.uint8()
+			}
+			if typ&batchInfo4 != 0 {
+				r.uint8()
+			}
+			if typ&batchGen != 0 {
+				row.generation = uint32(r.uint16())
+			}
+			if typ&batchTTL != 0 {
+				row.expiration = r.uint32()
+			}
+			readFieldsAndOps(r, &row, int(r.uint16()), int(r.uint16()))
+		}
+		if r.truncated {
+			return appendResult(nil, types.PARAMETER_ERROR, nil, 0, info3Last)
+		}
+
+		prev = &row
+		row.digest = digest
+		code, record := s.execute(row)
+		response = appendResult(response, code, record, index, 0)
+	}
+
+	return appendResult(response, types.OK, nil, 0, info3Last)
+}
+
+// execute applies the request to the record of the fake and returns the result code and the record.
+func (s *Server) execute(req request) (types.ResultCode, *aerospike.Record) {
+	if req.unsupported || req.batch != nil {
+		return types.UNSUPPORTED_FEATURE, nil
+	}
+	if !slices.Contains(s.namespaces, req.namespace) {
+		return types.INVALID_NAMESPACE, nil
+	}
+	key, err := aerospike.NewKeyWithDigest(req.namespace, req.set, req.userKey, req.digest)
+	if err != nil {
+		return types.PARAMETER_ERROR, nil
+	}
+	params := req.writeParams()
+
+	s.fake.mu.Lock()
+	defer s.fake.mu.Unlock()
+
+	if req.info2&info2Delete != 0 {
+		existed, err := s.fake.delete(key, params)
+		switch {
+		case err != nil:
+			return resultCode(err), nil
+		case !existed:
+			return types.KEY_NOT_FOUND_ERROR, nil
+		default:
+			return types.OK, &aerospike.Record{Key: key}
+		}
+	}
+
+	ops, err := req.operations()
+	if err != nil {
+		return resultCode(err), nil
+	}
+	record, err := s.fake.execute(key, params, ops)
+	if err != nil {
+		return resultCode(err), nil
+	}
+
+	return types.OK, record
+}
+
+func (req request) writeParams() writeParams {
+	params := writeParams{
+		generation: req.generation,
+		expiration: req.expiration,
+		sendKey:    req.sendKey,
+	}
+
+	switch {
+	case req.info2&info2CreateOnly != 0:
+		params.action = aerospike.CREATE_ONLY
+	case req.info3&info3UpdateOnly != 0:
+		params.action = aerospike.UPDATE_ONLY
+	case req.info3&info3CreateOrReplace != 0:
+		params.action = aerospike.REPLACE
+	case req.info3&info3ReplaceOnly != 0:
+		params.action = aerospike.REPLACE_ONLY
+	default:
+		params.action = aerospike.UPDATE
+	}
+
+	switch {
+	case req.info2&info2Generation != 0:
+		params.generationPolicy = aerospike.EXPECT_GEN_EQUAL
+	case req.info2&info2GenerationGT != 0:
+		params.generationPolicy = aerospike.EXPECT_GEN_GT
+	default:
+		params.generationPolicy = aerospike.NONE
+	}
+
+	return params
+}
+
+// operations decodes operations of the request. Commands without operations read all bins
+// or the header.
+func (req request) operations() ([]operation, aerospike.Error) {
+	if len(req.ops) == 0 {
+		switch {
+		case req.info2&info2Write != 0:
+			return nil, newError(types.PARAMETER_ERROR)
+		case req.info1&info1GetAll != 0:
+			return []operation{{kind: opReadAll}}, nil
+		default:
+			return []operation{{kind: opReadHeader}}, nil
+		}
+	}
+
+	ops := make([]operation, len(req.ops))
+	for i, op := range req.ops {
+		var err aerospike.Error
+		if ops[i], err = op.decode(req.info1); err != nil {
+			return nil, err
+		}
+	}
+
+	return ops, nil
+}
+
+func (o wireOperation) decode(info1 byte) (operation, aerospike.Error) {
+	switch o.op {
+	case wireRead:
+		switch {
+		case o.name != "":
+			return operation{kind: opRead, bin: o.name}, nil
+		case info1&info1GetAll != 0:
+			return operation{kind: opReadAll}, nil
+		default:
+			return operation{kind: opReadHeader}, nil
+		}
+	case wireWrite, wireAdd, wireAppend, wirePrepend:
+		value, err := particleValue(o.particleType, o.value)
+		if err != nil {
+			return operation{}, err
+		}
+		kind := map[byte]operationKind{
+			wireWrite: opWrite, wireAdd: opAdd, wireAppend: opAppend, wirePrepend: opPrepend,
+		}[o.op]
+		return operation{kind: kind, bin: o.name, value: value}, nil
+	case wireTouch:
+		return operation{kind: opTouch}, nil
+	case wireDelete:
+		return operation{kind: opDelete}, nil
+	case wireCDTRead:
+		return o.decodeCDT()
+	default:
+		return operation{}, unsupported("operation")
+	}
+}
+
+// decodeCDT decodes [command, params...] or [cdtContextMarker, [id, value...], [command, params...]].
+func (o wireOperation) decodeCDT() (operation, aerospike.Error) {
+	v, err := unpackValue(o.value)
+	if err != nil {
+		return operation{}, newError(types.PARAMETER_ERROR)
+	}
+	args, _ := v.([]any)
+
+	var ctx []*aerospike.CDTContext
+	if len(args) == 3 && args[0] == cdtContextMarker {
+		pairs, _ := args[1].([]any)
+		if len(pairs)%2 != 0 {
+			return operation{}, newError(types.PARAMETER_ERROR)
+		}
+		for i := 0; i < len(pairs); i += 2 {
+			id, ok := pairs[i].(int)
+			if !ok {
+				return operation{}, newError(types.PARAMETER_ERROR)
+			}
+			ctx = append(ctx, &aerospike.CDTContext{Id: id, Value: aerospike.NewValue(pairs[i+1])})
+		}
+		args, _ = args[2].([]any)
+	}
+
+	op := operation{bin: o.name, ctx: ctx}
+	switch {
+	case len(args) != 3:
+		return operation{}, unsupported("cdt operation")
+	case args[0] == cdtListGetByIndex && args[1] == int(aerospike.ListReturnTypeValue):
+		index, ok := args[2].(int)
+		if !ok {
+			return operation{}, newError(types.PARAMETER_ERROR)
+		}
+		op.kind = opListGetByIndex
+		op.value = index
+	case args[0] == cdtMapGetByKey && args[1] == int(aerospike.MapReturnType.VALUE):
+		op.kind = opMapGetByKey
+		op.value = args[2]
+	default:
+		return operation{}, unsupported("cdt operation")
+	}
+
+	return op, nil
+}
+
+// particleValue decodes the value of a bin or a key in the client representation.
+func particleValue(typ byte, data []byte) (any, aerospike.Error) {
+	switch typ {
+	case particle.NULL:
+		return nil, nil
+	case particle.INTEGER:
+		if len(data) != 8 {
+			return nil, newError(types.PARAMETER_ERROR)
+		}
+		return int(int64(binary.BigEndian.Uint64(data))), nil //nolint:gosec
+	case particle.FLOAT:
+		if len(data) != 8 {
+			return nil, newError(types.PARAMETER_ERROR)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+	case particle.STRING:
+		return string(data), nil
+	case particle.BLOB:
+		return append([]byte{}, data...), nil
+	case particle.BOOL:
+		return len(data) > 0 && data[0] != 0, nil
+	case particle.GEOJSON:
+		// Flags and the number of cells precede the JSON.
+		if len(data) < 3 {
+			return nil, newError(types.PARAMETER_ERROR)
+		}
+		header := 3 + 8*int(binary.BigEndian.Uint16(data[1:3]))
+		if len(data) < header {
+			return nil, newError(types.PARAMETER_ERROR)
+		}
+		return aerospike.GeoJSONValue(data[header:]), nil
+	case particle.LIST, particle.MAP:
+		v, err := unpackValue(data)
+		if err != nil {
+			return nil, newError(types.PARAMETER_ERROR)
+		}
+		return v, nil
+	default:
+		return nil, unsupported("particle type")
+	}
+}
+
+// appendParticle appends the value in the client representation encoded as a particle,
+// returning its particle type.
+func appendParticle(buf []byte, value any) ([]byte, byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return buf, particle.NULL, nil
+	case int:
+		return binary.BigEndian.AppendUint64(buf, uint64(v)), particle.INTEGER, nil //nolint:gosec
+	case float64:
+		return binary.BigEndian.AppendUint64(buf, math.Float64bits(v)), particle.FLOAT, nil
+	case string:
+		return append(buf, v...), particle.STRING, nil
+	case []byte:
+		return append(buf, v...), particle.BLOB, nil
+	case bool:
+		if v {
+			return append(buf, 1), particle.BOOL, nil
+		}
+		return append(buf, 0), particle.BOOL, nil
+	case aerospike.GeoJSONValue:
+		return append(append(buf, 0, 0, 0), v...), particle.GEOJSON, nil
+	case []any:
+		buf, err := packValue(buf, v)
+		return buf, particle.LIST, err
+	case map[any]any:
+		buf, err := packValue(buf, v)
+		return buf, particle.MAP, err
+	default:
+		return nil, 0, errMsgpack
+	}
+}
+
+// appendResult appends the response message for the record of a single record command
+// or a batch row. Records of failed commands are nil.
+func appendResult(buf []byte, code types.ResultCode, record *aerospike.Record, index uint32, info3 byte) []byte {
+	var ops []byte
+	opCount := 0
+	if record != nil {
+		names := make([]string, 0, len(record.Bins))
+		for name := range record.Bins {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		for _, name := range names {
+			values := []any{record.Bins[name]}
+			if results, ok := record.Bins[name].(aerospike.OpResults); ok {
+				values = results
+			}
+			for _, value := range values {
+				var err error
+				if ops, err = appendOperation(ops, name, value); err != nil {
+					return appendResult(buf, types.SERVER_ERROR, nil, index, info3)
+				}
+				opCount++
+			}
+		}
+	}
+
+	var generation, voidTime uint32
+	if record != nil {
+		generation = record.Generation
+		voidTime = voidTimeOf(record.Expiration)
+	}
+
+	buf = append(buf, messageHeaderSize, 0, 0, info3, 0, byte(code))
+	buf = binary.BigEndian.AppendUint32(buf, generation)
+	buf = binary.BigEndian.AppendUint32(buf, voidTime)
+	buf = binary.BigEndian.AppendUint32(buf, index)
+	buf = binary.BigEndian.AppendUint16(buf, 0)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(opCount)) //nolint:gosec
+
+	return append(buf, ops...)
+}
+
+func appendOperation(buf []byte, name string, value any) ([]byte, error) {
+	particleBuf, particleType, err := appendParticle(nil, value)
+	if err != nil {
+		return nil, err
+	}
+
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(name)+len(particleBuf)+4)) //nolint:gosec
+	buf = append(buf, wireRead, particleType, 0, byte(len(name)))
+	buf = append(buf, name...)
+
+	return append(buf, particleBuf...), nil
+}
+
+// voidTimeOf converts the remaining TTL of a record into the expiration time in seconds since
+// the citrusleaf epoch the server sends, 0 for records that never expire.
+func voidTimeOf(ttl uint32) uint32 {
+	if ttl == math.MaxUint32 {
+		return 0
+	}
+
+	return uint32(time.Now().Unix()-types.CITRUSLEAF_EPOCH) + ttl //nolint:gosec
+}
+
+func resultCode(err aerospike.Error) types.ResultCode {
+	var aerr *aerospike.AerospikeError
+	if !errors.As(err, &aerr) {
+		return types.SERVER_ERROR
+	}
+
+	return aerr.ResultCode
+}
@@ -0,0 +1,270 @@
+// Package asgen supports code generated by the asgen command, which implements
+// MarshalAerospike and UnmarshalAerospike without reflection. The helpers convert values
+// the same way the default codec does and are not meant to be called directly.
+package asgen
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"golang.org/x/exp/constraints"
+)
+
+var errInputType = errors.New("wrong variable provided")
+
+// EncodeTime converts t into Unix seconds, the zero time into 0.
+func EncodeTime(t time.Time) any {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.Unix()
+}
+
+// IsZeroTime reports whether t is the zero value of time.Time, as omitempty checks it.
+func IsZeroTime(t time.Time) bool {
+	return t == time.Time{}
+}
+
+// Bins converts a nested struct value into bins.
+func Bins(v any) (aerospike.BinMap, error) {
+	switch m := v.(type) {
+	case map[string]any:
+		return m, nil
+	case aerospike.BinMap:
+		return m, nil
+	case map[any]any:
+		bins := make(aerospike.BinMap, len(m))
+		for key, value := range m {
+			if name, ok := key.(string); ok {
+				bins[name] = value
+			}
+		}
+		return bins, nil
+	default:
+		return nil, errInputType
+	}
+}
+
+// SetInt sets dst from an integer value, 0 for values of other types.
+func SetInt[T constraints.Integer](dst *T, v any) {
+	*dst, _ = DecodeInt[T](v)
+}
+
+// SetFloat sets dst from a float value, 0 for values of other types.
+func SetFloat[T constraints.Float](dst *T, v any) {
+	*dst, _ = DecodeFloat[T](v)
+}
+
+// SetString sets dst from a string value, leaving it unchanged for nil values, see ConvertString.
+func SetString[T ~string](dst *T, v any) error {
+	if v == nil {
+		return nil
+	}
+
+	s, err := ConvertString[T](v)
+	if err != nil {
+		return err
+	}
+	*dst = s
+
+	return nil
+}
+
+// SetBool sets dst from a bool value, leaving it unchanged for nil values.
+func SetBool[T ~bool](dst *T, v any) error {
+	if v == nil {
+		return nil
+	}
+
+	b, err := DecodeBool[T](v)
+	if err != nil {
+		return err
+	}
+	*dst = b
+
+	return nil
+}
+
+// SetTime sets dst from Unix seconds, the zero time for nil values.
+func SetTime(dst *time.Time, v any) error {
+	if v == nil {
+		*dst = time.Time{}
+		return nil
+	}
+
+	timestamp, ok := v.(int)
+	if !ok {
+		return fmt.Errorf("time must be an int: %w", errInputType)
+	}
+	*dst = time.Unix(int64(timestamp), 0).UTC()
+
+	return nil
+}
+
+// SetIntPtr points dst to an integer value, 0 for values of other types.
+func SetIntPtr[T constraints.Integer](dst **T, v any) {
+	i, _ := DecodeInt[T](v)
+	*dst = &i
+}
+
+// SetFloatPtr points dst to a float value, 0 for values of other types.
+func SetFloatPtr[T constraints.Float](dst **T, v any) {
+	f, _ := DecodeFloat[T](v)
+	*dst = &f
+}
+
+// SetStringPtr points dst to a string value, leaving it unchanged for nil values.
+func SetStringPtr[T ~string](dst **T, v any) error {
+	return setPtr(dst, v, SetString[T])
+}
+
+// SetBoolPtr points dst to a bool value, leaving it unchanged for nil values.
+func SetBoolPtr[T ~bool](dst **T, v any) error {
+	return setPtr(dst, v, SetBool[T])
+}
+
+// SetTimePtr points dst to the time of Unix seconds, the zero time for nil values.
+func SetTimePtr(dst **time.Time, v any) error {
+	var t time.Time
+	if err := SetTime(&t, v); err != nil {
+		return err
+	}
+	*dst = &t
+
+	return nil
+}
+
+func setPtr[T any](dst **T, v any, set func(*T, any) error) error {
+	if v == nil {
+		return nil
+	}
+
+	var value T
+	if err := set(&value, v); err != nil {
+		return err
+	}
+	*dst = &value
+
+	return nil
+}
+
+// SetSlice sets dst from a list converting its elements, leaving it unchanged for nil values.
+func SetSlice[S ~[]E, E any](dst *S, v any, convert func(any) (E, error)) error {
+	if v == nil {
+		return nil
+	}
+
+	list, ok := v.([]any)
+	if !ok {
+		return fmt.Errorf("cannot convert %T to %T: %w", v, *dst, errInputType)
+	}
+	out := make(S, len(list))
+	for i, elem := range list {
+		var err error
+		if out[i], err = convert(elem); err != nil {
+			return err
+		}
+	}
+	*dst = out
+
+	return nil
+}
+
+// SetMap sets dst from a map decoding its keys and values, leaving it unchanged for nil values.
+func SetMap[M ~map[K]V, K comparable, V any](dst *M, v any, key func(any) (K, error), value func(any) (V, error)) error {
+	if v == nil {
+		return nil
+	}
+
+	m, ok := v.(map[any]any)
+	if !ok {
+		return fmt.Errorf("cannot convert %T to %T: %w", v, *dst, errInputType)
+	}
+	out := make(M, len(m))
+	for rawKey, rawValue := range m {
+		k, err := key(rawKey)
+		if err != nil {
+			return err
+		}
+		if out[k], err = value(rawValue); err != nil {
+			return err
+		}
+	}
+	*dst = out
+
+	return nil
+}
+
+// ConvertInt converts a list element into an integer.
+func ConvertInt[T constraints.Integer](v any) (T, error) {
+	switch n := v.(type) {
+	case int:
+		return T(n), nil
+	case float64:
+		return T(n), nil
+	default:
+		return 0, fmt.Errorf("cannot convert %T to %T: %w", v, T(0), errInputType)
+	}
+}
+
+// ConvertFloat converts a list element into a float.
+func ConvertFloat[T constraints.Float](v any) (T, error) {
+	switch n := v.(type) {
+	case int:
+		return T(n), nil
+	case float64:
+		return T(n), nil
+	default:
+		return 0, fmt.Errorf("cannot convert %T to %T: %w", v, T(0), errInputType)
+	}
+}
+
+// ConvertString converts a list element into a string, integers the way Go converts them
+// into strings of the runes they encode.
+func ConvertString[T ~string](v any) (T, error) {
+	if i, ok := v.(int); ok {
+		return T(rune(i)), nil //nolint:gosec
+	}
+
+	return DecodeString[T](v)
+}
+
+// ConvertBool converts a list element into a bool.
+func ConvertBool[T ~bool](v any) (T, error) {
+	return DecodeBool[T](v)
+}
+
+// DecodeInt decodes a map key or value into an integer, 0 for values of other types.
+func DecodeInt[T constraints.Integer](v any) (T, error) {
+	i, _ := v.(int)
+	return T(i), nil
+}
+
+// DecodeFloat decodes a map key or value into a float, 0 for values of other types.
+func DecodeFloat[T constraints.Float](v any) (T, error) {
+	f, _ := v.(float64)
+	return T(f), nil
+}
+
+// DecodeString decodes a map key or value into a string.
+func DecodeString[T ~string](v any) (T, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("cannot convert %T to %T: %w", v, T(""), errInputType)
+	}
+
+	return T(s), nil
+}
+
+// DecodeBool decodes a map key or value into a bool.
+func DecodeBool[T ~bool](v any) (T, error) {
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("cannot convert %T to %T: %w", v, T(false), errInputType)
+	}
+
+	return T(b), nil
+}
@@ -0,0 +1,40 @@
+// Package example holds structs with code generated by asgen, to test it against the reflective codec.
+package example
+
+import "time"
+
+//go:generate go run ../../../cmd/asgen -type Record
+
+// Level is a named integer.
+type Level int
+
+// Address is a nested struct.
+type Address struct {
+	City string `as:"city"`
+	Zip  uint16 `as:"zip,omitempty"`
+}
+
+// Record has fields of every type asgen supports.
+type Record struct {
+	ID      string          `as:",key"`
+	Name    string          `as:"name"`
+	Nick    string          `as:"nick,omitempty"`
+	Active  bool            `as:"active"`
+	Age     int8            `as:"age"`
+	Level   Level           `as:"level"`
+	Count   uint64          `as:"count"`
+	Score   float32         `as:"score"`
+	Ratio   float64         `as:"ratio"`
+	At      time.Time       `as:"at"`
+	Seen    time.Time       `as:"seen,omitempty"`
+	Ref     *int            `as:"ref"`
+	Note    *string         `as:"note"`
+	Expires *time.Time      `as:"expires"`
+	Tags    []string        `as:"tags"`
+	Data    []byte          `as:"data"`
+	Counts  map[string]int  `as:"counts"`
+	Flags   map[uint32]bool `as:"flags"`
+	Address Address         `as:"address"`
+	Home    Address         `as:"home,omitempty"`
+	Skipped string
+}
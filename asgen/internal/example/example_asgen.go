@@ -0,0 +1,210 @@
+// Code generated by asgen. DO NOT EDIT.
+
+package example
+
+import (
+	"fmt"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/viru-tech/go.aerospike/asgen"
+)
+
+// MarshalAerospike converts v into bins the way aerospike.Marshal does.
+func (v *Record) MarshalAerospike() (aerospike.BinMap, error) {
+	bins := make(aerospike.BinMap, 19)
+	bins["name"] = v.Name
+	if v.Nick != "" {
+		bins["nick"] = v.Nick
+	}
+	bins["active"] = v.Active
+	bins["age"] = int64(v.Age)
+	bins["level"] = int64(v.Level)
+	bins["count"] = int64(v.Count)
+	bins["score"] = float64(v.Score)
+	bins["ratio"] = v.Ratio
+	bins["at"] = asgen.EncodeTime(v.At)
+	if !asgen.IsZeroTime(v.Seen) {
+		bins["seen"] = asgen.EncodeTime(v.Seen)
+	}
+	if v.Ref == nil {
+		bins["ref"] = nil
+	} else {
+		bins["ref"] = int64(*v.Ref)
+	}
+	if v.Note == nil {
+		bins["note"] = nil
+	} else {
+		bins["note"] = *v.Note
+	}
+	if v.Expires == nil {
+		bins["expires"] = nil
+	} else {
+		bins["expires"] = asgen.EncodeTime(*v.Expires)
+	}
+	{
+		list := make([]any, len(v.Tags))
+		for i, elem := range v.Tags {
+			list[i] = elem
+		}
+		bins["tags"] = list
+	}
+	{
+		list := make([]any, len(v.Data))
+		for i, elem := range v.Data {
+			list[i] = int64(elem)
+		}
+		bins["data"] = list
+	}
+	{
+		m := make(map[any]any, len(v.Counts))
+		for key, elem := range v.Counts {
+			m[key] = int64(elem)
+		}
+		bins["counts"] = m
+	}
+	{
+		m := make(map[any]any, len(v.Flags))
+		for key, elem := range v.Flags {
+			m[uint64(key)] = elem
+		}
+		bins["flags"] = m
+	}
+	{
+		nested, err := v.Address.MarshalAerospike()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert field Address: %w", err)
+		}
+		bins["address"] = map[string]any(nested)
+	}
+	if v.Home != (Address{}) {
+		nested, err := v.Home.MarshalAerospike()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert field Home: %w", err)
+		}
+		bins["home"] = map[string]any(nested)
+	}
+
+	return bins, nil
+}
+
+// UnmarshalAerospike sets fields of v from bins the way aerospike.Unmarshal does.
+func (v *Record) UnmarshalAerospike(bins aerospike.BinMap) error {
+	if val, ok := bins["name"]; ok {
+		if err := asgen.SetString(&v.Name, val); err != nil {
+			return fmt.Errorf("error while parsing field Name: %w", err)
+		}
+	}
+	if val, ok := bins["nick"]; ok {
+		if err := asgen.SetString(&v.Nick, val); err != nil {
+			return fmt.Errorf("error while parsing field Nick: %w", err)
+		}
+	}
+	if val, ok := bins["active"]; ok {
+		if err := asgen.SetBool(&v.Active, val); err != nil {
+			return fmt.Errorf("error while parsing field Active: %w", err)
+		}
+	}
+	if val, ok := bins["age"]; ok {
+		asgen.SetInt(&v.Age, val)
+	}
+	if val, ok := bins["level"]; ok {
+		asgen.SetInt(&v.Level, val)
+	}
+	if val, ok := bins["count"]; ok {
+		asgen.SetInt(&v.Count, val)
+	}
+	if val, ok := bins["score"]; ok {
+		asgen.SetFloat(&v.Score, val)
+	}
+	if val, ok := bins["ratio"]; ok {
+		asgen.SetFloat(&v.Ratio, val)
+	}
+	if val, ok := bins["at"]; ok {
+		if err := asgen.SetTime(&v.At, val); err != nil {
+			return fmt.Errorf("error while parsing field At: %w", err)
+		}
+	}
+	if val, ok := bins["seen"]; ok {
+		if err := asgen.SetTime(&v.Seen, val); err != nil {
+			return fmt.Errorf("error while parsing field Seen: %w", err)
+		}
+	}
+	if val, ok := bins["ref"]; ok {
+		asgen.SetIntPtr(&v.Ref, val)
+	}
+	if val, ok := bins["note"]; ok {
+		if err := asgen.SetStringPtr(&v.Note, val); err != nil {
+			return fmt.Errorf("error while parsing field Note: %w", err)
+		}
+	}
+	if val, ok := bins["expires"]; ok {
+		if err := asgen.SetTimePtr(&v.Expires, val); err != nil {
+			return fmt.Errorf("error while parsing field Expires: %w", err)
+		}
+	}
+	if val, ok := bins["tags"]; ok {
+		if err := asgen.SetSlice(&v.Tags, val, asgen.ConvertString); err != nil {
+			return fmt.Errorf("error while parsing field Tags: %w", err)
+		}
+	}
+	if val, ok := bins["data"]; ok {
+		if err := asgen.SetSlice(&v.Data, val, asgen.ConvertInt); err != nil {
+			return fmt.Errorf("error while parsing field Data: %w", err)
+		}
+	}
+	if val, ok := bins["counts"]; ok {
+		if err := asgen.SetMap(&v.Counts, val, asgen.DecodeString, asgen.DecodeInt); err != nil {
+			return fmt.Errorf("error while parsing field Counts: %w", err)
+		}
+	}
+	if val, ok := bins["flags"]; ok {
+		if err := asgen.SetMap(&v.Flags, val, asgen.DecodeInt, asgen.DecodeBool); err != nil {
+			return fmt.Errorf("error while parsing field Flags: %w", err)
+		}
+	}
+	if val, ok := bins["address"]; ok {
+		nested, err := asgen.Bins(val)
+		if err == nil {
+			err = v.Address.UnmarshalAerospike(nested)
+		}
+		if err != nil {
+			return fmt.Errorf("error while parsing field Address: %w", err)
+		}
+	}
+	if val, ok := bins["home"]; ok {
+		nested, err := asgen.Bins(val)
+		if err == nil {
+			err = v.Home.UnmarshalAerospike(nested)
+		}
+		if err != nil {
+			return fmt.Errorf("error while parsing field Home: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// MarshalAerospike converts v into bins the way aerospike.Marshal does.
+func (v *Address) MarshalAerospike() (aerospike.BinMap, error) {
+	bins := make(aerospike.BinMap, 2)
+	bins["city"] = v.City
+	if v.Zip != 0 {
+		bins["zip"] = int64(v.Zip)
+	}
+
+	return bins, nil
+}
+
+// UnmarshalAerospike sets fields of v from bins the way aerospike.Unmarshal does.
+func (v *Address) UnmarshalAerospike(bins aerospike.BinMap) error {
+	if val, ok := bins["city"]; ok {
+		if err := asgen.SetString(&v.City, val); err != nil {
+			return fmt.Errorf("error while parsing field City: %w", err)
+		}
+	}
+	if val, ok := bins["zip"]; ok {
+		asgen.SetInt(&v.Zip, val)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package example
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/stretchr/testify/require"
+
+	goaerospike "github.com/viru-tech/go.aerospike"
+)
+
+func TestGenerated(t *testing.T) {
+	t.Parallel()
+
+	ref, note, expires := 7, "note", time.Unix(1700000000, 0).UTC()
+	tests := []struct {
+		name string
+		v    Record
+	}{
+		{
+			name: "zero",
+		},
+		{
+			name: "all fields",
+			v: Record{
+				Name: "name", Nick: "nick", Active: true, Age: -8, Level: 3, Count: 1<<64 - 1, Score: 1.5, Ratio: -0.25,
+				At: time.Unix(1600000000, 0).UTC(), Seen: time.Unix(1650000000, 0).UTC(),
+				Ref: &ref, Note: &note, Expires: &expires,
+				Tags: []string{"a", "b"}, Data: []byte{1, 255}, Counts: map[string]int{"a": 1},
+				Flags:   map[uint32]bool{4_000_000_000: true},
+				Address: Address{City: "city", Zip: 12345}, Home: Address{City: "home"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reflective := goaerospike.NewCodec()
+			want, err := reflective.Marshal(&tt.v)
+			require.NoError(t, err)
+			got, err := tt.v.MarshalAerospike()
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+
+			dispatched, err := goaerospike.Marshal(&tt.v)
+			require.NoError(t, err)
+			require.Equal(t, want, dispatched)
+
+			record := &aerospike.Record{Bins: goaerospike.Normalize(want)}
+			var wantValue, gotValue Record
+			require.NoError(t, reflective.Unmarshal(record, &wantValue))
+			require.NoError(t, goaerospike.Unmarshal(record, &gotValue))
+			require.Equal(t, wantValue, gotValue)
+		})
+	}
+}
+
+func TestGeneratedErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, bins := range []aerospike.BinMap{
+		{"name": 1.5},
+		{"active": "yes"},
+		{"at": "2024"},
+		{"tags": []any{1.5}},
+		{"address": nil},
+	} {
+		var want, got Record
+		require.Error(t, goaerospike.NewCodec().Unmarshal(&aerospike.Record{Bins: bins}, &want), "%v", bins)
+		require.Error(t, goaerospike.Unmarshal(&aerospike.Record{Bins: bins}, &got), "%v", bins)
+	}
+}
@@ -0,0 +1,150 @@
+// Package astypes registers conversions of standard library and common third-party types
+// with goaerospike.RegisterType. Registration is opt-in, call Register before marshaling:
+//
+//	func init() {
+//		astypes.Register()
+//	}
+package astypes
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	goaerospike "github.com/viru-tech/go.aerospike"
+)
+
+var errValueType = errors.New("unexpected value type")
+
+var once sync.Once
+
+// Register registers conversions of the types below, all stored as strings:
+//   - uuid.UUID in the canonical form, a 16 byte blob is decoded as well;
+//   - netip.Addr and netip.Prefix in their text forms, zero values are not stored;
+//   - *big.Int in decimal, integers are decoded as well;
+//   - *url.URL as the URL string;
+//   - *time.Location by name, e.g. "Europe/Berlin", loaded with time.LoadLocation.
+//
+// Nil pointers are not stored. Calling Register again has no effect.
+func Register() {
+	once.Do(func() {
+		goaerospike.RegisterType(encodeUUID, decodeUUID)
+		goaerospike.RegisterType(encodeAddr, decodeAddr)
+		goaerospike.RegisterType(encodePrefix, decodePrefix)
+		goaerospike.RegisterType(encodeBigInt, decodeBigInt)
+		goaerospike.RegisterType(encodeURL, decodeURL)
+		goaerospike.RegisterType(encodeLocation, decodeLocation)
+	})
+}
+
+func encodeUUID(v uuid.UUID) (any, error) {
+	return v.String(), nil
+}
+
+func decodeUUID(v any) (uuid.UUID, error) {
+	switch val := v.(type) {
+	case string:
+		return uuid.Parse(val)
+	case []byte:
+		return uuid.FromBytes(val)
+	default:
+		return uuid.UUID{}, fmt.Errorf("uuid from %T: %w", v, errValueType)
+	}
+}
+
+func encodeAddr(v netip.Addr) (any, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	return v.String(), nil
+}
+
+func decodeAddr(v any) (netip.Addr, error) {
+	s, ok := v.(string)
+	if !ok {
+		return netip.Addr{}, fmt.Errorf("address from %T: %w", v, errValueType)
+	}
+
+	return netip.ParseAddr(s)
+}
+
+func encodePrefix(v netip.Prefix) (any, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	return v.String(), nil
+}
+
+func decodePrefix(v any) (netip.Prefix, error) {
+	s, ok := v.(string)
+	if !ok {
+		return netip.Prefix{}, fmt.Errorf("prefix from %T: %w", v, errValueType)
+	}
+
+	return netip.ParsePrefix(s)
+}
+
+func encodeBigInt(v *big.Int) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	return v.String(), nil
+}
+
+func decodeBigInt(v any) (*big.Int, error) {
+	switch val := v.(type) {
+	case int:
+		return big.NewInt(int64(val)), nil
+	case string:
+		i, ok := new(big.Int).SetString(val, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q: %w", val, errValueType)
+		}
+		return i, nil
+	default:
+		return nil, fmt.Errorf("integer from %T: %w", v, errValueType)
+	}
+}
+
+func encodeURL(v *url.URL) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	return v.String(), nil
+}
+
+func decodeURL(v any) (*url.URL, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("url from %T: %w", v, errValueType)
+	}
+
+	return url.Parse(s)
+}
+
+func encodeLocation(v *time.Location) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	return v.String(), nil
+}
+
+func decodeLocation(v any) (*time.Location, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("location from %T: %w", v, errValueType)
+	}
+
+	return time.LoadLocation(s)
+}
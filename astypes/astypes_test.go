@@ -0,0 +1,73 @@
+package astypes
+
+import (
+	"math/big"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	goaerospike "github.com/viru-tech/go.aerospike"
+	"github.com/viru-tech/go.aerospike/aerospiketest"
+)
+
+type host struct {
+	ID       uuid.UUID            `as:"id"`
+	Addr     netip.Addr           `as:"addr"`
+	Network  netip.Prefix         `as:"network"`
+	Balance  *big.Int             `as:"balance"`
+	Endpoint *url.URL             `as:"endpoint"`
+	Location *time.Location       `as:"location"`
+	Aliases  []netip.Addr         `as:"aliases"`
+	Owners   map[string]uuid.UUID `as:"owners"`
+}
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+	Register()
+	Register()
+
+	balance, ok := new(big.Int).SetString("123456789012345678901234567890", 10)
+	require.True(t, ok)
+	endpoint, err := url.Parse("https://example.com/path?q=1")
+	require.NoError(t, err)
+	v := &host{
+		ID:       uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8"),
+		Addr:     netip.MustParseAddr("10.0.0.1"),
+		Network:  netip.MustParsePrefix("10.0.0.0/8"),
+		Balance:  balance,
+		Endpoint: endpoint,
+		Location: time.UTC,
+		Aliases:  []netip.Addr{netip.MustParseAddr("::1")},
+		Owners:   map[string]uuid.UUID{"a": uuid.MustParse("6ba7b811-9dad-11d1-80b4-00c04fd430c8")},
+	}
+
+	bins, err := goaerospike.Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, aerospike.BinMap{
+		"id":       "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"addr":     "10.0.0.1",
+		"network":  "10.0.0.0/8",
+		"balance":  "123456789012345678901234567890",
+		"endpoint": "https://example.com/path?q=1",
+		"location": "UTC",
+		"aliases":  []any{"::1"},
+		"owners":   map[any]any{"a": "6ba7b811-9dad-11d1-80b4-00c04fd430c8"},
+	}, bins)
+	aerospiketest.RoundTrip(t, v)
+
+	var got host
+	require.NoError(t, goaerospike.Unmarshal(&aerospike.Record{Bins: aerospike.BinMap{
+		"id":      v.ID[:],
+		"balance": 42,
+		"addr":    nil,
+	}}, &got))
+	require.Equal(t, host{ID: v.ID, Balance: big.NewInt(42)}, got)
+
+	err = goaerospike.Unmarshal(&aerospike.Record{Bins: aerospike.BinMap{"addr": 1}}, &got)
+	require.ErrorIs(t, err, errValueType)
+}
@@ -0,0 +1,242 @@
+// Package asvet provides an analyzer reporting problems with "as" struct tags,
+// which the library otherwise finds at runtime. Run it with the asvet command, or with
+// go vet -vettool=$(which asvet).
+package asvet
+
+import (
+	"go/ast"
+	"go/types"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// maxBinNameLength is the longest bin name in bytes the server accepts.
+const maxBinNameLength = 15
+
+// flagOptions are the tag options without values, valueOptions the ones with values,
+// mirroring the options the library parses.
+var (
+	flagOptions  = []string{"omitempty", "key", "generation", "ttl"}
+	valueOptions = map[string][]string{
+		"index":      {"numeric", "string", "blob", "geo2dsphere"},
+		"collection": {"", "default", "list", "mapkeys", "mapvalues"},
+	}
+)
+
+// Analyzer reports malformed "as" tags, bin names longer than 15 bytes, duplicate bin names
+// in a struct, tags on unexported fields and tagged fields of types the library cannot store.
+var Analyzer = &analysis.Analyzer{
+	Name:     "asvet",
+	Doc:      "check aerospike struct tags",
+	URL:      "https://pkg.go.dev/github.com/viru-tech/go.aerospike/asvet",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var tagName = "as"
+
+func init() {
+	Analyzer.Flags.StringVar(&tagName, "tag", tagName, "struct tag holding bin names")
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector) //nolint:forcetypeassert
+	insp.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(node ast.Node) {
+		checkStruct(pass, node.(*ast.StructType)) //nolint:forcetypeassert
+	})
+
+	return nil, nil //nolint:nilnil
+}
+
+func checkStruct(pass *analysis.Pass, st *ast.StructType) {
+	names := make(map[string]string)
+	for _, field := range st.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+		raw, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+		tag, ok := reflect.StructTag(raw).Lookup(tagName)
+		if !ok {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		checkName(pass, field, name)
+		checkOptions(pass, field, opts)
+		if name == "" || name == "-" {
+			continue
+		}
+
+		for _, fieldName := range fieldNames(field) {
+			if !ast.IsExported(fieldName) {
+				pass.Reportf(field.Tag.Pos(), "%s tag on unexported field %s", tagName, fieldName)
+			}
+			if other, ok := names[name]; ok {
+				pass.Reportf(field.Tag.Pos(), "bin name %q of field %s is used by field %s too", name, fieldName, other)
+			}
+			names[name] = fieldName
+		}
+		if typ := pass.TypesInfo.TypeOf(field.Type); typ != nil {
+			if unsupported := unsupportedType(typ, nil); unsupported != nil {
+				pass.Reportf(field.Tag.Pos(), "type %s of bin %q is not supported", unsupported, name)
+			}
+		}
+	}
+}
+
+func checkName(pass *analysis.Pass, field *ast.Field, name string) {
+	if len(name) > maxBinNameLength {
+		pass.Reportf(field.Tag.Pos(), "bin name %q is longer than %d bytes", name, maxBinNameLength)
+	}
+	if i := strings.IndexFunc(name, func(r rune) bool { return unicode.IsSpace(r) || strings.ContainsRune(`;:="'`, r) }); i >= 0 {
+		pass.Reportf(field.Tag.Pos(), "bin name %q contains %q, tag options must follow a comma", name, name[i])
+	}
+}
+
+func checkOptions(pass *analysis.Pass, field *ast.Field, opts string) {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		key, value, hasValue := strings.Cut(opt, "=")
+		if values, ok := valueOptions[key]; ok {
+			if !slices.Contains(values, value) {
+				pass.Reportf(field.Tag.Pos(), "unknown %s %q", key, value)
+			}
+			continue
+		}
+		switch {
+		case !slices.Contains(flagOptions, key):
+			pass.Reportf(field.Tag.Pos(), "unknown option %q", opt)
+		case hasValue:
+			pass.Reportf(field.Tag.Pos(), "option %q takes no value", key)
+		}
+	}
+}
+
+// fieldNames returns the names of the field, or the type name of an embedded field.
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) == 0 {
+		typ := field.Type
+		if star, ok := typ.(*ast.StarExpr); ok {
+			typ = star.X
+		}
+		switch typ := typ.(type) {
+		case *ast.Ident:
+			return []string{typ.Name}
+		case *ast.SelectorExpr:
+			return []string{typ.Sel.Name}
+		default:
+			return nil
+		}
+	}
+
+	names := make([]string, len(field.Names))
+	for i, name := range field.Names {
+		names[i] = name.Name
+	}
+
+	return names
+}
+
+// unsupportedType returns the type inside typ the library cannot store, nil if there is none,
+// mirroring Codec.Validate. Fields of named structs are checked where the structs are declared.
+// Types registered with RegisterType are not known to the analyzer.
+func unsupportedType(typ types.Type, seen map[types.Type]bool) types.Type {
+	if seen[typ] {
+		return nil
+	}
+	if seen == nil {
+		seen = make(map[types.Type]bool)
+	}
+	seen[typ] = true
+	if isTime(typ) || implementsFallback(typ) {
+		return nil
+	}
+
+	switch u := typ.Underlying().(type) {
+	case *types.Basic:
+		if isScalar(u) {
+			return nil
+		}
+		return typ
+	case *types.Pointer:
+		return unsupportedType(u.Elem(), seen)
+	case *types.Slice:
+		return unsupportedType(u.Elem(), seen)
+	case *types.Map:
+		if unsupported := unsupportedKey(u.Key()); unsupported != nil {
+			return unsupported
+		}
+		return unsupportedType(u.Elem(), seen)
+	case *types.Struct:
+		return nil
+	default:
+		return typ
+	}
+}
+
+// unsupportedKey returns the map key type if the library cannot store it, nil otherwise.
+func unsupportedKey(typ types.Type) types.Type {
+	if implementsFallback(typ) {
+		return nil
+	}
+	if b, ok := typ.Underlying().(*types.Basic); ok && b.Kind() != types.Uintptr &&
+		b.Info()&(types.IsString|types.IsInteger) != 0 {
+		return nil
+	}
+
+	return typ
+}
+
+func isScalar(b *types.Basic) bool {
+	return b.Kind() != types.Uintptr && b.Info()&(types.IsBoolean|types.IsString|types.IsInteger|types.IsFloat) != 0
+}
+
+func isTime(typ types.Type) bool {
+	named, ok := typ.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "time" && named.Obj().Name() == "Time"
+}
+
+// implementsFallback reports whether the library stores values of the type, or the type it points to,
+// with encoding.TextMarshaler, encoding.BinaryMarshaler or driver.Valuer. Scalars, slices and maps
+// are stored by their kind even if they implement them.
+func implementsFallback(typ types.Type) bool {
+	if ptr, ok := typ.Underlying().(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	switch u := typ.Underlying().(type) {
+	case *types.Basic:
+		if isScalar(u) {
+			return false
+		}
+	case *types.Slice, *types.Map:
+		return false
+	}
+	if isTime(typ) {
+		return false
+	}
+
+	for _, method := range []string{"MarshalText", "MarshalBinary", "Value"} {
+		obj, _, _ := types.LookupFieldOrMethod(typ, true, nil, method)
+		fn, ok := obj.(*types.Func)
+		if !ok {
+			continue
+		}
+		sig := fn.Signature()
+		if sig.Params().Len() == 0 && sig.Results().Len() == 2 && sig.Results().At(1).Type().String() == "error" {
+			return true
+		}
+	}
+
+	return false
+}
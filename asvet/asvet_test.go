@@ -0,0 +1,15 @@
+package asvet_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/viru-tech/go.aerospike/asvet"
+)
+
+func TestAnalyzer(t *testing.T) {
+	t.Parallel()
+
+	analysistest.Run(t, analysistest.TestData(), asvet.Analyzer, "a")
+}
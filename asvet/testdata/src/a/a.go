@@ -0,0 +1,46 @@
+package a
+
+import "time"
+
+// digest is stored with its MarshalText method.
+type digest [4]byte
+
+func (d digest) MarshalText() ([]byte, error) { return d[:], nil }
+
+func (d *digest) UnmarshalText(text []byte) error {
+	copy(d[:], text)
+	return nil
+}
+
+type valid struct {
+	ID      string            `as:",key"`
+	Name    string            `as:"name,omitempty"`
+	Email   string            `as:"email,index=string"`
+	Tags    []string          `as:"tags,index=string,collection=list"`
+	At      time.Time         `as:"at"`
+	Attrs   map[string]*valid `as:"attrs"`
+	Digest  digest            `as:"digest"`
+	Digests map[digest]int    `as:"digests"`
+	Ignored string            `as:"-"`
+	Other   chan int          `json:"other"`
+	private chan int
+}
+
+type invalid struct {
+	Name     string                `as:"name omitempty"`       // want `bin name "name omitempty" contains ' ', tag options must follow a comma`
+	Semi     string                `as:"semi;omitempty"`       // want `bin name "semi;omitempty" contains ';', tag options must follow a comma`
+	Spaced   string                `as:"spaced, omitempty"`    // want `unknown option " omitempty"`
+	Flag     string                `as:"flag,omitempty=true"`  // want `option "omitempty" takes no value`
+	Index    string                `as:"index,index=text"`     // want `unknown index "text"`
+	LongName string                `as:"a_very_long_bin_name"` // want `bin name "a_very_long_bin_name" is longer than 15 bytes`
+	Alias    string                `as:"flag"`                 // want `bin name "flag" of field Alias is used by field Flag too`
+	hidden   string                `as:"hidden"`               // want `as tag on unexported field hidden`
+	Done     chan bool             `as:"done"`                 // want `type chan bool of bin "done" is not supported`
+	Handlers []func()              `as:"handlers"`             // want `type func\(\) of bin "handlers" is not supported`
+	Ratios   map[string]complex128 `as:"ratios"`               // want `type complex128 of bin "ratios" is not supported`
+	Point    [2]int                `as:"point"`                // want `type \[2\]int of bin "point" is not supported`
+	Anything any                   `as:"anything"`             // want `type any of bin "anything" is not supported`
+	Values   []interface{ M() }    `as:"values"`               // want `type interface\{M\(\)\} of bin "values" is not supported`
+	ByPoint  map[[2]int]string     `as:"by_point"`             // want `type \[2\]int of bin "by_point" is not supported`
+	ByScore  map[float64]string    `as:"by_score"`             // want `type float64 of bin "by_score" is not supported`
+}
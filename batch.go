@@ -0,0 +1,102 @@
+package aerospike
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/aerospike/aerospike-client-go/v8/types"
+)
+
+// BatchResult is the outcome of reading a single record of a batch.
+type BatchResult[T any] struct {
+	// Key is the key of the record.
+	Key *aerospike.Key
+	// Value is the decoded record, valid only when Err is nil.
+	Value T
+	// Generation is the generation of the record, valid only when Err is nil.
+	Generation uint32
+	// Err is ErrNotFound for missing records, ErrFilteredOut for records rejected by the filter expression
+	// of the policy, ErrDecode for records that cannot be unmarshaled into T, or the error returned by the server.
+	Err error
+}
+
+// BatchDecode reads records under keys in a single batch and decodes them into T.
+// Only bins of T are requested from the server. Results are in the order of keys.
+// A non-nil error means the batch has failed as a whole; results are still returned
+// and records that got no response from the server have their Err set.
+func BatchDecode[T any](
+	ctx context.Context,
+	client Client,
+	policy *aerospike.BatchPolicy,
+	keys []*aerospike.Key,
+) ([]BatchResult[T], error) {
+	batchPolicy := aerospike.NewBatchPolicy()
+	if policy != nil {
+		*batchPolicy = *policy
+	}
+	if err := applyContext(ctx, &batchPolicy.BasePolicy); err != nil {
+		return nil, err
+	}
+	binNames, err := GetBinKeys(new(T))
+	if err != nil {
+		return nil, err
+	}
+
+	results, aerr := batchDecode(client, batchPolicy, keys, binNames, func(_ int, record *aerospike.Record, v *T) error {
+		return Unmarshal(record, v)
+	})
+	if aerr != nil {
+		return results, aerr
+	}
+
+	return results, nil
+}
+
+// batchDecode reads the bins of records under keys in a single batch and decodes found records
+// with decode, which gets the index of the key.
+func batchDecode[T any](
+	client Client,
+	policy *aerospike.BatchPolicy,
+	keys []*aerospike.Key,
+	binNames []string,
+	decode func(i int, record *aerospike.Record, v *T) error,
+) ([]BatchResult[T], aerospike.Error) {
+	records := make([]aerospike.BatchRecordIfc, len(keys))
+	for i := range keys {
+		records[i] = aerospike.NewBatchRead(nil, keys[i], binNames)
+	}
+
+	batchErr := client.BatchOperate(policy, records)
+	results := make([]BatchResult[T], len(records))
+	for i := range records {
+		results[i] = decodeBatchRecord(records[i].BatchRec(), batchErr, func(record *aerospike.Record, v *T) error {
+			return decode(i, record, v)
+		})
+	}
+
+	return results, batchErr
+}
+
+func decodeBatchRecord[T any](
+	record *aerospike.BatchRecord,
+	batchErr aerospike.Error,
+	decode func(record *aerospike.Record, v *T) error,
+) BatchResult[T] {
+	result := BatchResult[T]{Key: record.Key}
+	switch {
+	case record.ResultCode == types.OK && record.Record != nil:
+		result.Generation = record.Record.Generation
+		if err := decode(record.Record, &result.Value); err != nil {
+			result.Err = fmt.Errorf("%w: %w", ErrDecode, err)
+		}
+	case record.ResultCode == types.NO_RESPONSE && batchErr != nil:
+		result.Err = batchErr
+	case record.Err != nil:
+		result.Err = mapError(record.Err)
+	default:
+		result.Err = mapError(&aerospike.AerospikeError{ResultCode: record.ResultCode})
+	}
+
+	return result
+}
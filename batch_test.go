@@ -0,0 +1,72 @@
+package aerospike
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/aerospike/aerospike-client-go/v8/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeBatchRecord(t *testing.T) {
+	t.Parallel()
+	type record struct {
+		Text string    `as:"text"`
+		Time time.Time `as:"time"`
+	}
+	batchErr := &aerospike.AerospikeError{ResultCode: types.TIMEOUT}
+	tests := []struct {
+		name     string
+		in       aerospike.BatchRecord
+		want     BatchResult[record]
+		wantErr  error
+		batchErr aerospike.Error
+	}{
+		{
+			name: "found",
+			in: aerospike.BatchRecord{
+				ResultCode: types.OK,
+				Record:     &aerospike.Record{Bins: aerospike.BinMap{"text": "value"}, Generation: 2},
+			},
+			want: BatchResult[record]{Value: record{Text: "value"}, Generation: 2},
+		},
+		{
+			name:    "not found",
+			in:      aerospike.BatchRecord{ResultCode: types.KEY_NOT_FOUND_ERROR},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "filtered out",
+			in:      aerospike.BatchRecord{ResultCode: types.FILTERED_OUT},
+			wantErr: ErrFilteredOut,
+		},
+		{
+			name: "decode failure",
+			in: aerospike.BatchRecord{
+				ResultCode: types.OK,
+				Record:     &aerospike.Record{Bins: aerospike.BinMap{"time": "yesterday"}},
+			},
+			wantErr: ErrDecode,
+		},
+		{
+			name:     "no response",
+			in:       aerospike.BatchRecord{ResultCode: types.NO_RESPONSE},
+			batchErr: batchErr,
+			wantErr:  batchErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := decodeBatchRecord(&tt.in, tt.batchErr, func(record *aerospike.Record, v *record) error {
+				return Unmarshal(record, v)
+			})
+			if tt.wantErr != nil {
+				require.ErrorIs(t, got.Err, tt.wantErr)
+				return
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/aerospike/aerospike-client-go/v8/types"
+)
+
+const (
+	defaultBatchPutChunkSize   = 5000
+	defaultBatchPutConcurrency = 4
+)
+
+var errNoBins = errors.New("nothing to write")
+
+// BatchPutOption configures BatchPut.
+type BatchPutOption func(*batchPutOptions)
+
+type batchPutOptions struct {
+	policy      *aerospike.BatchPolicy
+	writePolicy *aerospike.BatchWritePolicy
+	chunkSize   int
+	concurrency int
+}
+
+// BatchPutChunkSize sets the maximum number of records sent in a single batch. Default is 5000.
+func BatchPutChunkSize(size int) BatchPutOption {
+	return func(o *batchPutOptions) {
+		o.chunkSize = size
+	}
+}
+
+// BatchPutConcurrency sets how many batches are executed in parallel. Default is 4.
+func BatchPutConcurrency(concurrency int) BatchPutOption {
+	return func(o *batchPutOptions) {
+		o.concurrency = concurrency
+	}
+}
+
+// BatchPutPolicy sets the policy of the batch commands.
+func BatchPutPolicy(policy *aerospike.BatchPolicy) BatchPutOption {
+	return func(o *batchPutOptions) {
+		o.policy = policy
+	}
+}
+
+// BatchPutWritePolicy sets the default policy of the written records.
+// Its expiration and generation are overridden by the TTL and generation fields of the items.
+func BatchPutWritePolicy(policy *aerospike.BatchWritePolicy) BatchPutOption {
+	return func(o *batchPutOptions) {
+		o.writePolicy = policy
+	}
+}
+
+// BatchPutResult is the outcome of writing a single item.
+type BatchPutResult struct {
+	// Key is the key of the item, nil if it could not be built.
+	Key *aerospike.Key
+	// Err is the error of the write, nil if the item was written.
+	Err error
+}
+
+// BatchPutReport holds results of BatchPut in the order of items.
+type BatchPutReport struct {
+	Results []BatchPutResult
+}
+
+// Failed returns indexes of items that were not written.
+func (r *BatchPutReport) Failed() []int {
+	return r.filter(func(err error) bool { return err != nil })
+}
+
+// Retryable returns indexes of items that failed because of a transient condition
+// and may be written if BatchPut is repeated for them. See IsRetryable.
+func (r *BatchPutReport) Retryable() []int {
+	return r.filter(IsRetryable)
+}
+
+// Permanent returns indexes of items that failed and would fail again if repeated as is.
+func (r *BatchPutReport) Permanent() []int {
+	return r.filter(func(err error) bool { return err != nil && !IsRetryable(err) })
+}
+
+func (r *BatchPutReport) filter(match func(error) bool) []int {
+	var out []int
+	for i := range r.Results {
+		if match(r.Results[i].Err) {
+			out = append(out, i)
+		}
+	}
+
+	return out
+}
+
+// BatchPut writes items into the set using batch commands.
+// Keys of the records are taken from the key fields of items, and the TTL and generation fields,
+// if present and non-zero, are applied to every record individually. Items are split into chunks
+// that are executed with bounded concurrency. Failures of individual items are reported
+// in BatchPutReport, while the error is returned only if T cannot be stored at all.
+func BatchPut[T any](
+	ctx context.Context,
+	client Client,
+	namespace, set string,
+	items []T,
+	opts ...BatchPutOption,
+) (*BatchPutReport, error) {
+	options := batchPutOptions{
+		policy:      aerospike.NewBatchPolicy(),
+		writePolicy: aerospike.NewBatchWritePolicy(),
+		chunkSize:   defaultBatchPutChunkSize,
+		concurrency: defaultBatchPutConcurrency,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	options.chunkSize = max(options.chunkSize, 1)
+	options.concurrency = max(options.concurrency, 1)
+
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("batch item type must be a struct: %w", errInputType)
+	}
+	meta := findMetaFields(typ)
+	if meta.key < 0 {
+		return nil, fmt.Errorf("%s: %w", typ, errNoKeyField)
+	}
+
+	report := &BatchPutReport{Results: make([]BatchPutResult, len(items))}
+	records := make([]aerospike.BatchRecordIfc, len(items))
+	for i := range items {
+		records[i], report.Results[i].Key, report.Results[i].Err = batchWriteRecord(
+			namespace, set, &items[i], meta, options.writePolicy)
+	}
+
+	sem := make(chan struct{}, options.concurrency)
+	var wg sync.WaitGroup
+	for chunk := range slices.Chunk(slices.Collect(validRecords(records)), options.chunkSize) {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+			wg.Go(func() {
+				defer func() { <-sem }()
+				executeBatchPut(ctx, client, options.policy, chunk, records, report)
+			})
+			continue
+		}
+		for _, i := range chunk {
+			report.Results[i].Err = ctx.Err()
+		}
+	}
+	wg.Wait()
+
+	return report, nil
+}
+
+// validRecords yields indexes of the records that were built successfully.
+func validRecords(records []aerospike.BatchRecordIfc) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := range records {
+			if records[i] != nil && !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func batchWriteRecord[T any](
+	namespace, set string,
+	item *T,
+	meta metaFields,
+	defaultPolicy *aerospike.BatchWritePolicy,
+) (aerospike.BatchRecordIfc, *aerospike.Key, error) {
+	rv := reflect.ValueOf(item).Elem()
+	keyValue, err := meta.keyValue(rv)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := aerospike.NewKey(namespace, set, keyValue)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create key: %w", err)
+	}
+	bins, err := Marshal(item)
+	if err != nil {
+		return nil, key, err
+	}
+	if len(bins) == 0 {
+		return nil, key, errNoBins
+	}
+
+	policy := *defaultPolicy
+	if gen := meta.generationOf(rv); gen != 0 {
+		policy.GenerationPolicy = aerospike.EXPECT_GEN_EQUAL
+		policy.Generation = gen
+	}
+	if expiration := meta.expirationOf(rv); expiration != 0 {
+		policy.Expiration = expiration
+	}
+
+	names := make([]string, 0, len(bins))
+	for name := range bins {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	ops := make([]*aerospike.Operation, len(names))
+	for i, name := range names {
+		ops[i] = aerospike.PutOp(aerospike.NewBin(name, bins[name]))
+	}
+
+	return aerospike.NewBatchWrite(&policy, key, ops...), key, nil
+}
+
+// executeBatchPut writes records with the given indexes in a single batch and stores results in the report.
+// Every call writes to its own indexes, so reports are filled without locking.
+func executeBatchPut(
+	ctx context.Context,
+	client Client,
+	defaultPolicy *aerospike.BatchPolicy,
+	indexes []int,
+	records []aerospike.BatchRecordIfc,
+	report *BatchPutReport,
+) {
+	policy := *defaultPolicy
+	if err := applyContext(ctx, &policy.BasePolicy); err != nil {
+		for _, i := range indexes {
+			report.Results[i].Err = err
+		}
+		return
+	}
+
+	chunk := make([]aerospike.BatchRecordIfc, len(indexes))
+	for j, i := range indexes {
+		chunk[j] = records[i]
+	}
+
+	batchErr := client.BatchOperate(&policy, chunk)
+	for j, i := range indexes {
+		record := chunk[j].BatchRec()
+		switch {
+		case record.ResultCode == types.OK:
+		case record.ResultCode == types.NO_RESPONSE && batchErr != nil:
+			report.Results[i].Err = batchErr
+		case record.Err != nil:
+			report.Results[i].Err = mapError(record.Err)
+		default:
+			report.Results[i].Err = mapError(&aerospike.AerospikeError{ResultCode: record.ResultCode, InDoubt: record.InDoubt})
+		}
+	}
+}
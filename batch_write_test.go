@@ -0,0 +1,78 @@
+package aerospike
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/aerospike/aerospike-client-go/v8/types"
+	"github.com/stretchr/testify/require"
+)
+
+type batchPutStruct struct {
+	ID   int           `as:",key"`
+	Gen  uint32        `as:",generation"`
+	TTL  time.Duration `as:",ttl"`
+	Name string        `as:"name,omitempty"`
+}
+
+func TestBatchWriteRecord(t *testing.T) {
+	t.Parallel()
+	meta := findMetaFields(reflect.TypeFor[batchPutStruct]())
+	defaultPolicy := aerospike.NewBatchWritePolicy()
+	defaultPolicy.Expiration = 10
+
+	t.Run("default policy", func(t *testing.T) {
+		t.Parallel()
+		rec, key, err := batchWriteRecord("test", "set", &batchPutStruct{ID: 1, Name: "one"}, meta, defaultPolicy)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), key.Value().GetObject())
+
+		write, ok := rec.(*aerospike.BatchWrite)
+		require.True(t, ok)
+		require.Len(t, write.Ops, 1)
+		require.Equal(t, uint32(10), write.Policy.Expiration)
+		require.Equal(t, aerospike.NONE, write.Policy.GenerationPolicy)
+	})
+	t.Run("per item ttl and generation", func(t *testing.T) {
+		t.Parallel()
+		item := &batchPutStruct{ID: 2, Gen: 3, TTL: time.Hour, Name: "two"}
+		rec, _, err := batchWriteRecord("test", "set", item, meta, defaultPolicy)
+		require.NoError(t, err)
+
+		write, ok := rec.(*aerospike.BatchWrite)
+		require.True(t, ok)
+		require.Equal(t, uint32(3600), write.Policy.Expiration)
+		require.Equal(t, aerospike.EXPECT_GEN_EQUAL, write.Policy.GenerationPolicy)
+		require.Equal(t, uint32(3), write.Policy.Generation)
+		require.Equal(t, uint32(10), defaultPolicy.Expiration)
+	})
+	t.Run("nothing to write", func(t *testing.T) {
+		t.Parallel()
+		rec, key, err := batchWriteRecord("test", "set", &batchPutStruct{ID: 3}, meta, defaultPolicy)
+		require.ErrorIs(t, err, errNoBins)
+		require.NotNil(t, key)
+		require.Nil(t, rec)
+	})
+}
+
+func TestBatchPutReport(t *testing.T) {
+	t.Parallel()
+	report := BatchPutReport{Results: []BatchPutResult{
+		{},
+		{Err: &aerospike.AerospikeError{ResultCode: types.TIMEOUT}},
+		{Err: mapError(&aerospike.AerospikeError{ResultCode: types.GENERATION_ERROR})},
+		{Err: &aerospike.AerospikeError{ResultCode: types.DEVICE_OVERLOAD}},
+		{Err: errNoBins},
+	}}
+	require.Equal(t, []int{1, 2, 3, 4}, report.Failed())
+	require.Equal(t, []int{1, 3}, report.Retryable())
+	require.Equal(t, []int{2, 4}, report.Permanent())
+}
+
+func TestBatchPutInvalidType(t *testing.T) {
+	t.Parallel()
+	_, err := BatchPut(t.Context(), nil, "test", "set", []innerStruct{{}})
+	require.ErrorIs(t, err, errNoKeyField)
+}
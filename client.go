@@ -0,0 +1,79 @@
+package aerospike
+
+import (
+	"github.com/aerospike/aerospike-client-go/v8"
+)
+
+// Client is the subset of aerospike client commands used by the typed helpers.
+// Use WrapClient to get it from *aerospike.Client, or aerospiketest.NewFake in tests.
+type Client interface {
+	Get(policy *aerospike.BasePolicy, key *aerospike.Key, binNames ...string) (*aerospike.Record, aerospike.Error)
+	Put(policy *aerospike.WritePolicy, key *aerospike.Key, bins aerospike.BinMap) aerospike.Error
+	Operate(
+		policy *aerospike.WritePolicy,
+		key *aerospike.Key,
+		operations ...*aerospike.Operation,
+	) (*aerospike.Record, aerospike.Error)
+	Delete(policy *aerospike.WritePolicy, key *aerospike.Key) (bool, aerospike.Error)
+	BatchOperate(policy *aerospike.BatchPolicy, records []aerospike.BatchRecordIfc) aerospike.Error
+	// Query executes the statement over the partitions of the filter and updates the filter
+	// with the progress, like QueryPartitions of *aerospike.Client.
+	Query(
+		policy *aerospike.QueryPolicy,
+		stmt *aerospike.Statement,
+		filter *aerospike.PartitionFilter,
+	) (Recordset, aerospike.Error)
+	// Scan reads records of the set in the partitions of the filter and updates the filter
+	// with the progress, like ScanPartitions of *aerospike.Client.
+	Scan(
+		policy *aerospike.ScanPolicy,
+		filter *aerospike.PartitionFilter,
+		namespace, set string,
+		binNames ...string,
+	) (Recordset, aerospike.Error)
+}
+
+// Recordset is a stream of query or scan results. *aerospike.Recordset implements it.
+type Recordset interface {
+	// Results returns the channel of results that is closed when the stream ends.
+	Results() <-chan *aerospike.Result
+	// Close stops the stream.
+	Close() aerospike.Error
+}
+
+// WrapClient returns Client backed by *aerospike.Client.
+func WrapClient(client *aerospike.Client) Client {
+	return &clientAdapter{Client: client}
+}
+
+// clientAdapter adapts query and scan commands of *aerospike.Client to Client.
+type clientAdapter struct {
+	*aerospike.Client
+}
+
+func (c *clientAdapter) Query(
+	policy *aerospike.QueryPolicy,
+	stmt *aerospike.Statement,
+	filter *aerospike.PartitionFilter,
+) (Recordset, aerospike.Error) {
+	rs, err := c.QueryPartitions(policy, stmt, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return rs, nil
+}
+
+func (c *clientAdapter) Scan(
+	policy *aerospike.ScanPolicy,
+	filter *aerospike.PartitionFilter,
+	namespace, set string,
+	binNames ...string,
+) (Recordset, aerospike.Error) {
+	rs, err := c.ScanPartitions(policy, filter, namespace, set, binNames...)
+	if err != nil {
+		return nil, err
+	}
+
+	return rs, nil
+}
@@ -0,0 +1,516 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	generatedSuffix  = "_asgen.go"
+	structTag        = "as"
+	maxBinNameLength = 15
+
+	clientPath  = "github.com/aerospike/aerospike-client-go/v8"
+	runtimePath = "github.com/viru-tech/go.aerospike/asgen"
+)
+
+var errUnsupported = errors.New("not supported")
+
+// valueKind is the way a value is converted.
+type valueKind int
+
+const (
+	kindBool valueKind = iota + 1
+	kindString
+	kindInt
+	kindUint
+	kindFloat
+	kindTime
+	kindPointer
+	kindSlice
+	kindMap
+	kindStruct
+)
+
+// valueType is a type the generated code converts.
+type valueType struct {
+	kind valueKind
+	typ  types.Type
+	// elem is the element type of pointers, slices and maps.
+	elem *valueType
+	// key is the key type of maps.
+	key *valueType
+}
+
+// field is a struct field stored as a bin.
+type field struct {
+	name      string
+	bin       string
+	omitEmpty bool
+	typ       *valueType
+}
+
+type generator struct {
+	pkg *types.Package
+	buf bytes.Buffer
+	// structs holds the structs to generate methods for in order, seen the ones queued.
+	structs []*types.Named
+	seen    map[*types.Named]bool
+	roots   map[*types.Named]bool
+	usesFmt bool
+	usesRT  bool
+}
+
+func loadPackage(dir string) (*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax,
+		Dir:  dir,
+	}
+	pkgs, err := packages.Load(cfg, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load package: %w", err)
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, got %d", dir, len(pkgs))
+	}
+	for _, pkgErr := range pkgs[0].Errors {
+		// Previously generated code may not compile against changed structs.
+		if !strings.Contains(pkgErr.Pos, generatedSuffix) {
+			return nil, fmt.Errorf("failed to load package: %w", pkgErr)
+		}
+	}
+
+	return pkgs[0], nil
+}
+
+// generate returns the source of the methods for the named structs of the package,
+// or for every struct with "as" tags if names is empty.
+func generate(pkg *packages.Package, names []string) ([]byte, error) {
+	g := &generator{
+		pkg:   pkg.Types,
+		seen:  make(map[*types.Named]bool),
+		roots: make(map[*types.Named]bool),
+	}
+	if err := g.queueRoots(names); err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	for i := 0; i < len(g.structs); i++ {
+		if err := g.generateStruct(&body, g.structs[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	g.printf("// Code generated by asgen. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\nimport (\n", g.pkg.Name())
+	if g.usesFmt {
+		g.printf("\t%q\n\n", "fmt")
+	}
+	g.printf("\t%q\n", clientPath)
+	if g.usesRT {
+		g.printf("\t%q\n", runtimePath)
+	}
+	g.printf(")\n")
+	g.buf.Write(body.Bytes())
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+
+	return src, nil
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) queueRoots(names []string) error {
+	scope := g.pkg.Scope()
+	if len(names) == 0 {
+		for _, name := range scope.Names() {
+			named, ok := scope.Lookup(name).Type().(*types.Named)
+			if _, isType := scope.Lookup(name).(*types.TypeName); !ok || !isType {
+				continue
+			}
+			if st, ok := named.Underlying().(*types.Struct); ok && hasTags(st) && named.TypeParams() == nil {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("package %s has no structs with %s tags", g.pkg.Name(), structTag)
+		}
+	}
+
+	for _, name := range names {
+		obj, ok := scope.Lookup(strings.TrimSpace(name)).(*types.TypeName)
+		if !ok {
+			return fmt.Errorf("type %s is not declared in package %s", name, g.pkg.Name())
+		}
+		named, ok := obj.Type().(*types.Named)
+		if !ok || named.TypeParams() != nil {
+			return fmt.Errorf("type %s: generic and alias types are %w", name, errUnsupported)
+		}
+		if _, ok := named.Underlying().(*types.Struct); !ok {
+			return fmt.Errorf("type %s is not a struct", name)
+		}
+		g.roots[named] = true
+		g.queue(named)
+	}
+
+	return nil
+}
+
+func (g *generator) queue(named *types.Named) {
+	if !g.seen[named] {
+		g.seen[named] = true
+		g.structs = append(g.structs, named)
+	}
+}
+
+func hasTags(st *types.Struct) bool {
+	for i := range st.NumFields() {
+		if _, ok := reflect.StructTag(st.Tag(i)).Lookup(structTag); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fields returns the fields of the struct stored as bins.
+func (g *generator) fields(named *types.Named) ([]field, error) {
+	st := named.Underlying().(*types.Struct) //nolint:forcetypeassert
+	var fields []field
+	names := make(map[string]string)
+	for i := range st.NumFields() {
+		v := st.Field(i)
+		bin, opts, _ := strings.Cut(reflect.StructTag(st.Tag(i)).Get(structTag), ",")
+		if bin == "" || bin == "-" {
+			continue
+		}
+
+		where := named.Obj().Name() + "." + v.Name()
+		if !v.Exported() {
+			return nil, fmt.Errorf("%s: unexported fields are %w", where, errUnsupported)
+		}
+		if g.roots[named] && len(bin) > maxBinNameLength {
+			return nil, fmt.Errorf("%s: bin name %q is longer than %d bytes", where, bin, maxBinNameLength)
+		}
+		if other, ok := names[bin]; ok {
+			return nil, fmt.Errorf("%s: bin name %q is used by %s too", where, bin, other)
+		}
+		names[bin] = v.Name()
+
+		typ, err := g.valueType(v.Type(), true)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", where, err)
+		}
+		f := field{name: v.Name(), bin: bin, typ: typ}
+		for opts != "" {
+			var opt string
+			opt, opts, _ = strings.Cut(opts, ",")
+			f.omitEmpty = f.omitEmpty || opt == "omitempty"
+		}
+		if f.omitEmpty && typ.kind == kindStruct && !types.Comparable(typ.typ) {
+			return nil, fmt.Errorf("%s: omitempty on a struct that is not comparable is %w", where, errUnsupported)
+		}
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// valueType classifies the type, nested is true for types of struct fields, which may be
+// pointers, slices, maps and structs rather than scalars.
+func (g *generator) valueType(typ types.Type, nested bool) (*valueType, error) {
+	if isTime(typ) {
+		return &valueType{kind: kindTime, typ: typ}, nil
+	}
+	if method := fallbackMethod(typ); method != "" {
+		return nil, fmt.Errorf("type %s implementing %s is %w", typ, method, errUnsupported)
+	}
+
+	switch u := typ.Underlying().(type) {
+	case *types.Basic:
+		if kind := basicKind(u); kind != 0 {
+			return &valueType{kind: kind, typ: typ}, nil
+		}
+	case *types.Pointer:
+		if !nested {
+			break
+		}
+		elem, err := g.valueType(u.Elem(), false)
+		if err != nil {
+			return nil, err
+		}
+		return &valueType{kind: kindPointer, typ: typ, elem: elem}, nil
+	case *types.Slice:
+		if !nested {
+			break
+		}
+		elem, err := g.valueType(u.Elem(), false)
+		if err != nil || elem.kind == kindTime {
+			return nil, fmt.Errorf("slices of %s are %w", u.Elem(), errUnsupported)
+		}
+		return &valueType{kind: kindSlice, typ: typ, elem: elem}, nil
+	case *types.Map:
+		if !nested {
+			break
+		}
+		key, err := g.valueType(u.Key(), false)
+		if err != nil || key.kind == kindTime || key.kind == kindBool || key.kind == kindFloat {
+			return nil, fmt.Errorf("map keys of %s are %w", u.Key(), errUnsupported)
+		}
+		elem, err := g.valueType(u.Elem(), false)
+		if err != nil || elem.kind == kindTime {
+			return nil, fmt.Errorf("map values of %s are %w", u.Elem(), errUnsupported)
+		}
+		return &valueType{kind: kindMap, typ: typ, key: key, elem: elem}, nil
+	case *types.Struct:
+		named, ok := typ.(*types.Named)
+		if !nested || !ok || named.Obj().Pkg() != g.pkg || named.TypeParams() != nil {
+			break
+		}
+		g.queue(named)
+		return &valueType{kind: kindStruct, typ: typ}, nil
+	}
+
+	return nil, fmt.Errorf("type %s is %w", typ, errUnsupported)
+}
+
+func basicKind(b *types.Basic) valueKind {
+	info := b.Info()
+	switch {
+	case info&types.IsBoolean != 0:
+		return kindBool
+	case info&types.IsString != 0:
+		return kindString
+	case b.Kind() == types.Uintptr:
+		return 0
+	case info&types.IsUnsigned != 0:
+		return kindUint
+	case info&types.IsInteger != 0:
+		return kindInt
+	case info&types.IsFloat != 0:
+		return kindFloat
+	default:
+		return 0
+	}
+}
+
+func isTime(typ types.Type) bool {
+	named, ok := typ.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "time" && named.Obj().Name() == "Time"
+}
+
+// fallbackMethod returns the method of the standard interfaces the default codec prefers
+// over storing structs as maps, which the generated code does not call.
+func fallbackMethod(typ types.Type) string {
+	if _, ok := typ.Underlying().(*types.Struct); !ok {
+		return ""
+	}
+	for _, method := range []string{"MarshalText", "MarshalBinary", "Value"} {
+		obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(typ), true, nil, method)
+		fn, ok := obj.(*types.Func)
+		if !ok {
+			continue
+		}
+		sig := fn.Signature()
+		if sig.Params().Len() == 0 && sig.Results().Len() == 2 && sig.Results().At(1).Type().String() == "error" {
+			return method
+		}
+	}
+
+	return ""
+}
+
+func (g *generator) generateStruct(w *bytes.Buffer, named *types.Named) error {
+	fields, err := g.fields(named)
+	if err != nil {
+		return err
+	}
+	name := named.Obj().Name()
+
+	fmt.Fprintf(w, "\n// MarshalAerospike converts v into bins the way aerospike.Marshal does.\n")
+	fmt.Fprintf(w, "func (v *%s) MarshalAerospike() (aerospike.BinMap, error) {\n", name)
+	fmt.Fprintf(w, "bins := make(aerospike.BinMap, %d)\n", len(fields))
+	for _, f := range fields {
+		g.marshalField(w, f)
+	}
+	fmt.Fprintf(w, "\nreturn bins, nil\n}\n")
+
+	fmt.Fprintf(w, "\n// UnmarshalAerospike sets fields of v from bins the way aerospike.Unmarshal does.\n")
+	fmt.Fprintf(w, "func (v *%s) UnmarshalAerospike(bins aerospike.BinMap) error {\n", name)
+	for _, f := range fields {
+		g.unmarshalField(w, f)
+	}
+	fmt.Fprintf(w, "\nreturn nil\n}\n")
+
+	return nil
+}
+
+func (g *generator) marshalField(w *bytes.Buffer, f field) {
+	value := "v." + f.name
+	bin := strconv.Quote(f.bin)
+	if f.omitEmpty {
+		fmt.Fprintf(w, "if %s {\n", g.nonZero(f.typ, value))
+	} else if f.typ.kind == kindSlice || f.typ.kind == kindMap || f.typ.kind == kindStruct {
+		fmt.Fprintf(w, "{\n")
+	}
+
+	switch f.typ.kind {
+	case kindPointer:
+		fmt.Fprintf(w, "if %s == nil {\nbins[%s] = nil\n} else {\nbins[%s] = %s\n}\n",
+			value, bin, bin, g.encode(f.typ.elem, "*"+value))
+	case kindSlice:
+		fmt.Fprintf(w, "list := make([]any, len(%s))\n", value)
+		fmt.Fprintf(w, "for i, elem := range %s {\nlist[i] = %s\n}\n", value, g.encode(f.typ.elem, "elem"))
+		fmt.Fprintf(w, "bins[%s] = list\n", bin)
+	case kindMap:
+		fmt.Fprintf(w, "m := make(map[any]any, len(%s))\n", value)
+		fmt.Fprintf(w, "for key, elem := range %s {\nm[%s] = %s\n}\n",
+			value, g.encodeKey(f.typ.key, "key"), g.encode(f.typ.elem, "elem"))
+		fmt.Fprintf(w, "bins[%s] = m\n", bin)
+	case kindStruct:
+		g.usesFmt = true
+		fmt.Fprintf(w, "nested, err := %s.MarshalAerospike()\n", value)
+		fmt.Fprintf(w, "if err != nil {\nreturn nil, fmt.Errorf(\"failed to convert field %s: %%w\", err)\n}\n", f.name)
+		fmt.Fprintf(w, "bins[%s] = map[string]any(nested)\n", bin)
+	default:
+		fmt.Fprintf(w, "bins[%s] = %s\n", bin, g.encode(f.typ, value))
+	}
+
+	if f.omitEmpty || f.typ.kind == kindSlice || f.typ.kind == kindMap || f.typ.kind == kindStruct {
+		fmt.Fprintf(w, "}\n")
+	}
+}
+
+// nonZero returns the condition of the value not being the zero value.
+func (g *generator) nonZero(typ *valueType, value string) string {
+	switch typ.kind {
+	case kindBool:
+		return value
+	case kindString:
+		return value + ` != ""`
+	case kindInt, kindUint, kindFloat:
+		return value + " != 0"
+	case kindTime:
+		g.usesRT = true
+		return "!asgen.IsZeroTime(" + value + ")"
+	case kindStruct:
+		return value + " != (" + typ.typ.(*types.Named).Obj().Name() + "{})" //nolint:forcetypeassert
+	default:
+		return value + " != nil"
+	}
+}
+
+// encode returns the expression converting the scalar or time value.
+func (g *generator) encode(typ *valueType, value string) string {
+	switch typ.kind {
+	case kindBool:
+		return convert(typ, types.Bool, value)
+	case kindString:
+		return convert(typ, types.String, value)
+	case kindInt, kindUint:
+		return convert(typ, types.Int64, value)
+	case kindFloat:
+		return convert(typ, types.Float64, value)
+	default:
+		g.usesRT = true
+		return "asgen.EncodeTime(" + value + ")"
+	}
+}
+
+// encodeKey returns the expression converting the map key.
+func (g *generator) encodeKey(typ *valueType, value string) string {
+	if typ.kind == kindUint {
+		return convert(typ, types.Uint64, value)
+	}
+
+	return g.encode(typ, value)
+}
+
+func convert(typ *valueType, kind types.BasicKind, value string) string {
+	if types.Identical(typ.typ, types.Typ[kind]) {
+		return value
+	}
+
+	return types.Typ[kind].Name() + "(" + value + ")"
+}
+
+func (g *generator) unmarshalField(w *bytes.Buffer, f field) {
+	g.usesRT = true
+	dst := "&v." + f.name
+	fmt.Fprintf(w, "if val, ok := bins[%s]; ok {\n", strconv.Quote(f.bin))
+
+	var call string
+	switch f.typ.kind {
+	case kindInt, kindUint:
+		fmt.Fprintf(w, "asgen.SetInt(%s, val)\n}\n", dst)
+		return
+	case kindFloat:
+		fmt.Fprintf(w, "asgen.SetFloat(%s, val)\n}\n", dst)
+		return
+	case kindPointer:
+		switch f.typ.elem.kind {
+		case kindInt, kindUint:
+			fmt.Fprintf(w, "asgen.SetIntPtr(%s, val)\n}\n", dst)
+			return
+		case kindFloat:
+			fmt.Fprintf(w, "asgen.SetFloatPtr(%s, val)\n}\n", dst)
+			return
+		case kindBool:
+			call = "asgen.SetBoolPtr(" + dst + ", val)"
+		case kindString:
+			call = "asgen.SetStringPtr(" + dst + ", val)"
+		default:
+			call = "asgen.SetTimePtr(" + dst + ", val)"
+		}
+	case kindBool:
+		call = "asgen.SetBool(" + dst + ", val)"
+	case kindString:
+		call = "asgen.SetString(" + dst + ", val)"
+	case kindTime:
+		call = "asgen.SetTime(" + dst + ", val)"
+	case kindSlice:
+		call = "asgen.SetSlice(" + dst + ", val, asgen.Convert" + scalarName(f.typ.elem) + ")"
+	case kindMap:
+		call = "asgen.SetMap(" + dst + ", val, asgen.Decode" + scalarName(f.typ.key) +
+			", asgen.Decode" + scalarName(f.typ.elem) + ")"
+	default:
+		fmt.Fprintf(w, "nested, err := asgen.Bins(val)\nif err == nil {\nerr = v.%s.UnmarshalAerospike(nested)\n}\n", f.name)
+		call = "err"
+	}
+
+	g.usesFmt = true
+	if call == "err" {
+		fmt.Fprintf(w, "if err != nil {\n")
+	} else {
+		fmt.Fprintf(w, "if err := %s; err != nil {\n", call)
+	}
+	fmt.Fprintf(w, "return fmt.Errorf(\"error while parsing field %s: %%w\", err)\n}\n}\n", f.name)
+}
+
+// scalarName returns the suffix of the runtime helpers for the scalar type.
+func scalarName(typ *valueType) string {
+	switch typ.kind {
+	case kindBool:
+		return "Bool"
+	case kindString:
+		return "String"
+	case kindFloat:
+		return "Float"
+	default:
+		return "Int"
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join("..", "..", "asgen", "internal", "example")
+	pkg, err := loadPackage(dir)
+	require.NoError(t, err)
+	want, err := os.ReadFile(filepath.Join(dir, "example"+generatedSuffix))
+	require.NoError(t, err)
+
+	got, err := generate(pkg, []string{"Record"})
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(got), "run go generate in %s", dir)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	t.Parallel()
+
+	pkg, err := loadPackage(filepath.Join("testdata", "invalid"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		typeName string
+		wantErr  string
+	}{
+		{typeName: "Unexported", wantErr: "Unexported.name: unexported fields are not supported"},
+		{typeName: "LongName", wantErr: `LongName.Name: bin name "a_very_long_bin_name" is longer than 15 bytes`},
+		{typeName: "Duplicate", wantErr: `Duplicate.Alias: bin name "name" is used by Name too`},
+		{typeName: "Channel", wantErr: "Channel.Done: type chan bool is not supported"},
+		{typeName: "TextMarshaler", wantErr: "TextMarshaler.Addr: type net/netip.Addr implementing MarshalText is not supported"},
+		{typeName: "PointerToStruct", wantErr: "PointerToStruct.Inner: type " +
+			"github.com/viru-tech/go.aerospike/cmd/asgen/testdata/invalid.Channel is not supported"},
+		{typeName: "SliceOfTimes", wantErr: "SliceOfTimes.At: slices of time.Time are not supported"},
+		{typeName: "FloatKeys", wantErr: "FloatKeys.Ratios: map keys of float64 are not supported"},
+		{typeName: "NestedInvalid", wantErr: "Channel.Done: type chan bool is not supported"},
+		{typeName: "Missing", wantErr: "type Missing is not declared in package invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typeName, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := generate(pkg, []string{tt.typeName})
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Command asgen generates MarshalAerospike and UnmarshalAerospike methods for structs with "as" tags,
+// which Marshal and Unmarshal of the default codec call instead of using reflection.
+// The methods store values the same way, see package asgen for the supported field types.
+//
+// Add a go:generate directive to the file declaring the structs:
+//
+//	//go:generate go run github.com/viru-tech/go.aerospike/cmd/asgen -type User,Order
+//
+// Without -type, methods are generated for every struct with "as" tags in the package.
+// Nested structs of the package are generated too. The output is written to the file
+// named after the one holding the directive with the "_asgen.go" suffix.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated struct names, all structs with as tags by default")
+	output := flag.String("output", "", "output file, <file>_asgen.go by default")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: asgen [-type T,U] [-output file] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	var names []string
+	if *typeNames != "" {
+		names = strings.Split(*typeNames, ",")
+	}
+
+	if err := run(dir, names, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "asgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string, names []string, output string) error {
+	pkg, err := loadPackage(dir)
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(pkg, names)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output = outputName(pkg.Name)
+	}
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(dir, output)
+	}
+
+	return os.WriteFile(output, src, 0o600)
+}
+
+// outputName names the output after the file of the go:generate directive, or the package.
+func outputName(pkgName string) string {
+	if file := os.Getenv("GOFILE"); file != "" {
+		return strings.TrimSuffix(file, ".go") + generatedSuffix
+	}
+
+	return pkgName + generatedSuffix
+}
@@ -0,0 +1,43 @@
+package invalid
+
+import (
+	"net/netip"
+	"time"
+)
+
+type Unexported struct {
+	name string `as:"name"`
+}
+
+type LongName struct {
+	Name string `as:"a_very_long_bin_name"`
+}
+
+type Duplicate struct {
+	Name  string `as:"name"`
+	Alias string `as:"name"`
+}
+
+type Channel struct {
+	Done chan bool `as:"done"`
+}
+
+type TextMarshaler struct {
+	Addr netip.Addr `as:"addr"`
+}
+
+type PointerToStruct struct {
+	Inner *Channel `as:"inner"`
+}
+
+type SliceOfTimes struct {
+	At []time.Time `as:"at"`
+}
+
+type FloatKeys struct {
+	Ratios map[float64]int `as:"ratios"`
+}
+
+type NestedInvalid struct {
+	Inner Channel `as:"inner"`
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/template"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/viru-tech/go.aerospike"
+)
+
+var errUnsupported = errors.New("not supported")
+
+// program prints the schema of the type. It is run from a hidden directory of the package,
+// so it may import internal packages.
+var program = template.Must(template.New("program").Parse(`package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/viru-tech/go.aerospike"
+	target {{printf "%q" .Path}}
+)
+
+func main() {
+	if err := json.NewEncoder(os.Stdout).Encode(aerospike.Describe[target.{{.Type}}]()); err != nil {
+		panic(err)
+	}
+}
+`))
+
+// describe returns the schema of the named struct of the package in dir by running a program
+// calling aerospike.Describe.
+func describe(dir, typeName string) (aerospike.Schema, error) {
+	pkg, err := loadPackage(dir)
+	if err != nil {
+		return aerospike.Schema{}, err
+	}
+	if err = checkType(pkg, typeName); err != nil {
+		return aerospike.Schema{}, err
+	}
+
+	tmp, err := os.MkdirTemp(filepath.Dir(pkg.GoFiles[0]), ".asschema")
+	if err != nil {
+		return aerospike.Schema{}, fmt.Errorf("failed to create program directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	var src bytes.Buffer
+	if err = program.Execute(&src, struct{ Path, Type string }{Path: pkg.PkgPath, Type: typeName}); err != nil {
+		return aerospike.Schema{}, fmt.Errorf("failed to render program: %w", err)
+	}
+	file := filepath.Join(tmp, "main.go")
+	if err = os.WriteFile(file, src.Bytes(), 0o600); err != nil {
+		return aerospike.Schema{}, fmt.Errorf("failed to write program: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", "run", file)
+	cmd.Dir = tmp
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err = cmd.Run(); err != nil {
+		return aerospike.Schema{}, fmt.Errorf("failed to describe %s: %w\n%s", typeName, err, stderr.Bytes())
+	}
+
+	var schema aerospike.Schema
+	if err = json.Unmarshal(stdout.Bytes(), &schema); err != nil {
+		return aerospike.Schema{}, fmt.Errorf("failed to decode schema of %s: %w", typeName, err)
+	}
+
+	return schema, nil
+}
+
+func loadPackage(dir string) (*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedSyntax,
+		Dir:  dir,
+	}
+	pkgs, err := packages.Load(cfg, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load package: %w", err)
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, got %d", dir, len(pkgs))
+	}
+	for _, pkgErr := range pkgs[0].Errors {
+		return nil, fmt.Errorf("failed to load package: %w", pkgErr)
+	}
+
+	return pkgs[0], nil
+}
+
+// checkType reports types the program cannot describe.
+func checkType(pkg *packages.Package, typeName string) error {
+	if pkg.Name == "main" {
+		return fmt.Errorf("types of package main cannot be imported: %w", errUnsupported)
+	}
+
+	obj, ok := pkg.Types.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return fmt.Errorf("type %s is not declared in package %s", typeName, pkg.Name)
+	}
+	if !obj.Exported() {
+		return fmt.Errorf("unexported type %s: %w", typeName, errUnsupported)
+	}
+	if named, ok := obj.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+		return fmt.Errorf("generic type %s: %w", typeName, errUnsupported)
+	}
+	if _, ok := obj.Type().Underlying().(*types.Struct); !ok {
+		return fmt.Errorf("type %s is not a struct: %w", typeName, errUnsupported)
+	}
+
+	return nil
+}
+
+func readSnapshot(path string) (aerospike.Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return aerospike.Schema{}, fmt.Errorf("failed to read snapshot, create it with -update: %w", err)
+	}
+
+	var schema aerospike.Schema
+	if err = json.Unmarshal(data, &schema); err != nil {
+		return aerospike.Schema{}, fmt.Errorf("failed to decode snapshot %s: %w", path, err)
+	}
+
+	return schema, nil
+}
+
+func writeSnapshot(path string, schema aerospike.Schema) error {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
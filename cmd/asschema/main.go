@@ -0,0 +1,74 @@
+// Command asschema compares the schema of a stored struct with a snapshot committed with the code,
+// failing when the struct cannot read records written with the snapshot, see aerospike.CheckCompatibility.
+// Run it in CI to block incompatible deploys:
+//
+//	go run github.com/viru-tech/go.aerospike/cmd/asschema -type User -snapshot schema/user.json ./models
+//
+// With -update, the snapshot is written from the current code instead. Schemas are described
+// with the default codec, so the struct must be exported from a package other than main.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/viru-tech/go.aerospike"
+)
+
+var errIncompatible = errors.New("incompatible schema")
+
+func main() {
+	typeName := flag.String("type", "", "struct name")
+	snapshot := flag.String("snapshot", "", "schema snapshot file")
+	update := flag.Bool("update", false, "write the snapshot from the current code")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: asschema -type T -snapshot file [-update] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *typeName == "" || *snapshot == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	if err := run(os.Stdout, dir, *typeName, *snapshot, *update); err != nil {
+		fmt.Fprintf(os.Stderr, "asschema: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(w io.Writer, dir, typeName, snapshot string, update bool) error {
+	if update {
+		current, err := describe(dir, typeName)
+		if err != nil {
+			return err
+		}
+		return writeSnapshot(snapshot, current)
+	}
+
+	old, err := readSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	current, err := describe(dir, typeName)
+	if err != nil {
+		return err
+	}
+	found := aerospike.CheckCompatibility(old, current)
+	for _, incompatibility := range found {
+		fmt.Fprintf(w, "%s: %s\n", typeName, incompatibility)
+	}
+	if len(found) > 0 {
+		return fmt.Errorf("%s has %d breaking changes since %s: %w", typeName, len(found), snapshot, errIncompatible)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var exampleDir = filepath.Join("..", "..", "asgen", "internal", "example")
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		snapshot string
+		wantOut  string
+		wantErr  string
+	}{
+		{
+			name:     "compatible",
+			snapshot: "record.json",
+		},
+		{
+			name:     "incompatible",
+			snapshot: "record_v0.json",
+			wantOut: "Record: email: bin of field Email was removed\n" +
+				"Record: age: int64 narrowed to int8\n",
+			wantErr: "Record has 2 breaking changes since testdata/record_v0.json: incompatible schema",
+		},
+		{
+			name:     "missing snapshot",
+			snapshot: "missing.json",
+			wantErr:  "failed to read snapshot, create it with -update: open testdata/missing.json: no such file or directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			err := run(&out, exampleDir, "Record", filepath.Join("testdata", tt.snapshot), false)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantOut, out.String())
+		})
+	}
+}
+
+func TestRunUpdate(t *testing.T) {
+	t.Parallel()
+
+	snapshot := filepath.Join(t.TempDir(), "record.json")
+	require.NoError(t, run(nil, exampleDir, "Record", snapshot, true))
+
+	got, err := os.ReadFile(snapshot)
+	require.NoError(t, err)
+	want, err := os.ReadFile(filepath.Join("testdata", "record.json"))
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(got), "run asschema -update in testdata")
+}
+
+func TestDescribeErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dir      string
+		typeName string
+		wantErr  string
+	}{
+		{dir: exampleDir, typeName: "Missing", wantErr: "type Missing is not declared in package example"},
+		{dir: exampleDir, typeName: "Level", wantErr: "type Level is not a struct: not supported"},
+		{dir: ".", typeName: "Record", wantErr: "types of package main cannot be imported: not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typeName, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := describe(tt.dir, tt.typeName)
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,15 @@
+// Command asvet reports problems with "as" struct tags, see package asvet.
+//
+//	go install github.com/viru-tech/go.aerospike/cmd/asvet
+//	go vet -vettool=$(which asvet) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/viru-tech/go.aerospike/asvet"
+)
+
+func main() {
+	singlechecker.Main(asvet.Analyzer)
+}
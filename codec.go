@@ -0,0 +1,341 @@
+package aerospike
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+)
+
+// TimeFormat is the way a Codec stores time.Time values.
+type TimeFormat int
+
+const (
+	// TimeUnix stores Unix seconds, the zero time as 0. Values are decoded in UTC.
+	TimeUnix TimeFormat = iota
+	// TimeUnixMilli stores Unix milliseconds, the zero time as 0. Values are decoded in UTC.
+	TimeUnixMilli
+	// TimeUnixNano stores Unix nanoseconds, the zero time as 0. Values are decoded in UTC.
+	TimeUnixNano
+	// TimeRFC3339 stores strings in time.RFC3339Nano format keeping the offset, the zero time as "".
+	TimeRFC3339
+)
+
+// NilPolicy is the way a Codec stores nil slices and maps.
+type NilPolicy int
+
+const (
+	// NilAsEmpty stores nil slices and maps as empty lists and maps.
+	NilAsEmpty NilPolicy = iota
+	// NilAsNil stores nil slices and maps as nil values, which remove bins on writes.
+	NilAsNil
+)
+
+// UintPolicy is the way a Codec stores unsigned integers, which the server does not support.
+type UintPolicy int
+
+const (
+	// UintWrap stores unsigned integers as int64 values with the same bits, so values above
+	// math.MaxInt64 are stored negative and decoded back unchanged.
+	UintWrap UintPolicy = iota
+	// UintReject fails to marshal unsigned integers above math.MaxInt64.
+	UintReject
+)
+
+// Codec converts structs into bins and back. Its options and registered types are isolated
+// from other codecs, so libraries in the same binary can use different conventions.
+// The package-level Marshal, Unmarshal and GetBinKeys use the default codec, which RegisterType
+// and SetFallbacks configure. A Codec is safe for concurrent use.
+type Codec struct {
+	tagName    string
+	timeFormat TimeFormat
+	nilPolicy  NilPolicy
+	uintPolicy UintPolicy
+	strict     bool
+	naming     NamingStrategy
+	shortNames bool
+
+	mu        sync.RWMutex
+	types     map[reflect.Type]typeConverter
+	fallbacks []Fallback
+
+	// fields caches struct fields stored as bins by struct type.
+	fields sync.Map
+	// plain caches whether struct types store no values of registered types.
+	plain sync.Map
+}
+
+// CodecOption configures a Codec.
+type CodecOption func(*Codec)
+
+// WithTagName sets the struct tag holding bin names and options. Default is "as".
+func WithTagName(name string) CodecOption {
+	return func(c *Codec) {
+		c.tagName = name
+	}
+}
+
+// WithTimeFormat sets the way time.Time values are stored. Default is TimeUnix.
+func WithTimeFormat(format TimeFormat) CodecOption {
+	return func(c *Codec) {
+		c.timeFormat = format
+	}
+}
+
+// WithNilPolicy sets the way nil slices and maps are stored. Default is NilAsEmpty.
+// Nil pointers are always stored as nil values.
+func WithNilPolicy(policy NilPolicy) CodecOption {
+	return func(c *Codec) {
+		c.nilPolicy = policy
+	}
+}
+
+// WithUintPolicy sets the way unsigned integers are stored. Default is UintWrap.
+func WithUintPolicy(policy UintPolicy) CodecOption {
+	return func(c *Codec) {
+		c.uintPolicy = policy
+	}
+}
+
+// WithStrict makes Unmarshal fail on bins without a matching field and on values that do not fit
+// the field type, e.g. a string into an int field or 300 into an int8 field, which are otherwise
+// ignored or converted with loss.
+func WithStrict() CodecOption {
+	return func(c *Codec) {
+		c.strict = true
+	}
+}
+
+// WithFallbacks sets the standard interfaces consulted for types that are not registered,
+// see SetFallbacks.
+func WithFallbacks(order ...Fallback) CodecOption {
+	return func(c *Codec) {
+		c.fallbacks = slices.Clone(order)
+	}
+}
+
+// WithType registers conversions of values of T with the codec, see RegisterType.
+func WithType[T any](encode func(T) (any, error), decode func(any) (T, error)) CodecOption {
+	return func(c *Codec) {
+		c.register(reflect.TypeFor[T](), newTypeConverter(encode, decode))
+	}
+}
+
+var defaultCodec = NewCodec()
+
+// NewCodec returns a codec with the options.
+func NewCodec(opts ...CodecOption) *Codec {
+	c := &Codec{
+		tagName:   structTag,
+		types:     make(map[reflect.Type]typeConverter),
+		fallbacks: []Fallback{FallbackText, FallbackBinary, FallbackSQL},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// DefaultCodec returns the codec of the package-level functions, which holds the types registered
+// with RegisterType.
+func DefaultCodec() *Codec {
+	return defaultCodec
+}
+
+// Marshal converts the struct v points to into bins.
+func (c *Codec) Marshal(v any) (aerospike.BinMap, error) {
+	if v == nil {
+		return aerospike.BinMap{}, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	indirect := reflect.Indirect(rv)
+	if rv.Kind() != reflect.Pointer || indirect.Kind() != reflect.Struct || rv.IsNil() {
+		return aerospike.BinMap{}, fmt.Errorf("the provided variable must be a non-nil pointer to a struct: %w", errInputType)
+	}
+
+	if m, ok := v.(Marshaler); ok && c.generated(indirect.Type()) {
+		return m.MarshalAerospike()
+	}
+	if _, err := c.binFields(indirect.Type()); err != nil {
+		return nil, err
+	}
+
+	binMap, err := c.marshalStruct(indirect)
+	if err != nil {
+		return nil, err
+	}
+
+	return binMap, nil
+}
+
+// Unmarshal parses bins of the record into the struct v points to.
+func (c *Codec) Unmarshal(record *aerospike.Record, v any) error {
+	if record == nil {
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	indirect := reflect.Indirect(rv)
+	if rv.Kind() != reflect.Pointer || indirect.Kind() != reflect.Struct || rv.IsNil() {
+		return errInputType
+	}
+
+	if u, ok := v.(Unmarshaler); ok && c.generated(indirect.Type()) {
+		return u.UnmarshalAerospike(record.Bins)
+	}
+	if _, err := c.binFields(indirect.Type()); err != nil {
+		return err
+	}
+
+	return c.unmarshalStruct(indirect, map[string]any(record.Bins))
+}
+
+// BinKeys returns bin names of the struct v or v points to, or keys of the map with string keys.
+func (c *Codec) BinKeys(v any) ([]string, error) {
+	rv := reflect.ValueOf(v)
+	indirect := reflect.Indirect(rv)
+	switch indirect.Kind() {
+	case reflect.Struct:
+		return c.structBinKeys(indirect.Type())
+	case reflect.Map:
+		return getMapBinKeys(indirect)
+	default:
+		return nil, errInputType
+	}
+}
+
+// codecField is a struct field stored as a bin.
+type codecField struct {
+	index int
+	tag   fieldTag
+}
+
+// fieldsEntry is a cached result of parseFields.
+type fieldsEntry struct {
+	fields []codecField
+	// err reports fields with the same name.
+	err error
+	// binErr reports names longer than the server accepts, which are valid as map keys
+	// of nested structs.
+	binErr error
+}
+
+// structFields returns the fields of the struct type stored as bins or map entries,
+// or an error if they have the same name.
+func (c *Codec) structFields(typ reflect.Type) ([]codecField, error) {
+	entry := c.parseFields(typ)
+	return entry.fields, entry.err
+}
+
+// binFields returns the fields of the struct type stored as bins, or an error if their
+// names are invalid bin names.
+func (c *Codec) binFields(typ reflect.Type) ([]codecField, error) {
+	entry := c.parseFields(typ)
+	if entry.err != nil {
+		return nil, entry.err
+	}
+
+	return entry.fields, entry.binErr
+}
+
+func (c *Codec) parseFields(typ reflect.Type) fieldsEntry {
+	if cached, ok := c.fields.Load(typ); ok {
+		return cached.(fieldsEntry) //nolint:forcetypeassert
+	}
+
+	var entry fieldsEntry
+	names := make(map[string]string, typ.NumField())
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		tag := c.fieldName(field)
+		if tag.name == "" {
+			continue
+		}
+		if other, ok := names[tag.name]; ok && entry.err == nil {
+			entry.err = fmt.Errorf("fields %s and %s of %s have the same name %q: %w",
+				other, field.Name, typ, tag.name, errBinName)
+		}
+		if len(tag.name) > maxBinNameLength && entry.binErr == nil {
+			entry.binErr = fmt.Errorf("bin name %q of field %s of %s is longer than %d bytes: %w",
+				tag.name, field.Name, typ, maxBinNameLength, errBinName)
+		}
+		names[tag.name] = field.Name
+		entry.fields = append(entry.fields, codecField{index: i, tag: tag})
+	}
+	c.fields.Store(typ, entry)
+
+	return entry
+}
+
+func (c *Codec) structBinKeys(typ reflect.Type) ([]string, error) {
+	fields, err := c.binFields(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(fields))
+	for i, field := range fields {
+		keys[i] = field.tag.name
+	}
+
+	return keys, nil
+}
+
+// encodeTime converts t into the stored representation.
+func (c *Codec) encodeTime(t time.Time) any {
+	if c.timeFormat == TimeRFC3339 {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339Nano)
+	}
+	if t.IsZero() {
+		return 0
+	}
+
+	switch c.timeFormat {
+	case TimeUnixMilli:
+		return t.UnixMilli()
+	case TimeUnixNano:
+		return t.UnixNano()
+	default:
+		return t.Unix()
+	}
+}
+
+// decodeTime parses the stored representation of time.
+func (c *Codec) decodeTime(v any) (time.Time, error) {
+	if v == nil {
+		return time.Time{}, nil
+	}
+
+	if c.timeFormat == TimeRFC3339 {
+		s, ok := v.(string)
+		if !ok {
+			return time.Time{}, fmt.Errorf("time must be a string: %w", errInputType)
+		}
+		if s == "" {
+			return time.Time{}, nil
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	}
+
+	timestamp, ok := v.(int)
+	if !ok {
+		return time.Time{}, fmt.Errorf("time must be an int: %w", errInputType)
+	}
+
+	switch c.timeFormat {
+	case TimeUnixMilli:
+		return time.UnixMilli(int64(timestamp)).UTC(), nil
+	case TimeUnixNano:
+		return time.Unix(0, int64(timestamp)).UTC(), nil
+	default:
+		return time.Unix(int64(timestamp), 0).UTC(), nil
+	}
+}
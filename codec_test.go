@@ -0,0 +1,172 @@
+package aerospike
+
+import (
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/stretchr/testify/require"
+)
+
+type codecStruct struct {
+	Name  string    `as:"name" db:"full_name"`
+	Count uint64    `as:"count" db:"count"`
+	Small int8      `as:"small" db:"small"`
+	Tags  []string  `as:"tags" db:"tags"`
+	At    time.Time `as:"at" db:"at"`
+	Ref   *int      `as:"ref" db:"ref"`
+}
+
+func TestCodecMarshal(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2024, 5, 6, 7, 8, 9, 10_000_000, time.FixedZone("", 3600))
+	v := &codecStruct{Name: "a", Count: math.MaxUint64, Small: -1, At: at}
+	tests := []struct {
+		name    string
+		codec   *Codec
+		want    aerospike.BinMap
+		wantErr error
+	}{
+		{
+			name:  "default",
+			codec: NewCodec(),
+			want: aerospike.BinMap{
+				"name": "a", "count": int64(-1), "small": int64(-1), "tags": []any{}, "at": at.Unix(), "ref": nil,
+			},
+		},
+		{
+			name:  "tag name",
+			codec: NewCodec(WithTagName("db")),
+			want: aerospike.BinMap{
+				"full_name": "a", "count": int64(-1), "small": int64(-1), "tags": []any{}, "at": at.Unix(), "ref": nil,
+			},
+		},
+		{
+			name:  "time and nil policy",
+			codec: NewCodec(WithTimeFormat(TimeRFC3339), WithNilPolicy(NilAsNil)),
+			want: aerospike.BinMap{
+				"name": "a", "count": int64(-1), "small": int64(-1), "tags": nil, "at": "2024-05-06T07:08:09.01+01:00", "ref": nil,
+			},
+		},
+		{
+			name:    "uint policy",
+			codec:   NewCodec(WithUintPolicy(UintReject)),
+			wantErr: errInputType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.codec.Marshal(v)
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCodecUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	bins := aerospike.BinMap{"name": "a", "count": -1, "small": 1, "at": 1715000000123, "ref": 2}
+	tests := []struct {
+		name    string
+		codec   *Codec
+		bins    aerospike.BinMap
+		want    codecStruct
+		wantErr error
+	}{
+		{
+			name:  "unix milliseconds",
+			codec: NewCodec(WithTimeFormat(TimeUnixMilli)),
+			bins:  bins,
+			want: codecStruct{
+				Name: "a", Count: math.MaxUint64, Small: 1, At: time.UnixMilli(1715000000123).UTC(), Ref: ptr(2),
+			},
+		},
+		{
+			name:  "loose",
+			codec: NewCodec(),
+			bins:  aerospike.BinMap{"small": 300, "name": "a", "unknown": 1},
+			want:  codecStruct{Name: "a", Small: 44},
+		},
+		{
+			name:    "strict overflow",
+			codec:   NewCodec(WithStrict()),
+			bins:    aerospike.BinMap{"small": 300},
+			wantErr: errInputType,
+		},
+		{
+			name:    "strict type",
+			codec:   NewCodec(WithStrict()),
+			bins:    aerospike.BinMap{"tags": []any{1}},
+			wantErr: errInputType,
+		},
+		{
+			name:    "strict unknown bin",
+			codec:   NewCodec(WithStrict()),
+			bins:    aerospike.BinMap{"name": "a", "unknown": 1},
+			wantErr: errInputType,
+		},
+		{
+			name:  "strict",
+			codec: NewCodec(WithStrict(), WithTimeFormat(TimeUnixNano)),
+			bins:  aerospike.BinMap{"count": -1, "small": -128, "tags": []any{"a"}, "at": 5},
+			want:  codecStruct{Count: math.MaxUint64, Small: -128, Tags: []string{"a"}, At: time.Unix(0, 5).UTC()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got codecStruct
+			err := tt.codec.Unmarshal(&aerospike.Record{Bins: tt.bins}, &got)
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				require.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCodecIsolation(t *testing.T) {
+	t.Parallel()
+
+	type counter int
+	hex := NewCodec(
+		WithTagName("db"),
+		WithType(
+			func(c counter) (any, error) { return strconv.FormatInt(int64(c), 16), nil },
+			func(v any) (counter, error) {
+				s, _ := v.(string)
+				i, err := strconv.ParseInt(s, 16, 64)
+				return counter(i), err
+			},
+		),
+	)
+	v := &struct {
+		Hits counter `as:"hits" db:"h"`
+	}{Hits: 255}
+
+	bins, err := hex.Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, aerospike.BinMap{"h": "ff"}, bins)
+	bins, err = Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, aerospike.BinMap{"hits": int64(255)}, bins)
+
+	keys, err := hex.BinKeys(v)
+	require.NoError(t, err)
+	require.Equal(t, []string{"h"}, keys)
+
+	v.Hits = 0
+	require.NoError(t, hex.Unmarshal(&aerospike.Record{Bins: aerospike.BinMap{"h": "10"}}, v))
+	require.Equal(t, counter(16), v.Hits)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
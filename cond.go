@@ -0,0 +1,584 @@
+package aerospike
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+)
+
+var (
+	errInvalidCond = errors.New("invalid condition")
+	// errUnknownMeta is returned when a condition reads record metadata that was not provided.
+	errUnknownMeta = errors.New("record metadata is unknown")
+)
+
+type operandKind int
+
+const (
+	operandBin operandKind = iota
+	operandTTL
+	operandLastUpdate
+)
+
+// CondOperand is the left side of a comparison: a bin or record metadata.
+type CondOperand struct {
+	kind operandKind
+	bin  string
+}
+
+// CondBin returns the operand reading the bin. The bin type is inferred from the value it is compared with.
+func CondBin(name string) CondOperand {
+	return CondOperand{kind: operandBin, bin: name}
+}
+
+// CondTTL returns the operand reading the remaining time-to-live of the record.
+// It is compared with a time.Duration or an integer number of seconds; -1 means the record never expires.
+func CondTTL() CondOperand {
+	return CondOperand{kind: operandTTL}
+}
+
+// CondLastUpdate returns the operand reading the time of the last update of the record.
+// It is compared with a time.Time or an integer number of nanoseconds since the Unix epoch.
+func CondLastUpdate() CondOperand {
+	return CondOperand{kind: operandLastUpdate}
+}
+
+func (o CondOperand) String() string {
+	switch o.kind {
+	case operandTTL:
+		return "ttl"
+	case operandLastUpdate:
+		return "last update"
+	default:
+		return "bin " + o.bin
+	}
+}
+
+type condKind int
+
+const (
+	condCompare condKind = iota
+	condAnd
+	condOr
+	condNot
+	condRegex
+	condListContains
+	condMapContainsKey
+	condMapContainsValue
+	condBinExists
+)
+
+type compareOp int
+
+const (
+	opEq compareOp = iota
+	opNotEq
+	opGt
+	opGe
+	opLt
+	opLe
+)
+
+// Cond is a filter condition that can be compiled into an aerospike expression with Expression
+// or evaluated locally with Match, giving the same result as the server.
+// Like the server, conditions on missing bins or bins of another type are neither true nor false:
+// they make the whole condition false unless decided by the other operands of CondAnd and CondOr,
+// so CondNot(CondEq(CondBin("a"), 1)) does not match records without bin "a".
+type Cond struct {
+	kind    condKind
+	op      compareOp
+	operand CondOperand
+	// value is the normalized value the operand is compared with.
+	value any
+	conds []Cond
+	regex *regexp.Regexp
+	flags aerospike.ExpRegexFlags
+	err   error
+}
+
+// CondEq returns the condition that is true if the operand equals value.
+func CondEq(operand CondOperand, value any) Cond {
+	return compare(opEq, operand, value)
+}
+
+// CondNotEq returns the condition that is true if the operand does not equal value.
+func CondNotEq(operand CondOperand, value any) Cond {
+	return compare(opNotEq, operand, value)
+}
+
+// CondGt returns the condition that is true if the operand is greater than value.
+func CondGt(operand CondOperand, value any) Cond {
+	return compare(opGt, operand, value)
+}
+
+// CondGe returns the condition that is true if the operand is greater than or equal to value.
+func CondGe(operand CondOperand, value any) Cond {
+	return compare(opGe, operand, value)
+}
+
+// CondLt returns the condition that is true if the operand is less than value.
+func CondLt(operand CondOperand, value any) Cond {
+	return compare(opLt, operand, value)
+}
+
+// CondLe returns the condition that is true if the operand is less than or equal to value.
+func CondLe(operand CondOperand, value any) Cond {
+	return compare(opLe, operand, value)
+}
+
+// CondAnd returns the condition that is true if all conds are true.
+func CondAnd(conds ...Cond) Cond {
+	return Cond{kind: condAnd, conds: conds, err: condsError(conds)}
+}
+
+// CondOr returns the condition that is true if any of conds is true.
+func CondOr(conds ...Cond) Cond {
+	return Cond{kind: condOr, conds: conds, err: condsError(conds)}
+}
+
+// CondNot returns the condition negating cond.
+func CondNot(cond Cond) Cond {
+	return Cond{kind: condNot, conds: []Cond{cond}, err: cond.err}
+}
+
+// CondRegex returns the condition that is true if the string bin matches the POSIX extended regular expression.
+// Only ExpRegexFlagICASE and ExpRegexFlagNEWLINE flags change the local evaluation.
+// Locally the pattern is compiled with the regexp package, so it must use the syntax both engines share.
+func CondRegex(bin, pattern string, flags aerospike.ExpRegexFlags) Cond {
+	prefix := "(?s)"
+	if flags&aerospike.ExpRegexFlagNEWLINE != 0 {
+		prefix = "(?m)"
+	}
+	if flags&aerospike.ExpRegexFlagICASE != 0 {
+		prefix += "(?i)"
+	}
+	re, err := regexp.Compile(prefix + pattern)
+	if err != nil {
+		return Cond{err: fmt.Errorf("%w: %w", errInvalidCond, err)}
+	}
+
+	return Cond{
+		kind:    condRegex,
+		operand: CondBin(bin),
+		value:   pattern,
+		regex:   re,
+		flags:   flags | aerospike.ExpRegexFlagEXTENDED,
+	}
+}
+
+// CondListContains returns the condition that is true if the list bin has an element equal to value.
+// Like on the server, values of different types are never equal, e.g. 1 and 1.0.
+func CondListContains(bin string, value any) Cond {
+	return contains(condListContains, bin, value)
+}
+
+// CondMapContainsKey returns the condition that is true if the map bin has the key.
+func CondMapContainsKey(bin string, key any) Cond {
+	return contains(condMapContainsKey, bin, key)
+}
+
+// CondMapContainsValue returns the condition that is true if the map bin has a value equal to value.
+func CondMapContainsValue(bin string, value any) Cond {
+	return contains(condMapContainsValue, bin, value)
+}
+
+// CondBinExists returns the condition that is true if the record has the bin.
+func CondBinExists(bin string) Cond {
+	return Cond{kind: condBinExists, operand: CondBin(bin)}
+}
+
+func compare(op compareOp, operand CondOperand, value any) Cond {
+	cond := Cond{kind: condCompare, op: op, operand: operand}
+	switch operand.kind {
+	case operandTTL:
+		if d, ok := value.(time.Duration); ok {
+			value = int64(d / time.Second)
+		}
+	case operandLastUpdate:
+		if t, ok := value.(time.Time); ok {
+			value = t.UnixNano()
+		}
+	default:
+	}
+
+	cond.value = defaultCodec.normalizeValue(value)
+	switch rank := valueRank(cond.value); {
+	case rank == rankNil:
+		cond.err = fmt.Errorf("%s compared with nil, use CondBinExists: %w", operand, errInvalidCond)
+	case operand.kind != operandBin && rank != rankInt:
+		cond.err = fmt.Errorf("%s compared with %T: %w", operand, value, errTypeMismatch)
+	case rank == rankMap && op != opEq && op != opNotEq:
+		cond.err = fmt.Errorf("%s: maps can only be compared for equality: %w", operand, errInvalidCond)
+	case rank == rankGeoJSON:
+		cond.err = fmt.Errorf("%s: GeoJSON values cannot be compared: %w", operand, errInvalidCond)
+	}
+
+	return cond
+}
+
+func contains(kind condKind, bin string, value any) Cond {
+	cond := Cond{kind: kind, operand: CondBin(bin), value: defaultCodec.normalizeValue(value)}
+	if valueRank(cond.value) == rankGeoJSON {
+		cond.err = fmt.Errorf("bin %s: GeoJSON values cannot be compared: %w", bin, errInvalidCond)
+	}
+
+	return cond
+}
+
+func condsError(conds []Cond) error {
+	for _, cond := range conds {
+		if cond.err != nil {
+			return cond.err
+		}
+	}
+	if len(conds) == 0 {
+		return fmt.Errorf("no conditions to combine: %w", errInvalidCond)
+	}
+
+	return nil
+}
+
+// Expression compiles the condition into an aerospike filter expression.
+func (c Cond) Expression() (*aerospike.Expression, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	switch c.kind {
+	case condAnd, condOr:
+		exps := make([]*aerospike.Expression, len(c.conds))
+		for i, cond := range c.conds {
+			exps[i], _ = cond.Expression()
+		}
+		if c.kind == condAnd {
+			return aerospike.ExpAnd(exps...), nil
+		}
+		return aerospike.ExpOr(exps...), nil
+	case condNot:
+		exp, _ := c.conds[0].Expression()
+		return aerospike.ExpNot(exp), nil
+	case condRegex:
+		return aerospike.ExpRegexCompare(c.value.(string), c.flags, aerospike.ExpStringBin(c.operand.bin)), nil //nolint:forcetypeassert
+	case condListContains:
+		count := aerospike.ExpListGetByValue(
+			aerospike.ListReturnTypeCount, constantExpression(c.value), aerospike.ExpListBin(c.operand.bin))
+		return aerospike.ExpGreater(count, aerospike.ExpIntVal(0)), nil
+	case condMapContainsKey:
+		count := aerospike.ExpMapGetByKey(aerospike.MapReturnType.COUNT, aerospike.ExpTypeINT,
+			constantExpression(c.value), aerospike.ExpMapBin(c.operand.bin))
+		return aerospike.ExpGreater(count, aerospike.ExpIntVal(0)), nil
+	case condMapContainsValue:
+		count := aerospike.ExpMapGetByValue(
+			aerospike.MapReturnType.COUNT, constantExpression(c.value), aerospike.ExpMapBin(c.operand.bin))
+		return aerospike.ExpGreater(count, aerospike.ExpIntVal(0)), nil
+	case condBinExists:
+		return aerospike.ExpBinExists(c.operand.bin), nil
+	default:
+		return compareExpression(c.op, c.operandExpression(), constantExpression(c.value)), nil
+	}
+}
+
+func (c Cond) operandExpression() *aerospike.Expression {
+	switch c.operand.kind {
+	case operandTTL:
+		return aerospike.ExpTTL()
+	case operandLastUpdate:
+		return aerospike.ExpLastUpdate()
+	default:
+	}
+
+	bin := c.operand.bin
+	switch c.value.(type) {
+	case bool:
+		return aerospike.ExpBoolBin(bin)
+	case int64:
+		return aerospike.ExpIntBin(bin)
+	case float64:
+		return aerospike.ExpFloatBin(bin)
+	case string:
+		return aerospike.ExpStringBin(bin)
+	case []byte:
+		return aerospike.ExpBlobBin(bin)
+	case []any:
+		return aerospike.ExpListBin(bin)
+	default:
+		return aerospike.ExpMapBin(bin)
+	}
+}
+
+func compareExpression(op compareOp, left, right *aerospike.Expression) *aerospike.Expression {
+	switch op {
+	case opNotEq:
+		return aerospike.ExpNotEq(left, right)
+	case opGt:
+		return aerospike.ExpGreater(left, right)
+	case opGe:
+		return aerospike.ExpGreaterEq(left, right)
+	case opLt:
+		return aerospike.ExpLess(left, right)
+	case opLe:
+		return aerospike.ExpLessEq(left, right)
+	default:
+		return aerospike.ExpEq(left, right)
+	}
+}
+
+// constantExpression returns the expression of the normalized value.
+func constantExpression(v any) *aerospike.Expression {
+	switch val := v.(type) {
+	case bool:
+		return aerospike.ExpBoolVal(val)
+	case int64:
+		return aerospike.ExpIntVal(val)
+	case float64:
+		return aerospike.ExpFloatVal(val)
+	case string:
+		return aerospike.ExpStringVal(val)
+	case []byte:
+		return aerospike.ExpBlobVal(val)
+	case []any:
+		values := make([]aerospike.Value, len(val))
+		for i := range val {
+			values[i] = aerospike.NewValue(val[i])
+		}
+		return aerospike.ExpListVal(values...)
+	case map[any]any:
+		return aerospike.ExpMapVal(val)
+	default:
+		return aerospike.ExpNilValue()
+	}
+}
+
+// MatchOption provides record metadata to Match.
+type MatchOption func(*recordMeta)
+
+// recordMeta is the metadata of the evaluated record, nil fields are unknown.
+type recordMeta struct {
+	ttl        *int64
+	lastUpdate *int64
+}
+
+// MatchTTL sets the remaining time-to-live of the record, a negative duration means it never expires.
+func MatchTTL(ttl time.Duration) MatchOption {
+	return func(m *recordMeta) {
+		seconds := int64(ttl / time.Second)
+		if ttl < 0 {
+			seconds = -1
+		}
+		m.ttl = &seconds
+	}
+}
+
+// MatchLastUpdate sets the time of the last update of the record.
+func MatchLastUpdate(t time.Time) MatchOption {
+	return func(m *recordMeta) {
+		nanos := t.UnixNano()
+		m.lastUpdate = &nanos
+	}
+}
+
+// Match evaluates the condition against v the way the server evaluates the compiled expression.
+// v is an aerospike.BinMap, an *aerospike.Record or a struct, or a pointer to it, that is converted with Marshal.
+// TTL is taken from records and TTL fields of structs, and can be set with MatchTTL.
+// Evaluating CondTTL or CondLastUpdate without them known returns an error.
+func (c Cond) Match(v any, opts ...MatchOption) (bool, error) {
+	if c.err != nil {
+		return false, c.err
+	}
+
+	var (
+		meta recordMeta
+		bins map[string]any
+	)
+	switch val := v.(type) {
+	case aerospike.BinMap:
+		bins = val
+	case map[string]any:
+		bins = val
+	case *aerospike.Record:
+		if val == nil {
+			return false, fmt.Errorf("record is nil: %w", errInputType)
+		}
+		bins = val.Bins
+		ttl := int64(val.Expiration)
+		if val.Expiration == math.MaxUint32 {
+			ttl = -1
+		}
+		meta.ttl = &ttl
+	default:
+		var err error
+		bins, meta.ttl, err = structBins(v)
+		if err != nil {
+			return false, err
+		}
+	}
+	for _, opt := range opts {
+		opt(&meta)
+	}
+
+	result, err := c.eval(bins, meta)
+	if err != nil {
+		return false, err
+	}
+
+	return result == triTrue, nil
+}
+
+// structBins marshals the struct and returns its TTL field in seconds, if it has one.
+func structBins(v any) (map[string]any, *int64, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Struct {
+		ptr := reflect.New(rv.Type())
+		ptr.Elem().Set(rv)
+		rv = ptr
+	}
+	bins, err := Marshal(rv.Interface())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	meta := findMetaFields(rv.Type().Elem())
+	if meta.ttl < 0 {
+		return bins, nil, nil
+	}
+	ttl := int64(int32(meta.expirationOf(rv.Elem()))) //nolint:gosec
+
+	return bins, &ttl, nil
+}
+
+// tri is the result of a condition in the three-valued logic of the server.
+type tri int
+
+const (
+	triFalse tri = iota
+	triTrue
+	triUnknown
+)
+
+func toTri(b bool) tri {
+	if b {
+		return triTrue
+	}
+
+	return triFalse
+}
+
+func (c Cond) eval(bins map[string]any, meta recordMeta) (tri, error) {
+	switch c.kind {
+	case condAnd, condOr:
+		return c.evalLogical(bins, meta)
+	case condNot:
+		result, err := c.conds[0].eval(bins, meta)
+		if err != nil || result == triUnknown {
+			return result, err
+		}
+		return toTri(result == triFalse), nil
+	case condBinExists:
+		val, ok := bins[c.operand.bin]
+		return toTri(ok && val != nil), nil
+	case condCompare:
+		return c.evalCompare(bins, meta)
+	default:
+	}
+
+	bin := defaultCodec.normalizeValue(bins[c.operand.bin])
+	switch c.kind {
+	case condRegex:
+		s, ok := bin.(string)
+		if !ok {
+			return triUnknown, nil
+		}
+		return toTri(c.regex.MatchString(s)), nil
+	case condListContains:
+		list, ok := bin.([]any)
+		if !ok {
+			return triUnknown, nil
+		}
+		for _, elem := range list {
+			if compareValues(elem, c.value) == 0 {
+				return triTrue, nil
+			}
+		}
+		return triFalse, nil
+	default:
+		m, ok := bin.(map[any]any)
+		if !ok {
+			return triUnknown, nil
+		}
+		for key, val := range m {
+			if c.kind == condMapContainsKey && compareValues(key, c.value) == 0 ||
+				c.kind == condMapContainsValue && compareValues(val, c.value) == 0 {
+				return triTrue, nil
+			}
+		}
+		return triFalse, nil
+	}
+}
+
+// evalLogical evaluates CondAnd and CondOr: a false operand makes CondAnd false and a true one makes CondOr true,
+// otherwise any unknown operand makes the result unknown.
+func (c Cond) evalLogical(bins map[string]any, meta recordMeta) (tri, error) {
+	decisive := triFalse
+	if c.kind == condOr {
+		decisive = triTrue
+	}
+
+	result := toTri(c.kind == condAnd)
+	for _, cond := range c.conds {
+		r, err := cond.eval(bins, meta)
+		if err != nil {
+			return triUnknown, err
+		}
+		switch r {
+		case decisive:
+			return decisive, nil
+		case triUnknown:
+			result = triUnknown
+		default:
+		}
+	}
+
+	return result, nil
+}
+
+func (c Cond) evalCompare(bins map[string]any, meta recordMeta) (tri, error) {
+	var left any
+	switch c.operand.kind {
+	case operandTTL:
+		if meta.ttl == nil {
+			return triUnknown, fmt.Errorf("ttl: %w", errUnknownMeta)
+		}
+		left = *meta.ttl
+	case operandLastUpdate:
+		if meta.lastUpdate == nil {
+			return triUnknown, fmt.Errorf("last update: %w", errUnknownMeta)
+		}
+		left = *meta.lastUpdate
+	default:
+		left = defaultCodec.normalizeValue(bins[c.operand.bin])
+	}
+	if valueRank(left) != valueRank(c.value) {
+		return triUnknown, nil
+	}
+
+	result := compareValues(left, c.value)
+	switch c.op {
+	case opNotEq:
+		return toTri(result != 0), nil
+	case opGt:
+		return toTri(result > 0), nil
+	case opGe:
+		return toTri(result >= 0), nil
+	case opLt:
+		return toTri(result < 0), nil
+	case opLe:
+		return toTri(result <= 0), nil
+	default:
+		return toTri(result == 0), nil
+	}
+}
@@ -0,0 +1,181 @@
+package aerospike
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCondMatch(t *testing.T) {
+	t.Parallel()
+	bins := aerospike.BinMap{
+		"age":   30,
+		"score": 1.5,
+		"name":  "Alice",
+		"tags":  []any{"go", 1},
+		"attrs": map[any]any{"color": "red", "size": 3},
+		"nil":   nil,
+	}
+
+	tests := []struct {
+		name string
+		cond Cond
+		want bool
+	}{
+		{name: "eq", cond: CondEq(CondBin("age"), 30), want: true},
+		{name: "eq other int type", cond: CondEq(CondBin("age"), uint8(30)), want: true},
+		{name: "not eq", cond: CondNotEq(CondBin("age"), 30)},
+		{name: "gt", cond: CondGt(CondBin("score"), 1.0), want: true},
+		{name: "le string", cond: CondLe(CondBin("name"), "Bob"), want: true},
+		{name: "int bin compared with float", cond: CondEq(CondBin("age"), 30.0)},
+		{name: "missing bin", cond: CondLt(CondBin("missing"), 1)},
+		{name: "not of missing bin is unknown", cond: CondNot(CondEq(CondBin("missing"), 1))},
+		{name: "not of type mismatch is unknown", cond: CondNot(CondEq(CondBin("name"), 1))},
+		{name: "not", cond: CondNot(CondEq(CondBin("age"), 1)), want: true},
+		{name: "and with false decides", cond: CondNot(CondAnd(CondEq(CondBin("missing"), 1), CondEq(CondBin("age"), 1))), want: true},
+		{name: "and with unknown", cond: CondAnd(CondEq(CondBin("missing"), 1), CondEq(CondBin("age"), 30))},
+		{name: "or with true decides", cond: CondOr(CondEq(CondBin("missing"), 1), CondEq(CondBin("age"), 30)), want: true},
+		{name: "or with unknown", cond: CondNot(CondOr(CondEq(CondBin("missing"), 1), CondEq(CondBin("age"), 1)))},
+		{name: "regex", cond: CondRegex("name", "^al", aerospike.ExpRegexFlagICASE), want: true},
+		{name: "regex case", cond: CondRegex("name", "^al", aerospike.ExpRegexFlagNONE)},
+		{name: "regex on int", cond: CondNot(CondRegex("age", "3", aerospike.ExpRegexFlagNONE))},
+		{name: "list contains", cond: CondListContains("tags", "go"), want: true},
+		{name: "list contains int", cond: CondListContains("tags", 1), want: true},
+		{name: "list does not contain float", cond: CondListContains("tags", 1.0)},
+		{name: "list contains on map", cond: CondNot(CondListContains("attrs", "go"))},
+		{name: "map contains key", cond: CondMapContainsKey("attrs", "color"), want: true},
+		{name: "map contains value", cond: CondMapContainsValue("attrs", 3), want: true},
+		{name: "map does not contain value", cond: CondNot(CondMapContainsValue("attrs", "blue")), want: true},
+		{name: "list equality", cond: CondEq(CondBin("tags"), []any{"go", 1}), want: true},
+		{name: "list ordering", cond: CondLt(CondBin("tags"), []any{"go", 2}), want: true},
+		{name: "map equality", cond: CondEq(CondBin("attrs"), map[string]any{"color": "red", "size": 3}), want: true},
+		{name: "bin exists", cond: CondBinExists("age"), want: true},
+		{name: "nil bin does not exist", cond: CondBinExists("nil")},
+		{name: "bin does not exist", cond: CondNot(CondBinExists("missing")), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := tt.cond.Match(bins)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+
+			_, err = tt.cond.Expression()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCondMatchMeta(t *testing.T) {
+	t.Parallel()
+	type withTTL struct {
+		Name      string        `as:"name"`
+		CreatedAt time.Time     `as:"created_at"`
+		TTL       time.Duration `as:",ttl"`
+	}
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("struct", func(t *testing.T) {
+		t.Parallel()
+		v := withTTL{Name: "a", CreatedAt: created, TTL: time.Hour}
+		got, err := CondAnd(CondEq(CondBin("name"), "a"), CondGt(CondBin("created_at"), created.Add(-time.Second)), CondGe(CondTTL(), time.Hour)).Match(v)
+		require.NoError(t, err)
+		require.True(t, got)
+
+		got, err = CondLt(CondTTL(), 60).Match(&v)
+		require.NoError(t, err)
+		require.False(t, got)
+	})
+	t.Run("record", func(t *testing.T) {
+		t.Parallel()
+		record := &aerospike.Record{Bins: aerospike.BinMap{"a": 1}, Expiration: math.MaxUint32}
+		got, err := CondEq(CondTTL(), -1).Match(record)
+		require.NoError(t, err)
+		require.True(t, got)
+	})
+	t.Run("last update", func(t *testing.T) {
+		t.Parallel()
+		got, err := CondLt(CondLastUpdate(), created).Match(aerospike.BinMap{}, MatchLastUpdate(created.Add(-time.Minute)))
+		require.NoError(t, err)
+		require.True(t, got)
+	})
+	t.Run("unknown ttl", func(t *testing.T) {
+		t.Parallel()
+		_, err := CondLt(CondTTL(), 60).Match(aerospike.BinMap{})
+		require.ErrorIs(t, err, errUnknownMeta)
+		got, err := CondLt(CondTTL(), 60).Match(aerospike.BinMap{}, MatchTTL(time.Second))
+		require.NoError(t, err)
+		require.True(t, got)
+	})
+}
+
+func TestCondErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		cond Cond
+		want error
+	}{
+		{name: "nil value", cond: CondEq(CondBin("a"), nil), want: errInvalidCond},
+		{name: "ttl with string", cond: CondEq(CondTTL(), "1h"), want: errTypeMismatch},
+		{name: "map ordering", cond: CondGt(CondBin("a"), map[string]int{}), want: errInvalidCond},
+		{name: "bad regex", cond: CondRegex("a", "(", aerospike.ExpRegexFlagNONE), want: errInvalidCond},
+		{name: "empty and", cond: CondAnd(), want: errInvalidCond},
+		{name: "propagated", cond: CondOr(CondEq(CondBin("a"), 1), CondNot(CondEq(CondBin("a"), nil))), want: errInvalidCond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := tt.cond.Expression()
+			require.ErrorIs(t, err, tt.want)
+			_, err = tt.cond.Match(aerospike.BinMap{})
+			require.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestCondExpression(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		cond Cond
+		want *aerospike.Expression
+	}{
+		{
+			name: "comparison",
+			cond: CondAnd(CondGe(CondBin("age"), 18), CondNot(CondEq(CondBin("name"), "bob")), CondLt(CondTTL(), time.Minute)),
+			want: aerospike.ExpAnd(
+				aerospike.ExpGreaterEq(aerospike.ExpIntBin("age"), aerospike.ExpIntVal(18)),
+				aerospike.ExpNot(aerospike.ExpEq(aerospike.ExpStringBin("name"), aerospike.ExpStringVal("bob"))),
+				aerospike.ExpLess(aerospike.ExpTTL(), aerospike.ExpIntVal(60)),
+			),
+		},
+		{
+			name: "regex",
+			cond: CondRegex("name", "^a", aerospike.ExpRegexFlagICASE),
+			want: aerospike.ExpRegexCompare("^a",
+				aerospike.ExpRegexFlagICASE|aerospike.ExpRegexFlagEXTENDED, aerospike.ExpStringBin("name")),
+		},
+		{
+			name: "list contains",
+			cond: CondOr(CondListContains("tags", "go"), CondBinExists("x")),
+			want: aerospike.ExpOr(
+				aerospike.ExpGreater(
+					aerospike.ExpListGetByValue(aerospike.ListReturnTypeCount, aerospike.ExpStringVal("go"), aerospike.ExpListBin("tags")),
+					aerospike.ExpIntVal(0)),
+				aerospike.ExpBinExists("x"),
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := tt.cond.Expression()
+			require.NoError(t, err)
+			requireSameExpression(t, tt.want, got)
+		})
+	}
+}
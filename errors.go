@@ -0,0 +1,74 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/aerospike/aerospike-client-go/v8/types"
+)
+
+var (
+	// ErrAlreadyExists is returned when a record is created under a key that is already taken.
+	ErrAlreadyExists = errors.New("record already exists")
+	// ErrNotFound is returned when a record that must exist is missing.
+	ErrNotFound = errors.New("record not found")
+	// ErrConflict is returned when a record was modified concurrently
+	// and its generation differs from the expected one.
+	ErrConflict = errors.New("record generation conflict")
+	// ErrFilteredOut is returned when a command was not applied to a record
+	// because the filter expression of the policy evaluated to false.
+	ErrFilteredOut = errors.New("record filtered out")
+	// ErrDecode is returned when a record cannot be unmarshaled into the target type.
+	ErrDecode = errors.New("failed to decode record")
+)
+
+// mapError wraps aerospike errors with the matching sentinel error,
+// keeping the original error available to errors.As.
+func mapError(err aerospike.Error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch {
+	case err.Matches(types.KEY_EXISTS_ERROR):
+		return fmt.Errorf("%w: %w", ErrAlreadyExists, err)
+	case err.Matches(types.KEY_NOT_FOUND_ERROR):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case err.Matches(types.GENERATION_ERROR):
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	case err.Matches(types.FILTERED_OUT):
+		return fmt.Errorf("%w: %w", ErrFilteredOut, err)
+	default:
+		return err
+	}
+}
+
+// IsRetryable reports whether the command failed because of a transient condition,
+// such as a timeout, a network failure or an overloaded server, and may succeed if repeated as is.
+func IsRetryable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	ae := &aerospike.AerospikeError{}
+	if !errors.As(err, &ae) {
+		return false
+	}
+
+	return ae.Matches(
+		types.TIMEOUT,
+		types.NETWORK_ERROR,
+		types.NO_RESPONSE,
+		types.MAX_RETRIES_EXCEEDED,
+		types.SERVER_NOT_AVAILABLE,
+		types.NO_AVAILABLE_CONNECTIONS_TO_NODE,
+		types.PARTITION_UNAVAILABLE,
+		types.KEY_BUSY,
+		types.XDR_KEY_BUSY,
+		types.DEVICE_OVERLOAD,
+		types.SERVER_MEM_ERROR,
+		types.QUERY_TIMEOUT,
+	)
+}
@@ -0,0 +1,73 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/aerospike/aerospike-client-go/v8/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		code types.ResultCode
+		want error
+	}{
+		{name: "key exists", code: types.KEY_EXISTS_ERROR, want: ErrAlreadyExists},
+		{name: "key not found", code: types.KEY_NOT_FOUND_ERROR, want: ErrNotFound},
+		{name: "generation", code: types.GENERATION_ERROR, want: ErrConflict},
+		{name: "filtered out", code: types.FILTERED_OUT, want: ErrFilteredOut},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := mapError(&aerospike.AerospikeError{ResultCode: tt.code})
+			require.ErrorIs(t, err, tt.want)
+
+			var aerr *aerospike.AerospikeError
+			require.ErrorAs(t, err, &aerr)
+			require.Equal(t, tt.code, aerr.ResultCode)
+		})
+	}
+
+	t.Run("other errors are kept as is", func(t *testing.T) {
+		t.Parallel()
+		aerr := &aerospike.AerospikeError{ResultCode: types.TIMEOUT}
+		err := mapError(aerr)
+		require.Equal(t, aerr, err)
+		require.False(t, errors.Is(err, ErrNotFound))
+	})
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+		require.NoError(t, mapError(nil))
+	})
+}
+
+func TestIsRetryable(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		in   error
+		want bool
+	}{
+		{name: "timeout", in: &aerospike.AerospikeError{ResultCode: types.TIMEOUT}, want: true},
+		{name: "device overload", in: &aerospike.AerospikeError{ResultCode: types.DEVICE_OVERLOAD}, want: true},
+		{name: "wrapped key busy", in: fmt.Errorf("put: %w", &aerospike.AerospikeError{ResultCode: types.KEY_BUSY}), want: true},
+		{name: "context deadline", in: context.DeadlineExceeded, want: true},
+		{name: "generation", in: mapError(&aerospike.AerospikeError{ResultCode: types.GENERATION_ERROR})},
+		{name: "parameter", in: &aerospike.AerospikeError{ResultCode: types.PARAMETER_ERROR}},
+		{name: "context canceled", in: context.Canceled},
+		{name: "other", in: errInputType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, IsRetryable(tt.in))
+		})
+	}
+}
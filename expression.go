@@ -0,0 +1,573 @@
+package aerospike
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+)
+
+var (
+	errTypeMismatch = errors.New("type mismatch")
+	errInvalidExpr  = errors.New("invalid expression")
+)
+
+// Expr is a filter expression built by ExpBuilder. Errors of the expressions it is made of
+// are kept until Build is called.
+type Expr struct {
+	exp *aerospike.Expression
+	err error
+}
+
+// Build returns the aerospike expression, or the first error encountered while building it.
+// The zero Expr fails to build.
+func (e Expr) Build() (*aerospike.Expression, error) {
+	if err := e.check(); err != nil {
+		return nil, err
+	}
+
+	return e.exp, nil
+}
+
+// check returns the error of the expression, or an error if it is the zero Expr.
+func (e Expr) check() error {
+	switch {
+	case e.err != nil:
+		return e.err
+	case e.exp == nil:
+		return fmt.Errorf("empty expression: %w", errInvalidExpr)
+	default:
+		return nil
+	}
+}
+
+// ExpBuilder builds filter expressions and secondary index filters over fields of T.
+// Fields are addressed by paths like the ones of ProjectOps: a bin name followed by nested struct
+// tags, map keys and list indexes, e.g. "profile.address.city" or "scores.math".
+// Particle types and values are taken from the way the codec stores the fields, so comparing
+// a field with a value of another type, e.g. a time.Time field with a string, fails.
+type ExpBuilder[T any] struct {
+	codec *Codec
+	typ   reflect.Type
+}
+
+// NewExpBuilder returns a builder of expressions over fields of T stored with the default codec.
+func NewExpBuilder[T any]() (*ExpBuilder[T], error) {
+	return NewCodecExpBuilder[T](defaultCodec)
+}
+
+// NewCodecExpBuilder returns a builder of expressions over fields of T stored with the codec.
+func NewCodecExpBuilder[T any](c *Codec) (*ExpBuilder[T], error) {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expression target must be a struct: %w", errInputType)
+	}
+	if c == nil {
+		return nil, fmt.Errorf("codec must not be nil: %w", errInputType)
+	}
+
+	return &ExpBuilder[T]{codec: c, typ: typ}, nil
+}
+
+// expValue is a value addressed by a path, described the way the codec stores it.
+type expValue struct {
+	schema ValueSchema
+	typ    reflect.Type
+}
+
+// elem returns the list elements or map values of the value.
+func (v expValue) elem() expValue {
+	return expValue{schema: *v.schema.Elem, typ: derefType(v.typ).Elem()}
+}
+
+// key returns the map keys of the value.
+func (v expValue) key() expValue {
+	return expValue{schema: *v.schema.Key, typ: derefType(v.typ).Key()}
+}
+
+func (v expValue) isMap() bool {
+	return v.schema.Type == ParticleMap && v.schema.Key != nil
+}
+
+// Field returns the expression reading the field.
+func (b *ExpBuilder[T]) Field(field string) Expr {
+	steps, v, err := b.resolve(field)
+	if err != nil {
+		return Expr{err: err}
+	}
+
+	return Expr{exp: b.fieldExpression(steps, v.schema.Type)}
+}
+
+// Eq returns the expression checking that the field equals value.
+func (b *ExpBuilder[T]) Eq(field string, value any) Expr {
+	return b.compare(field, value, aerospike.ExpEq)
+}
+
+// NotEq returns the expression checking that the field does not equal value.
+func (b *ExpBuilder[T]) NotEq(field string, value any) Expr {
+	return b.compare(field, value, aerospike.ExpNotEq)
+}
+
+// Gt returns the expression checking that the field is greater than value.
+func (b *ExpBuilder[T]) Gt(field string, value any) Expr {
+	return b.compare(field, value, aerospike.ExpGreater)
+}
+
+// Ge returns the expression checking that the field is greater than or equal to value.
+func (b *ExpBuilder[T]) Ge(field string, value any) Expr {
+	return b.compare(field, value, aerospike.ExpGreaterEq)
+}
+
+// Lt returns the expression checking that the field is less than value.
+func (b *ExpBuilder[T]) Lt(field string, value any) Expr {
+	return b.compare(field, value, aerospike.ExpLess)
+}
+
+// Le returns the expression checking that the field is less than or equal to value.
+func (b *ExpBuilder[T]) Le(field string, value any) Expr {
+	return b.compare(field, value, aerospike.ExpLessEq)
+}
+
+// Contains returns the expression checking that the list field has the element
+// or the map field has the value.
+func (b *ExpBuilder[T]) Contains(field string, value any) Expr {
+	steps, v, err := b.resolve(field)
+	if err != nil {
+		return Expr{err: err}
+	}
+	if v.schema.Type != ParticleList && !v.isMap() {
+		return Expr{err: fmt.Errorf("%s is %s, not a list or a map: %w", field, v.schema.GoType, errTypeMismatch)}
+	}
+	elem, err := b.valueExpression(v.elem(), value)
+	if err != nil {
+		return Expr{err: fmt.Errorf("%s: %w", field, err)}
+	}
+
+	container := b.fieldExpression(steps, v.schema.Type)
+	count := aerospike.ExpMapGetByValue(aerospike.MapReturnType.COUNT, elem, container)
+	if v.schema.Type == ParticleList {
+		count = aerospike.ExpListGetByValue(aerospike.ListReturnTypeCount, elem, container)
+	}
+
+	return Expr{exp: aerospike.ExpGreater(count, aerospike.ExpIntVal(0))}
+}
+
+// ContainsKey returns the expression checking that the map field has the key.
+func (b *ExpBuilder[T]) ContainsKey(field string, key any) Expr {
+	steps, v, err := b.resolve(field)
+	if err != nil {
+		return Expr{err: err}
+	}
+	if !v.isMap() {
+		return Expr{err: fmt.Errorf("%s is not a map: %w", field, errTypeMismatch)}
+	}
+	keyExp, err := b.valueExpression(v.key(), key)
+	if err != nil {
+		return Expr{err: fmt.Errorf("%s: %w", field, err)}
+	}
+
+	count := aerospike.ExpMapGetByKey(
+		aerospike.MapReturnType.COUNT, aerospike.ExpTypeINT, keyExp, b.fieldExpression(steps, v.schema.Type))
+
+	return Expr{exp: aerospike.ExpGreater(count, aerospike.ExpIntVal(0))}
+}
+
+// Exists returns the expression checking that the bin exists.
+func (b *ExpBuilder[T]) Exists(field string) Expr {
+	steps, _, err := b.resolve(field)
+	if err != nil {
+		return Expr{err: err}
+	}
+	if len(steps) > 1 {
+		return Expr{err: fmt.Errorf("%s is not a bin: %w", field, errInvalidPath)}
+	}
+
+	return Expr{exp: aerospike.ExpBinExists(steps[0].name)}
+}
+
+// And returns the expression that is true if all exps are true.
+func (b *ExpBuilder[T]) And(exps ...Expr) Expr {
+	return combine(exps, aerospike.ExpAnd)
+}
+
+// Or returns the expression that is true if any of exps is true.
+func (b *ExpBuilder[T]) Or(exps ...Expr) Expr {
+	return combine(exps, aerospike.ExpOr)
+}
+
+// Not returns the expression negating exp.
+func (b *ExpBuilder[T]) Not(exp Expr) Expr {
+	if err := exp.check(); err != nil {
+		return Expr{err: err}
+	}
+
+	return Expr{exp: aerospike.ExpNot(exp.exp)}
+}
+
+// FilterEq returns the secondary index filter selecting records whose field stored as an integer
+// or a string equals value.
+func (b *ExpBuilder[T]) FilterEq(field string, value any) (*aerospike.Filter, error) {
+	steps, v, err := b.resolve(field)
+	if err != nil {
+		return nil, err
+	}
+	val, err := b.filterValue(v, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", field, err)
+	}
+
+	return aerospike.NewEqualFilter(steps[0].name, val, filterContext(steps)...), nil
+}
+
+// FilterRange returns the secondary index filter selecting records whose field stored as an integer,
+// e.g. a time stored as Unix seconds, is between begin and end inclusive.
+func (b *ExpBuilder[T]) FilterRange(field string, begin, end any) (*aerospike.Filter, error) {
+	steps, v, err := b.resolve(field)
+	if err != nil {
+		return nil, err
+	}
+	beginVal, endVal, err := b.filterRange(v, begin, end)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", field, err)
+	}
+
+	return aerospike.NewRangeFilter(steps[0].name, beginVal, endVal, filterContext(steps)...), nil
+}
+
+// FilterContains returns the secondary index filter selecting records whose list field has the element
+// or map field has the value. The field must be indexed with the "list" or "mapvalues" collection type.
+func (b *ExpBuilder[T]) FilterContains(field string, value any) (*aerospike.Filter, error) {
+	steps, collection, elem, err := b.resolveCollection(field, aerospike.ICT_MAPVALUES)
+	if err != nil {
+		return nil, err
+	}
+	val, err := b.filterValue(elem, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", field, err)
+	}
+
+	return aerospike.NewContainsFilter(steps[0].name, collection, val, filterContext(steps)...), nil
+}
+
+// FilterContainsKey returns the secondary index filter selecting records whose map field has the key.
+// The field must be indexed with the "mapkeys" collection type.
+func (b *ExpBuilder[T]) FilterContainsKey(field string, key any) (*aerospike.Filter, error) {
+	steps, collection, keyValue, err := b.resolveCollection(field, aerospike.ICT_MAPKEYS)
+	if err != nil {
+		return nil, err
+	}
+	if collection != aerospike.ICT_MAPKEYS {
+		return nil, fmt.Errorf("%s is not a map: %w", field, errTypeMismatch)
+	}
+	val, err := b.filterValue(keyValue, key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", field, err)
+	}
+
+	return aerospike.NewContainsFilter(steps[0].name, collection, val, filterContext(steps)...), nil
+}
+
+// FilterContainsRange returns the secondary index filter selecting records whose list field has an element
+// or map field has a value between begin and end inclusive.
+func (b *ExpBuilder[T]) FilterContainsRange(field string, begin, end any) (*aerospike.Filter, error) {
+	steps, collection, elem, err := b.resolveCollection(field, aerospike.ICT_MAPVALUES)
+	if err != nil {
+		return nil, err
+	}
+	beginVal, endVal, err := b.filterRange(elem, begin, end)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", field, err)
+	}
+
+	return aerospike.NewContainsRangeFilter(steps[0].name, collection, beginVal, endVal, filterContext(steps)...), nil
+}
+
+func (b *ExpBuilder[T]) compare(
+	field string,
+	value any,
+	op func(left, right *aerospike.Expression) *aerospike.Expression,
+) Expr {
+	steps, v, err := b.resolve(field)
+	if err != nil {
+		return Expr{err: err}
+	}
+	val, err := b.valueExpression(v, value)
+	if err != nil {
+		return Expr{err: fmt.Errorf("%s: %w", field, err)}
+	}
+
+	return Expr{exp: op(b.fieldExpression(steps, v.schema.Type), val)}
+}
+
+// resolve resolves the field path into projection steps and the description of the addressed value.
+func (b *ExpBuilder[T]) resolve(field string) ([]projectionStep, expValue, error) {
+	if b == nil || b.typ == nil {
+		return nil, expValue{}, fmt.Errorf("builder is not initialized: %w", errInputType)
+	}
+
+	steps, err := b.codec.resolvePath(b.typ, field)
+	if err != nil {
+		return nil, expValue{}, err
+	}
+	v := b.describe(steps[len(steps)-1].typ)
+	if v.schema.Type == "" {
+		return nil, expValue{}, fmt.Errorf("%s has unsupported type %s: %w", field, v.typ, errInputType)
+	}
+
+	return steps, v, nil
+}
+
+func (b *ExpBuilder[T]) describe(typ reflect.Type) expValue {
+	return expValue{schema: b.codec.describeValue(typ, map[reflect.Type]bool{}), typ: typ}
+}
+
+// resolveCollection resolves the list or map field for a secondary index filter.
+// Maps are resolved with the given collection type, elem describes list elements, map keys or map values.
+func (b *ExpBuilder[T]) resolveCollection(
+	field string,
+	mapCollection aerospike.IndexCollectionType,
+) ([]projectionStep, aerospike.IndexCollectionType, expValue, error) {
+	steps, v, err := b.resolve(field)
+	if err != nil {
+		return nil, aerospike.ICT_DEFAULT, expValue{}, err
+	}
+
+	switch {
+	case v.schema.Type == ParticleList:
+		return steps, aerospike.ICT_LIST, v.elem(), nil
+	case v.isMap() && mapCollection == aerospike.ICT_MAPKEYS:
+		return steps, mapCollection, v.key(), nil
+	case v.isMap():
+		return steps, mapCollection, v.elem(), nil
+	default:
+		return nil, aerospike.ICT_DEFAULT, expValue{},
+			fmt.Errorf("%s is %s, not a list or a map: %w", field, v.schema.GoType, errTypeMismatch)
+	}
+}
+
+// fieldExpression returns the expression reading the value of the particle type addressed by steps.
+// Nested values are read from the map or list bin with CDT contexts built from the intermediate steps.
+func (b *ExpBuilder[T]) fieldExpression(steps []projectionStep, particle ParticleType) *aerospike.Expression {
+	bin := steps[0].name
+	if len(steps) == 1 {
+		return binExpression(bin, particle)
+	}
+
+	container := binExpression(bin, b.describe(steps[0].typ).schema.Type)
+	ctx := make([]*aerospike.CDTContext, 0, len(steps)-2)
+	for _, step := range steps[1 : len(steps)-1] {
+		ctx = append(ctx, stepContext(step))
+	}
+
+	last := steps[len(steps)-1]
+	if last.kind == stepListIndex {
+		return aerospike.ExpListGetByIndex(
+			aerospike.ListReturnTypeValue, expType(particle), aerospike.ExpIntVal(int64(last.index)), container, ctx...)
+	}
+
+	key := aerospike.ExpStringVal(last.name)
+	if last.kind == stepMapKey {
+		if k, ok := last.mapKey.(string); ok {
+			key = aerospike.ExpStringVal(k)
+		} else {
+			key = aerospike.ExpIntVal(integerValue(reflect.ValueOf(last.mapKey)))
+		}
+	}
+
+	return aerospike.ExpMapGetByKey(aerospike.MapReturnType.VALUE, expType(particle), key, container, ctx...)
+}
+
+// valueExpression converts value into the expression of the value stored the way the codec stores v.
+func (b *ExpBuilder[T]) valueExpression(v expValue, value any) (*aerospike.Expression, error) {
+	encoded, err := b.encode(v, value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e := encoded.(type) {
+	case int64:
+		return aerospike.ExpIntVal(e), nil
+	case float64:
+		return aerospike.ExpFloatVal(e), nil
+	case string:
+		return aerospike.ExpStringVal(e), nil
+	case bool:
+		return aerospike.ExpBoolVal(e), nil
+	case []byte:
+		return aerospike.ExpBlobVal(e), nil
+	default:
+		return nil, fmt.Errorf("%s values cannot be compared: %w", v.schema.Type, errTypeMismatch)
+	}
+}
+
+// filterValue converts value into the integer or string accepted by secondary index filters.
+func (b *ExpBuilder[T]) filterValue(v expValue, value any) (any, error) {
+	if v.schema.Type != ParticleInteger && v.schema.Type != ParticleString {
+		return nil, fmt.Errorf("%s values cannot be indexed: %w", v.schema.Type, errTypeMismatch)
+	}
+	encoded, err := b.encode(v, value)
+	if err != nil {
+		return nil, err
+	}
+	switch encoded.(type) {
+	case int64, string:
+		return encoded, nil
+	default:
+		return nil, fmt.Errorf("%T values cannot be indexed: %w", encoded, errTypeMismatch)
+	}
+}
+
+func (b *ExpBuilder[T]) filterRange(v expValue, begin, end any) (int64, int64, error) {
+	if v.schema.Type != ParticleInteger {
+		return 0, 0, fmt.Errorf("range of %s values: %w", v.schema.Type, errTypeMismatch)
+	}
+	beginVal, err := b.filterValue(v, begin)
+	if err != nil {
+		return 0, 0, err
+	}
+	endVal, err := b.filterValue(v, end)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return beginVal.(int64), endVal.(int64), nil //nolint:forcetypeassert
+}
+
+// encode converts value into the scalar the codec stores for v. Values of fields with an encoding,
+// e.g. times and registered types, must be of the field type, integers are accepted for float fields.
+func (b *ExpBuilder[T]) encode(v expValue, value any) (any, error) {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	mismatch := fmt.Errorf("cannot compare %s with %T: %w", v.schema.GoType, value, errTypeMismatch)
+
+	if v.schema.Encoding != "" {
+		if !rv.IsValid() || rv.Type() != derefType(v.typ) {
+			return nil, mismatch
+		}
+		encoded, err := b.codec.convertValue(rv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %T: %w", value, err)
+		}
+		return scalarValue(reflect.ValueOf(encoded)), nil
+	}
+
+	switch {
+	case v.schema.Type == ParticleInteger && isIntegerKind(rv.Kind()):
+		return integerValue(rv), nil
+	case v.schema.Type == ParticleFloat && (rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64):
+		return rv.Float(), nil
+	case v.schema.Type == ParticleFloat && isIntegerKind(rv.Kind()):
+		return float64(integerValue(rv)), nil
+	case v.schema.Type == ParticleString && rv.Kind() == reflect.String:
+		return rv.String(), nil
+	case v.schema.Type == ParticleBool && rv.Kind() == reflect.Bool:
+		return rv.Bool(), nil
+	default:
+		return nil, mismatch
+	}
+}
+
+// scalarValue converts an encoded value into int64, float64, string or bool,
+// returning other values as they are.
+func scalarValue(v reflect.Value) any {
+	switch {
+	case !v.IsValid():
+		return nil
+	case isIntegerKind(v.Kind()):
+		return integerValue(v)
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		return v.Float()
+	case v.Kind() == reflect.String:
+		return v.String()
+	case v.Kind() == reflect.Bool:
+		return v.Bool()
+	default:
+		return v.Interface()
+	}
+}
+
+func expType(particle ParticleType) aerospike.ExpType {
+	switch particle {
+	case ParticleBool:
+		return aerospike.ExpTypeBOOL
+	case ParticleInteger:
+		return aerospike.ExpTypeINT
+	case ParticleFloat:
+		return aerospike.ExpTypeFLOAT
+	case ParticleString:
+		return aerospike.ExpTypeSTRING
+	case ParticleBlob:
+		return aerospike.ExpTypeBLOB
+	case ParticleList:
+		return aerospike.ExpTypeLIST
+	case ParticleMap:
+		return aerospike.ExpTypeMAP
+	case ParticleGeoJSON:
+		return aerospike.ExpTypeGEO
+	case ParticleHLL:
+		return aerospike.ExpTypeHLL
+	default:
+		return aerospike.ExpTypeNIL
+	}
+}
+
+func binExpression(bin string, particle ParticleType) *aerospike.Expression {
+	switch particle {
+	case ParticleBool:
+		return aerospike.ExpBoolBin(bin)
+	case ParticleInteger:
+		return aerospike.ExpIntBin(bin)
+	case ParticleFloat:
+		return aerospike.ExpFloatBin(bin)
+	case ParticleString:
+		return aerospike.ExpStringBin(bin)
+	case ParticleBlob:
+		return aerospike.ExpBlobBin(bin)
+	case ParticleList:
+		return aerospike.ExpListBin(bin)
+	case ParticleGeoJSON:
+		return aerospike.ExpGeoBin(bin)
+	case ParticleHLL:
+		return aerospike.ExpHLLBin(bin)
+	default:
+		return aerospike.ExpMapBin(bin)
+	}
+}
+
+// filterContext returns CDT contexts addressing the nested value indexed by the filter.
+func filterContext(steps []projectionStep) []*aerospike.CDTContext {
+	var ctx []*aerospike.CDTContext
+	for _, step := range steps[1:] {
+		ctx = append(ctx, stepContext(step))
+	}
+
+	return ctx
+}
+
+func combine(exps []Expr, op func(...*aerospike.Expression) *aerospike.Expression) Expr {
+	if len(exps) == 0 {
+		return Expr{err: fmt.Errorf("no expressions to combine: %w", errInvalidExpr)}
+	}
+
+	built := make([]*aerospike.Expression, len(exps))
+	for i, exp := range exps {
+		if err := exp.check(); err != nil {
+			return Expr{err: err}
+		}
+		built[i] = exp.exp
+	}
+
+	return Expr{exp: op(built...)}
+}
+
+func derefType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	return typ
+}
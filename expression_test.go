@@ -0,0 +1,325 @@
+package aerospike
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/stretchr/testify/require"
+)
+
+type expAddress struct {
+	City string `as:"city"`
+}
+
+type expStruct struct {
+	ID        string           `as:"id,key"`
+	Age       int              `as:"age"`
+	Score     float64          `as:"score"`
+	Active    bool             `as:"active"`
+	Name      string           `as:"name"`
+	CreatedAt time.Time        `as:"created_at"`
+	Tags      []string         `as:"tags"`
+	Attrs     map[string]int   `as:"attrs"`
+	Address   *expAddress      `as:"address"`
+	Labels    map[int64]string `as:"labels"`
+	Nested    map[string][]int `as:"nested"`
+	Ignored   string           `as:""`
+}
+
+func TestExpBuilder(t *testing.T) {
+	t.Parallel()
+	b, err := NewExpBuilder[expStruct]()
+	require.NoError(t, err)
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		exp  Expr
+		want *aerospike.Expression
+	}{
+		{
+			name: "int",
+			exp:  b.Eq("age", 30),
+			want: aerospike.ExpEq(aerospike.ExpIntBin("age"), aerospike.ExpIntVal(30)),
+		},
+		{
+			name: "float with int value",
+			exp:  b.Gt("score", 1),
+			want: aerospike.ExpGreater(aerospike.ExpFloatBin("score"), aerospike.ExpFloatVal(1)),
+		},
+		{
+			name: "bool",
+			exp:  b.NotEq("active", false),
+			want: aerospike.ExpNotEq(aerospike.ExpBoolBin("active"), aerospike.ExpBoolVal(false)),
+		},
+		{
+			name: "time",
+			exp:  b.Lt("created_at", created),
+			want: aerospike.ExpLess(aerospike.ExpIntBin("created_at"), aerospike.ExpIntVal(created.Unix())),
+		},
+		{
+			name: "nested struct field",
+			exp:  b.Eq("address.city", "Berlin"),
+			want: aerospike.ExpEq(
+				aerospike.ExpMapGetByKey(aerospike.MapReturnType.VALUE, aerospike.ExpTypeSTRING,
+					aerospike.ExpStringVal("city"), aerospike.ExpMapBin("address")),
+				aerospike.ExpStringVal("Berlin"),
+			),
+		},
+		{
+			name: "map key",
+			exp:  b.Ge("attrs.size", 3),
+			want: aerospike.ExpGreaterEq(
+				aerospike.ExpMapGetByKey(aerospike.MapReturnType.VALUE, aerospike.ExpTypeINT,
+					aerospike.ExpStringVal("size"), aerospike.ExpMapBin("attrs")),
+				aerospike.ExpIntVal(3),
+			),
+		},
+		{
+			name: "list in map",
+			exp:  b.Le("nested.a.1", 3),
+			want: aerospike.ExpLessEq(
+				aerospike.ExpListGetByIndex(aerospike.ListReturnTypeValue, aerospike.ExpTypeINT, aerospike.ExpIntVal(1),
+					aerospike.ExpMapBin("nested"), aerospike.CtxMapKey(aerospike.NewValue("a"))),
+				aerospike.ExpIntVal(3),
+			),
+		},
+		{
+			name: "list contains",
+			exp:  b.Contains("tags", "go"),
+			want: aerospike.ExpGreater(
+				aerospike.ExpListGetByValue(aerospike.ListReturnTypeCount, aerospike.ExpStringVal("go"), aerospike.ExpListBin("tags")),
+				aerospike.ExpIntVal(0),
+			),
+		},
+		{
+			name: "map contains key",
+			exp:  b.ContainsKey("labels", 7),
+			want: aerospike.ExpGreater(
+				aerospike.ExpMapGetByKey(aerospike.MapReturnType.COUNT, aerospike.ExpTypeINT,
+					aerospike.ExpIntVal(7), aerospike.ExpMapBin("labels")),
+				aerospike.ExpIntVal(0),
+			),
+		},
+		{
+			name: "combined",
+			exp:  b.And(b.Exists("name"), b.Not(b.Or(b.Eq("age", 1), b.Eq("age", 2)))),
+			want: aerospike.ExpAnd(
+				aerospike.ExpBinExists("name"),
+				aerospike.ExpNot(aerospike.ExpOr(
+					aerospike.ExpEq(aerospike.ExpIntBin("age"), aerospike.ExpIntVal(1)),
+					aerospike.ExpEq(aerospike.ExpIntBin("age"), aerospike.ExpIntVal(2)),
+				)),
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := tt.exp.Build()
+			require.NoError(t, err)
+			requireSameExpression(t, tt.want, got)
+		})
+	}
+}
+
+func TestExpBuilderErrors(t *testing.T) {
+	t.Parallel()
+	b, err := NewExpBuilder[expStruct]()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		exp  Expr
+		want error
+	}{
+		{name: "time with string", exp: b.Eq("created_at", "2024-01-02"), want: errTypeMismatch},
+		{name: "int with string", exp: b.Gt("age", "30"), want: errTypeMismatch},
+		{name: "string with int", exp: b.Eq("name", 1), want: errTypeMismatch},
+		{name: "int with float", exp: b.Eq("age", 1.5), want: errTypeMismatch},
+		{name: "list comparison", exp: b.Eq("tags", "go"), want: errTypeMismatch},
+		{name: "list element type", exp: b.Contains("tags", 1), want: errTypeMismatch},
+		{name: "contains on struct", exp: b.Contains("address", "Berlin"), want: errTypeMismatch},
+		{name: "contains key on list", exp: b.ContainsKey("tags", "go"), want: errTypeMismatch},
+		{name: "unknown field", exp: b.Eq("missing", 1), want: errInvalidPath},
+		{name: "field name instead of bin", exp: b.Eq("Age", 1), want: errInvalidPath},
+		{name: "field without bin", exp: b.Eq("Ignored", ""), want: errInvalidPath},
+		{name: "nested exists", exp: b.Exists("address.city"), want: errInvalidPath},
+		{name: "propagated", exp: b.And(b.Eq("age", 1), b.Not(b.Eq("age", "1"))), want: errTypeMismatch},
+		{name: "empty and", exp: b.And(), want: errInvalidExpr},
+		{name: "empty or", exp: b.Or(), want: errInvalidExpr},
+		{name: "zero", exp: Expr{}, want: errInvalidExpr},
+		{name: "not of zero", exp: b.Not(Expr{}), want: errInvalidExpr},
+		{name: "and with zero", exp: b.And(b.Eq("age", 1), Expr{}), want: errInvalidExpr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := tt.exp.Build()
+			require.ErrorIs(t, err, tt.want)
+		})
+	}
+
+	t.Run("not a struct", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewExpBuilder[int]()
+		require.ErrorIs(t, err, errInputType)
+	})
+}
+
+func TestExpBuilderFilters(t *testing.T) {
+	t.Parallel()
+	b, err := NewExpBuilder[expStruct]()
+	require.NoError(t, err)
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		filter func() (*aerospike.Filter, error)
+		want   *aerospike.Filter
+	}{
+		{
+			name:   "equal string",
+			filter: func() (*aerospike.Filter, error) { return b.FilterEq("name", "bob") },
+			want:   aerospike.NewEqualFilter("name", "bob"),
+		},
+		{
+			name:   "range of time",
+			filter: func() (*aerospike.Filter, error) { return b.FilterRange("created_at", created, created.Add(time.Hour)) },
+			want:   aerospike.NewRangeFilter("created_at", created.Unix(), created.Add(time.Hour).Unix()),
+		},
+		{
+			name:   "nested",
+			filter: func() (*aerospike.Filter, error) { return b.FilterEq("address.city", "Berlin") },
+			want:   aerospike.NewEqualFilter("address", "Berlin", aerospike.CtxMapKey(aerospike.NewValue("city"))),
+		},
+		{
+			name:   "list contains",
+			filter: func() (*aerospike.Filter, error) { return b.FilterContains("tags", "go") },
+			want:   aerospike.NewContainsFilter("tags", aerospike.ICT_LIST, "go"),
+		},
+		{
+			name:   "map values range",
+			filter: func() (*aerospike.Filter, error) { return b.FilterContainsRange("attrs", 1, 5) },
+			want:   aerospike.NewContainsRangeFilter("attrs", aerospike.ICT_MAPVALUES, 1, 5),
+		},
+		{
+			name:   "map keys",
+			filter: func() (*aerospike.Filter, error) { return b.FilterContainsKey("labels", 7) },
+			want:   aerospike.NewContainsFilter("labels", aerospike.ICT_MAPKEYS, int64(7)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := tt.filter()
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	errTests := []struct {
+		name   string
+		filter func() (*aerospike.Filter, error)
+	}{
+		{name: "float", filter: func() (*aerospike.Filter, error) { return b.FilterEq("score", 1.5) }},
+		{name: "time with int", filter: func() (*aerospike.Filter, error) { return b.FilterEq("created_at", 1) }},
+		{name: "range of strings", filter: func() (*aerospike.Filter, error) { return b.FilterRange("name", "a", "b") }},
+		{name: "list element", filter: func() (*aerospike.Filter, error) { return b.FilterContains("tags", 1) }},
+		{name: "keys of list", filter: func() (*aerospike.Filter, error) { return b.FilterContainsKey("tags", "go") }},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := tt.filter()
+			require.ErrorIs(t, err, errTypeMismatch)
+		})
+	}
+}
+
+func requireSameExpression(t *testing.T, want, got *aerospike.Expression) {
+	t.Helper()
+	wantB64, err := want.Base64()
+	require.NoError(t, err)
+	gotB64, err := got.Base64()
+	require.NoError(t, err)
+	require.Equal(t, wantB64, gotB64)
+}
+
+func TestCodecExpBuilder(t *testing.T) {
+	t.Parallel()
+
+	type event struct {
+		Addr  netip.Addr `db:"addr"`
+		Level level      `db:"level"`
+		At    time.Time  `db:"at"`
+		Seen  time.Time  `db:"seen"`
+	}
+	addr := netip.MustParseAddr("10.0.0.1")
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	rfc3339 := NewCodec(
+		WithTagName("db"),
+		WithTimeFormat(TimeRFC3339),
+		WithType(
+			func(a netip.Addr) (any, error) { return a.String(), nil },
+			func(v any) (netip.Addr, error) { return netip.ParseAddr(v.(string)) }, //nolint:forcetypeassert
+		),
+	)
+	b, err := NewCodecExpBuilder[event](rfc3339)
+	require.NoError(t, err)
+	millis, err := NewCodecExpBuilder[event](NewCodec(WithTagName("db"), WithTimeFormat(TimeUnixMilli)))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		exp  Expr
+		want *aerospike.Expression
+	}{
+		{
+			name: "registered",
+			exp:  b.Eq("addr", addr),
+			want: aerospike.ExpEq(aerospike.ExpStringBin("addr"), aerospike.ExpStringVal("10.0.0.1")),
+		},
+		{
+			name: "text marshaler",
+			exp:  b.Eq("level", level{name: "info"}),
+			want: aerospike.ExpEq(aerospike.ExpStringBin("level"), aerospike.ExpStringVal("INFO")),
+		},
+		{
+			name: "rfc3339",
+			exp:  b.Gt("at", at),
+			want: aerospike.ExpGreater(aerospike.ExpStringBin("at"), aerospike.ExpStringVal("2024-01-02T03:04:05Z")),
+		},
+		{
+			name: "unix milli",
+			exp:  millis.Lt("seen", &at),
+			want: aerospike.ExpLess(aerospike.ExpIntBin("seen"), aerospike.ExpIntVal(at.UnixMilli())),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := tt.exp.Build()
+			require.NoError(t, err)
+			requireSameExpression(t, tt.want, got)
+		})
+	}
+
+	_, err = b.Eq("addr", "10.0.0.1").Build()
+	require.ErrorIs(t, err, errTypeMismatch)
+	_, err = b.Eq("level", "INFO").Build()
+	require.ErrorIs(t, err, errTypeMismatch)
+	_, err = b.Eq("level.name", "info").Build()
+	require.ErrorIs(t, err, errInvalidPath)
+
+	filter, err := b.FilterEq("at", at)
+	require.NoError(t, err)
+	require.Equal(t, aerospike.NewEqualFilter("at", "2024-01-02T03:04:05Z"), filter)
+	_, err = b.FilterRange("at", at, at)
+	require.ErrorIs(t, err, errTypeMismatch)
+	filter, err = millis.FilterRange("seen", at, at)
+	require.NoError(t, err)
+	require.Equal(t, aerospike.NewRangeFilter("seen", at.UnixMilli(), at.UnixMilli()), filter)
+}
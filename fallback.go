@@ -0,0 +1,207 @@
+package aerospike
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Fallback is a standard interface the encoder and the decoder use for types implementing it.
+type Fallback int
+
+const (
+	// FallbackText stores values implementing encoding.TextMarshaler as string bins
+	// and decodes them with encoding.TextUnmarshaler.
+	FallbackText Fallback = iota + 1
+	// FallbackBinary stores values implementing encoding.BinaryMarshaler as blob bins
+	// and decodes them with encoding.BinaryUnmarshaler.
+	FallbackBinary
+	// FallbackSQL stores values implementing driver.Valuer as the value they return
+	// and decodes them with sql.Scanner, integers are scanned as int64.
+	FallbackSQL
+)
+
+var (
+	textMarshalerType     = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType   = reflect.TypeFor[encoding.TextUnmarshaler]()
+	binaryMarshalerType   = reflect.TypeFor[encoding.BinaryMarshaler]()
+	binaryUnmarshalerType = reflect.TypeFor[encoding.BinaryUnmarshaler]()
+	valuerType            = reflect.TypeFor[driver.Valuer]()
+	scannerType           = reflect.TypeFor[sql.Scanner]()
+)
+
+// SetFallbacks sets the standard interfaces the default codec consults for types that are not registered
+// with RegisterType, in order of precedence. The first one the type implements is used instead of
+// storing a struct as a map of its fields, or failing for kinds that cannot be stored. Named scalars,
+// slices and maps keep being stored by their kind, and time.Time by the time format. The default order is FallbackText, FallbackBinary, FallbackSQL;
+// calling SetFallbacks without arguments disables them. Use WithFallbacks to configure other codecs.
+func SetFallbacks(order ...Fallback) {
+	defaultCodec.mu.Lock()
+	defer defaultCodec.mu.Unlock()
+
+	defaultCodec.fallbacks = slices.Clone(order)
+}
+
+func (c *Codec) fallbackOrder() []Fallback {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.fallbacks
+}
+
+func (f Fallback) interfaces() (marshaler, unmarshaler reflect.Type) {
+	switch f {
+	case FallbackText:
+		return textMarshalerType, textUnmarshalerType
+	case FallbackBinary:
+		return binaryMarshalerType, binaryUnmarshalerType
+	default:
+		return valuerType, scannerType
+	}
+}
+
+// usesFallback reports whether values of the type, or the type it points to, are consulted
+// for fallback interfaces: structs other than time.Time and kinds that cannot be stored otherwise.
+func usesFallback(typ reflect.Type) bool {
+	typ = derefType(typ)
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Slice, reflect.Map:
+		return false
+	default:
+		return !isTimeType(typ)
+	}
+}
+
+// implementsFallback reports whether the type or the pointer to it implements a fallback interface.
+func (c *Codec) implementsFallback(typ reflect.Type) bool {
+	if !usesFallback(typ) {
+		return false
+	}
+
+	for _, fallback := range c.fallbackOrder() {
+		marshaler, _ := fallback.interfaces()
+		if typ.Implements(marshaler) || reflect.PointerTo(typ).Implements(marshaler) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// encodeFallback converts v with the first fallback interface v or its address implements.
+// ok is false if there is none.
+func (c *Codec) encodeFallback(v reflect.Value) (encoded any, ok bool, err error) {
+	if !usesFallback(v.Type()) {
+		return nil, false, nil
+	}
+
+	for _, fallback := range c.fallbackOrder() {
+		marshaler, _ := fallback.interfaces()
+		target := v
+		switch {
+		case v.Type().Implements(marshaler):
+			if v.Kind() == reflect.Pointer && v.IsNil() {
+				return nil, true, nil
+			}
+		case reflect.PointerTo(v.Type()).Implements(marshaler):
+			if !v.CanAddr() {
+				// Map values and fields of structs passed by value are copied to call pointer methods.
+				target = reflect.New(v.Type())
+				target.Elem().Set(v)
+			} else {
+				target = v.Addr()
+			}
+		default:
+			continue
+		}
+
+		encoded, err = c.marshalFallback(fallback, target.Interface())
+		if err != nil {
+			return nil, true, fmt.Errorf("failed to marshal %s: %w", v.Type(), err)
+		}
+		return encoded, true, nil
+	}
+
+	return nil, false, nil
+}
+
+func (c *Codec) marshalFallback(fallback Fallback, v any) (any, error) {
+	switch fallback {
+	case FallbackText:
+		text, err := v.(encoding.TextMarshaler).MarshalText() //nolint:forcetypeassert
+		return string(text), err
+	case FallbackBinary:
+		return v.(encoding.BinaryMarshaler).MarshalBinary() //nolint:forcetypeassert
+	default:
+		value, err := v.(driver.Valuer).Value() //nolint:forcetypeassert
+		if t, ok := value.(time.Time); ok {
+			return c.encodeTime(t), err
+		}
+		return value, err
+	}
+}
+
+// decodeFallback sets v from val with the first fallback interface the pointer to v,
+// or v itself if it is a pointer, implements. ok is false if there is none.
+func (c *Codec) decodeFallback(v reflect.Value, val any) (ok bool, err error) {
+	typ := v.Type()
+	if !usesFallback(typ) {
+		return false, nil
+	}
+
+	for _, fallback := range c.fallbackOrder() {
+		_, unmarshaler := fallback.interfaces()
+		var target reflect.Value
+		allocated := false
+		switch {
+		case typ.Kind() == reflect.Pointer && typ.Implements(unmarshaler):
+			target, allocated = reflect.New(typ.Elem()), true
+		case v.CanAddr() && reflect.PointerTo(typ).Implements(unmarshaler):
+			target = v.Addr()
+		default:
+			continue
+		}
+		if val == nil {
+			return true, nil
+		}
+
+		if err := unmarshalFallback(fallback, target.Interface(), val); err != nil {
+			return true, fmt.Errorf("failed to unmarshal %s: %w", typ, err)
+		}
+		if allocated {
+			v.Set(target)
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func unmarshalFallback(fallback Fallback, target, val any) error {
+	switch fallback {
+	case FallbackText:
+		text, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("text must be a string, got %T: %w", val, errInputType)
+		}
+		return target.(encoding.TextUnmarshaler).UnmarshalText([]byte(text)) //nolint:forcetypeassert
+	case FallbackBinary:
+		data, ok := val.([]byte)
+		if !ok {
+			return fmt.Errorf("binary must be a blob, got %T: %w", val, errInputType)
+		}
+		return target.(encoding.BinaryUnmarshaler).UnmarshalBinary(data) //nolint:forcetypeassert
+	default:
+		if i, ok := val.(int); ok {
+			val = int64(i)
+		}
+		return target.(sql.Scanner).Scan(val) //nolint:forcetypeassert
+	}
+}
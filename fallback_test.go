@@ -0,0 +1,188 @@
+package aerospike
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/stretchr/testify/require"
+)
+
+var errLevel = errors.New("invalid level")
+
+// level implements text and binary marshaling.
+type level struct {
+	name string
+}
+
+func (l level) MarshalText() ([]byte, error) {
+	return []byte(strings.ToUpper(l.name)), nil
+}
+
+func (l *level) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		return errLevel
+	}
+	l.name = strings.ToLower(string(text))
+	return nil
+}
+
+func (l level) MarshalBinary() ([]byte, error) {
+	return []byte(l.name), nil
+}
+
+func (l *level) UnmarshalBinary(data []byte) error {
+	l.name = string(data)
+	return nil
+}
+
+// checksum implements binary marshaling with pointer receivers only.
+type checksum struct {
+	sum [2]byte
+}
+
+func (c *checksum) MarshalBinary() ([]byte, error) {
+	return c.sum[:], nil
+}
+
+func (c *checksum) UnmarshalBinary(data []byte) error {
+	copy(c.sum[:], data)
+	return nil
+}
+
+// status is a named scalar implementing text marshaling, which is stored by its kind.
+type status int
+
+func (s status) MarshalText() ([]byte, error) {
+	return []byte("s" + strconv.Itoa(int(s))), nil
+}
+
+func (s *status) UnmarshalText(text []byte) error {
+	n, err := strconv.Atoi(strings.TrimPrefix(string(text), "s"))
+	*s = status(n)
+	return err
+}
+
+// label is a named string implementing text marshaling, which is stored by its kind.
+type label string
+
+func (l label) MarshalText() ([]byte, error) {
+	return []byte(strings.ToUpper(string(l))), nil
+}
+
+type fallbackStruct struct {
+	Level    level           `as:"level"`
+	Levels   []level         `as:"levels"`
+	ByLevel  map[level]int   `as:"by_level"`
+	Optional *level          `as:"optional"`
+	Checksum checksum        `as:"checksum"`
+	Nullable sql.NullString  `as:"nullable"`
+	Count    sql.NullInt64   `as:"count"`
+	Nested   *fallbackNested `as:"nested,omitempty"`
+}
+
+type fallbackNested struct {
+	Level level `as:"level"`
+}
+
+func TestFallbacks(t *testing.T) {
+	t.Parallel()
+
+	v := &fallbackStruct{
+		Level:    level{name: "info"},
+		Levels:   []level{{name: "warn"}},
+		ByLevel:  map[level]int{{name: "error"}: 1},
+		Optional: &level{name: "debug"},
+		Checksum: checksum{sum: [2]byte{1, 2}},
+		Nullable: sql.NullString{String: "a", Valid: true},
+		Count:    sql.NullInt64{Int64: 5, Valid: true},
+	}
+	bins, err := Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, aerospike.BinMap{
+		"level":    "INFO",
+		"levels":   []any{"WARN"},
+		"by_level": map[any]any{"ERROR": int64(1)},
+		"optional": "DEBUG",
+		"checksum": []byte{1, 2},
+		"nullable": "a",
+		"count":    int64(5),
+	}, bins)
+
+	var got fallbackStruct
+	require.NoError(t, Unmarshal(&aerospike.Record{Bins: Normalize(bins)}, &got))
+	require.Equal(t, v, &got)
+
+	err = Unmarshal(&aerospike.Record{Bins: aerospike.BinMap{"level": ""}}, &got)
+	require.ErrorIs(t, err, errLevel)
+	err = Unmarshal(&aerospike.Record{Bins: aerospike.BinMap{"level": 1}}, &got)
+	require.ErrorIs(t, err, errInputType)
+}
+
+//nolint:paralleltest // changes the global fallback order
+func TestSetFallbacks(t *testing.T) {
+	t.Cleanup(func() { SetFallbacks(FallbackText, FallbackBinary, FallbackSQL) })
+
+	v := &fallbackNested{Level: level{name: "info"}}
+	SetFallbacks(FallbackBinary, FallbackText)
+	bins, err := Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, aerospike.BinMap{"level": []byte("info")}, bins)
+
+	var got fallbackNested
+	require.NoError(t, Unmarshal(&aerospike.Record{Bins: bins}, &got))
+	require.Equal(t, v, &got)
+
+	SetFallbacks()
+	bins, err = Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, aerospike.BinMap{"level": map[string]any{}}, bins)
+}
+
+func TestFallbackScalars(t *testing.T) {
+	t.Parallel()
+
+	type scalars struct {
+		Status   status            `as:"status"`
+		Label    label             `as:"label"`
+		Statuses map[string]status `as:"statuses"`
+	}
+	v := &scalars{Status: 3, Label: "a", Statuses: map[string]status{"k": 4}}
+	bins, err := Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, aerospike.BinMap{
+		"status":   int64(3),
+		"label":    "a",
+		"statuses": map[any]any{"k": int64(4)},
+	}, bins)
+
+	var got scalars
+	require.NoError(t, Unmarshal(&aerospike.Record{Bins: Normalize(bins)}, &got))
+	require.Equal(t, v, &got)
+}
+
+func TestFallbackPointerReceivers(t *testing.T) {
+	t.Parallel()
+
+	type checksums struct {
+		ByName map[string]checksum `as:"by_name"`
+		List   []checksum          `as:"list"`
+	}
+	v := &checksums{
+		ByName: map[string]checksum{"a": {sum: [2]byte{1, 2}}},
+		List:   []checksum{{sum: [2]byte{3, 4}}},
+	}
+	bins, err := Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, aerospike.BinMap{
+		"by_name": map[any]any{"a": []byte{1, 2}},
+		"list":    []any{[]byte{3, 4}},
+	}, bins)
+
+	var got checksums
+	require.NoError(t, Unmarshal(&aerospike.Record{Bins: Normalize(bins)}, &got))
+	require.Equal(t, v, &got)
+}
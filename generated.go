@@ -0,0 +1,65 @@
+package aerospike
+
+import (
+	"reflect"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+)
+
+// Marshaler is implemented by structs with code generated by the asgen command,
+// which Marshal calls instead of converting fields by reflection unless the struct stores values
+// of registered types.
+type Marshaler interface {
+	MarshalAerospike() (aerospike.BinMap, error)
+}
+
+// Unmarshaler is implemented by pointers to structs with code generated by the asgen command,
+// which Unmarshal calls instead of setting fields by reflection.
+type Unmarshaler interface {
+	UnmarshalAerospike(bins aerospike.BinMap) error
+}
+
+// generated reports whether the codec uses generated methods of the struct type. Generated methods
+// follow the conventions of the default codec and do not see registered types, so structs storing
+// values of registered types at any depth are converted by reflection.
+func (c *Codec) generated(typ reflect.Type) bool {
+	if c != defaultCodec {
+		return false
+	}
+	if cached, ok := c.plain.Load(typ); ok {
+		return cached.(bool) //nolint:forcetypeassert
+	}
+
+	plain := !c.storesRegistered(typ, map[reflect.Type]bool{})
+	c.plain.Store(typ, plain)
+
+	return plain
+}
+
+// storesRegistered reports whether values of the type hold values of registered types.
+func (c *Codec) storesRegistered(typ reflect.Type, seen map[reflect.Type]bool) bool {
+	if c.isRegistered(typ) {
+		return true
+	}
+
+	switch typ.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return c.storesRegistered(typ.Elem(), seen)
+	case reflect.Map:
+		return c.storesRegistered(typ.Key(), seen) || c.storesRegistered(typ.Elem(), seen)
+	case reflect.Struct:
+		if seen[typ] || isTimeType(typ) {
+			return false
+		}
+		seen[typ] = true
+		fields, _ := c.structFields(typ)
+		for _, f := range fields {
+			if c.storesRegistered(typ.Field(f.index).Type, seen) {
+				return true
+			}
+		}
+	default:
+	}
+
+	return false
+}
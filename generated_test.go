@@ -0,0 +1,79 @@
+package aerospike
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/stretchr/testify/require"
+)
+
+type priority int
+
+type task struct {
+	Name     string   `as:"name"`
+	Priority priority `as:"priority"`
+}
+
+type tasks struct {
+	Items map[string][]*task `as:"items"`
+}
+
+type note struct {
+	Text string `as:"text"`
+}
+
+// The methods stand in for the ones generated by asgen.
+
+func (*task) MarshalAerospike() (aerospike.BinMap, error) {
+	return aerospike.BinMap{"generated": true}, nil
+}
+
+func (*tasks) MarshalAerospike() (aerospike.BinMap, error) {
+	return aerospike.BinMap{"generated": true}, nil
+}
+
+func (*note) MarshalAerospike() (aerospike.BinMap, error) {
+	return aerospike.BinMap{"generated": true}, nil
+}
+
+func (t *task) UnmarshalAerospike(aerospike.BinMap) error {
+	t.Name = "generated"
+	return nil
+}
+
+func TestGeneratedRegisteredTypes(t *testing.T) {
+	t.Parallel()
+	generated := aerospike.BinMap{"generated": true}
+
+	bins, err := Marshal(&task{Name: "a", Priority: 1})
+	require.NoError(t, err)
+	require.Equal(t, generated, bins)
+
+	RegisterType(
+		func(p priority) (any, error) { return fmt.Sprintf("p%d", p), nil },
+		func(v any) (priority, error) {
+			var p priority
+			_, err := fmt.Sscanf(v.(string), "p%d", &p) //nolint:forcetypeassert
+			return p, err
+		},
+	)
+
+	bins, err = Marshal(&task{Name: "a", Priority: 1})
+	require.NoError(t, err)
+	require.Equal(t, aerospike.BinMap{"name": "a", "priority": "p1"}, bins)
+
+	var got task
+	require.NoError(t, Unmarshal(&aerospike.Record{Bins: aerospike.BinMap{"name": "a", "priority": "p2"}}, &got))
+	require.Equal(t, task{Name: "a", Priority: 2}, got)
+
+	bins, err = Marshal(&tasks{Items: map[string][]*task{"k": {{Name: "b", Priority: 3}}}})
+	require.NoError(t, err)
+	require.Equal(t, aerospike.BinMap{
+		"items": map[any]any{"k": []any{map[string]any{"name": "b", "priority": "p3"}}},
+	}, bins)
+
+	bins, err = Marshal(&note{Text: "c"})
+	require.NoError(t, err)
+	require.Equal(t, generated, bins)
+}
@@ -0,0 +1,323 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/aerospike/aerospike-client-go/v8/types"
+)
+
+var errInvalidIndex = errors.New("invalid index declaration")
+
+// maxIndexNameLength is the longest index name in bytes the server accepts.
+const maxIndexNameLength = 63
+
+// indexTypes maps values of the "index" tag option to aerospike index types.
+var indexTypes = map[string]aerospike.IndexType{
+	"numeric":     aerospike.NUMERIC,
+	"string":      aerospike.STRING,
+	"blob":        aerospike.BLOB,
+	"geo2dsphere": aerospike.GEO2DSPHERE,
+}
+
+// indexCollectionTypes maps values of the "collection" tag option to aerospike index collection types.
+var indexCollectionTypes = map[string]aerospike.IndexCollectionType{
+	"":          aerospike.ICT_DEFAULT,
+	"default":   aerospike.ICT_DEFAULT,
+	"list":      aerospike.ICT_LIST,
+	"mapkeys":   aerospike.ICT_MAPKEYS,
+	"mapvalues": aerospike.ICT_MAPVALUES,
+}
+
+// IndexSpec describes a secondary index.
+type IndexSpec struct {
+	Name       string
+	Namespace  string
+	Set        string
+	Bin        string
+	Type       aerospike.IndexType
+	Collection aerospike.IndexCollectionType
+}
+
+// sameDefinition reports whether both specs index the same data, regardless of the index names.
+func (s IndexSpec) sameDefinition(other IndexSpec) bool {
+	return s.Namespace == other.Namespace && s.Set == other.Set && s.Bin == other.Bin &&
+		s.Type == other.Type && s.Collection == other.Collection
+}
+
+// IndexMismatch is a declared index that differs from the index found on the server.
+type IndexMismatch struct {
+	Declared IndexSpec
+	Server   IndexSpec
+}
+
+// IndexReport is the outcome of EnsureIndexes.
+type IndexReport struct {
+	// Created are the declared indexes that were missing and have been created.
+	Created []IndexSpec
+	// Present are the declared indexes that already existed.
+	Present []IndexSpec
+	// Mismatched are the declared indexes whose name or definition differs from the server.
+	// They are left untouched.
+	Mismatched []IndexMismatch
+	// Undeclared are the indexes of the set that T does not declare.
+	Undeclared []IndexSpec
+}
+
+// HasDrift reports whether the indexes on the server differ from the declared ones.
+func (r *IndexReport) HasDrift() bool {
+	return len(r.Mismatched) > 0 || len(r.Undeclared) > 0
+}
+
+// Indexes returns secondary indexes declared by the "index" and "collection" options of the "as" tags of T,
+// e.g. `as:"email,index=string"` or `as:"tags,index=string,collection=list"`.
+// Index names are derived from the set, bin, index type and collection type, names longer than
+// the server limit of 63 bytes are shortened to their prefix and a hash of the full name.
+func Indexes[T any](namespace, set string) ([]IndexSpec, error) {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("index declarations require a struct: %w", errInputType)
+	}
+
+	var specs []IndexSpec
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		tag := parseTag(field)
+		if tag.index == "" && tag.collection == "" {
+			continue
+		}
+
+		indexType, ok := indexTypes[tag.index]
+		if !ok {
+			return nil, fmt.Errorf("%s.%s: unknown index type %q: %w", typ, field.Name, tag.index, errInvalidIndex)
+		}
+		collection, ok := indexCollectionTypes[tag.collection]
+		if !ok {
+			return nil, fmt.Errorf("%s.%s: unknown collection type %q: %w", typ, field.Name, tag.collection, errInvalidIndex)
+		}
+		if tag.name == "" {
+			return nil, fmt.Errorf("%s.%s: index on a field without bin name: %w", typ, field.Name, errInvalidIndex)
+		}
+
+		spec := IndexSpec{
+			Namespace:  namespace,
+			Set:        set,
+			Bin:        tag.name,
+			Type:       indexType,
+			Collection: collection,
+		}
+		spec.Name = indexName(spec)
+		specs = append(specs, spec)
+	}
+
+	return specs, nil
+}
+
+// indexName derives the index name from its definition, e.g. "idx_users_tags_string_list".
+func indexName(spec IndexSpec) string {
+	parts := []string{"idx", spec.Set, spec.Bin, strings.ToLower(string(spec.Type))}
+	if spec.Collection != aerospike.ICT_DEFAULT {
+		parts = append(parts, strings.ToLower(spec.Collection.String()[len("ICT_"):]))
+	}
+
+	name := strings.Join(parts, "_")
+	if len(name) > maxIndexNameLength {
+		return shortName(name, maxIndexNameLength)
+	}
+
+	return name
+}
+
+// EnsureIndexes creates secondary indexes declared by T that are missing on the server and waits until
+// they are built. Indexes are never dropped or recreated: differences between the declared indexes
+// and the ones on the server are returned in the report for the operator to resolve.
+func EnsureIndexes[T any](ctx context.Context, client *aerospike.Client, namespace, set string) (*IndexReport, error) {
+	declared, err := Indexes[T](namespace, set)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := listIndexes(ctx, client, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &IndexReport{}
+	var missing []IndexSpec
+	matched := make(map[string]bool, len(existing))
+	for _, spec := range declared {
+		server, ok := findIndex(existing, spec)
+		switch {
+		case !ok:
+			missing = append(missing, spec)
+			continue
+		case server == spec:
+			report.Present = append(report.Present, spec)
+		default:
+			report.Mismatched = append(report.Mismatched, IndexMismatch{Declared: spec, Server: server})
+		}
+		matched[server.Name] = true
+	}
+	for _, server := range existing {
+		if server.Set == set && !matched[server.Name] {
+			report.Undeclared = append(report.Undeclared, server)
+		}
+	}
+
+	for _, spec := range missing {
+		if err = createIndex(ctx, client, spec); err != nil {
+			return report, fmt.Errorf("failed to create index %s: %w", spec.Name, err)
+		}
+		report.Created = append(report.Created, spec)
+	}
+
+	return report, nil
+}
+
+// findIndex returns the server index with the same name as spec or, failing that, with the same definition.
+func findIndex(existing []IndexSpec, spec IndexSpec) (IndexSpec, bool) {
+	for _, server := range existing {
+		if server.Name == spec.Name {
+			return server, true
+		}
+	}
+	for _, server := range existing {
+		if server.sameDefinition(spec) {
+			return server, true
+		}
+	}
+
+	return IndexSpec{}, false
+}
+
+func createIndex(ctx context.Context, client *aerospike.Client, spec IndexSpec) error {
+	policy := aerospike.NewWritePolicy(0, 0)
+	if err := applyContext(ctx, &policy.BasePolicy); err != nil {
+		return err
+	}
+
+	task, aerr := client.CreateComplexIndex(
+		policy, spec.Namespace, spec.Set, spec.Name, spec.Bin, spec.Type, spec.Collection)
+	if aerr != nil {
+		// Index was created concurrently, e.g. by another instance of the service.
+		if aerr.Matches(types.INDEX_FOUND) {
+			return nil
+		}
+		return aerr
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case aerr = <-task.OnComplete():
+		if aerr != nil {
+			return aerr
+		}
+	}
+
+	return nil
+}
+
+// listIndexes returns secondary indexes of the namespace known to the cluster. Every active node is asked,
+// as indexes being created or dropped may not have reached all of them yet.
+func listIndexes(ctx context.Context, client *aerospike.Client, namespace string) ([]IndexSpec, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	const command = "sindex-list:"
+	var specs []IndexSpec
+	seen := make(map[string]bool)
+	asked := false
+	for _, node := range client.GetNodes() {
+		if !node.IsActive() {
+			continue
+		}
+		policy := aerospike.NewInfoPolicy()
+		if deadline, ok := ctx.Deadline(); ok {
+			policy.Timeout = min(policy.Timeout, time.Until(deadline))
+		}
+		response, aerr := node.RequestInfo(policy, command)
+		if aerr != nil {
+			return nil, fmt.Errorf("failed to list indexes on node %s: %w", node.GetName(), aerr)
+		}
+		nodeSpecs, err := parseIndexList(response[command], namespace)
+		if err != nil {
+			return nil, err
+		}
+		specs = mergeIndexes(specs, nodeSpecs, seen)
+		asked = true
+	}
+	if !asked {
+		return nil, fmt.Errorf("failed to list indexes: %w",
+			&aerospike.AerospikeError{ResultCode: types.SERVER_NOT_AVAILABLE})
+	}
+
+	return specs, nil
+}
+
+// mergeIndexes appends the specs with names not seen yet.
+func mergeIndexes(specs, other []IndexSpec, seen map[string]bool) []IndexSpec {
+	for _, spec := range other {
+		if !seen[spec.Name] {
+			seen[spec.Name] = true
+			specs = append(specs, spec)
+		}
+	}
+
+	return specs
+}
+
+// parseIndexList parses the response of the "sindex-list" info command, e.g.
+// "ns=test:indexname=idx:set=users:bin=age:type=numeric:indextype=default:context=NULL:state=RW;...".
+// Only indexes of the namespace on a single bin are returned.
+func parseIndexList(response, namespace string) ([]IndexSpec, error) {
+	var specs []IndexSpec
+	for entry := range strings.SplitSeq(strings.TrimSpace(response), ";") {
+		if entry == "" {
+			continue
+		}
+
+		fields := make(map[string]string)
+		for pair := range strings.SplitSeq(entry, ":") {
+			name, value, _ := strings.Cut(pair, "=")
+			fields[name] = value
+		}
+		ns, ok := fields["ns"]
+		if !ok {
+			ns = fields["namespace"]
+		}
+		if ns != namespace || fields["bin"] == "" || fields["bin"] == "NULL" {
+			continue
+		}
+
+		indexType, ok := indexTypes[strings.ToLower(fields["type"])]
+		if !ok {
+			return nil, fmt.Errorf("index %s has unknown type %q: %w", fields["indexname"], fields["type"], errInvalidIndex)
+		}
+		collection, ok := indexCollectionTypes[strings.ToLower(fields["indextype"])]
+		if !ok {
+			return nil, fmt.Errorf("index %s has unknown collection type %q: %w",
+				fields["indexname"], fields["indextype"], errInvalidIndex)
+		}
+		set := fields["set"]
+		if set == "NULL" {
+			set = ""
+		}
+		specs = append(specs, IndexSpec{
+			Name:       fields["indexname"],
+			Namespace:  ns,
+			Set:        set,
+			Bin:        fields["bin"],
+			Type:       indexType,
+			Collection: collection,
+		})
+	}
+
+	return specs, nil
+}
@@ -0,0 +1,154 @@
+package aerospike
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexes(t *testing.T) {
+	t.Parallel()
+	type user struct {
+		ID    string   `as:"id,key"`
+		Email string   `as:"email,index=string"`
+		Age   int      `as:"age,index=numeric"`
+		Tags  []string `as:"tags,index=string,collection=list"`
+		Loc   string   `as:"loc,index=geo2dsphere"`
+		Name  string   `as:"name"`
+	}
+
+	got, err := Indexes[user]("test", "users")
+	require.NoError(t, err)
+	require.Equal(t, []IndexSpec{
+		{Name: "idx_users_email_string", Namespace: "test", Set: "users", Bin: "email", Type: aerospike.STRING},
+		{Name: "idx_users_age_numeric", Namespace: "test", Set: "users", Bin: "age", Type: aerospike.NUMERIC},
+		{
+			Name: "idx_users_tags_string_list", Namespace: "test", Set: "users", Bin: "tags",
+			Type: aerospike.STRING, Collection: aerospike.ICT_LIST,
+		},
+		{Name: "idx_users_loc_geo2dsphere", Namespace: "test", Set: "users", Bin: "loc", Type: aerospike.GEO2DSPHERE},
+	}, got)
+
+	t.Run("unknown index type", func(t *testing.T) {
+		t.Parallel()
+		_, err := Indexes[struct {
+			A int `as:"a,index=text"`
+		}]("test", "set")
+		require.ErrorIs(t, err, errInvalidIndex)
+	})
+	t.Run("unknown collection type", func(t *testing.T) {
+		t.Parallel()
+		_, err := Indexes[struct {
+			A []int `as:"a,index=numeric,collection=set"`
+		}]("test", "set")
+		require.ErrorIs(t, err, errInvalidIndex)
+	})
+	t.Run("collection without index type", func(t *testing.T) {
+		t.Parallel()
+		_, err := Indexes[struct {
+			A []int `as:"a,collection=list"`
+		}]("test", "set")
+		require.ErrorIs(t, err, errInvalidIndex)
+	})
+	t.Run("no bin name", func(t *testing.T) {
+		t.Parallel()
+		_, err := Indexes[struct {
+			A int `as:",index=numeric"`
+		}]("test", "set")
+		require.ErrorIs(t, err, errInvalidIndex)
+	})
+	t.Run("long name", func(t *testing.T) {
+		t.Parallel()
+		set := strings.Repeat("s", 63)
+		got, err := Indexes[struct {
+			Tags []string `as:"tags,index=string,collection=mapvalues"`
+		}]("test", set)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		require.Len(t, got[0].Name, maxIndexNameLength)
+		require.True(t, strings.HasPrefix(got[0].Name, "idx_sss"))
+
+		other, err := Indexes[struct {
+			Tags []string `as:"tags,index=string,collection=mapkeys"`
+		}]("test", set)
+		require.NoError(t, err)
+		require.NotEqual(t, got[0].Name, other[0].Name)
+	})
+	t.Run("not a struct", func(t *testing.T) {
+		t.Parallel()
+		_, err := Indexes[int]("test", "set")
+		require.ErrorIs(t, err, errInputType)
+	})
+}
+
+func TestParseIndexList(t *testing.T) {
+	t.Parallel()
+	response := "ns=test:indexname=idx_users_age_numeric:set=users:bin=age:type=numeric:indextype=default:" +
+		"context=NULL:exp=NULL:state=RW;" +
+		"ns=test:indexname=tags:set=users:bin=tags:type=string:indextype=list:context=NULL:exp=NULL:state=RW;" +
+		"ns=other:indexname=age:set=users:bin=age:type=numeric:indextype=default:context=NULL:exp=NULL:state=RW;" +
+		"ns=test:indexname=all:set=NULL:bin=name:type=string:indextype=default:context=NULL:exp=NULL:state=RW;" +
+		"ns=test:indexname=exp:set=users:bin=NULL:type=numeric:indextype=default:context=NULL:exp=kQI=:state=RW;"
+
+	got, err := parseIndexList(response, "test")
+	require.NoError(t, err)
+	require.Equal(t, []IndexSpec{
+		{Name: "idx_users_age_numeric", Namespace: "test", Set: "users", Bin: "age", Type: aerospike.NUMERIC},
+		{Name: "tags", Namespace: "test", Set: "users", Bin: "tags", Type: aerospike.STRING, Collection: aerospike.ICT_LIST},
+		{Name: "all", Namespace: "test", Bin: "name", Type: aerospike.STRING},
+	}, got)
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+		got, err := parseIndexList("", "test")
+		require.NoError(t, err)
+		require.Empty(t, got)
+	})
+	t.Run("unknown type", func(t *testing.T) {
+		t.Parallel()
+		_, err := parseIndexList("ns=test:indexname=a:set=s:bin=a:type=text:indextype=default", "test")
+		require.ErrorIs(t, err, errInvalidIndex)
+	})
+}
+
+func TestFindIndex(t *testing.T) {
+	t.Parallel()
+	declared := IndexSpec{Name: "idx_users_age_numeric", Namespace: "test", Set: "users", Bin: "age", Type: aerospike.NUMERIC}
+	renamed := declared
+	renamed.Name = "age"
+	retyped := declared
+	retyped.Name = "other"
+	retyped.Type = aerospike.STRING
+
+	tests := []struct {
+		name     string
+		existing []IndexSpec
+		want     IndexSpec
+		found    bool
+	}{
+		{name: "missing", existing: []IndexSpec{retyped}},
+		{name: "same", existing: []IndexSpec{renamed, declared}, want: declared, found: true},
+		{name: "renamed", existing: []IndexSpec{renamed}, want: renamed, found: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, found := findIndex(tt.existing, declared)
+			require.Equal(t, tt.found, found)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMergeIndexes(t *testing.T) {
+	t.Parallel()
+	age := IndexSpec{Name: "idx_users_age_numeric", Namespace: "test", Set: "users", Bin: "age", Type: aerospike.NUMERIC}
+	tags := IndexSpec{Name: "tags", Namespace: "test", Set: "users", Bin: "tags", Type: aerospike.STRING}
+
+	seen := make(map[string]bool)
+	got := mergeIndexes(nil, []IndexSpec{age}, seen)
+	got = mergeIndexes(got, []IndexSpec{age, tags}, seen)
+	require.Equal(t, []IndexSpec{age, tags}, got)
+}
@@ -3,8 +3,8 @@ package aerospike
 import (
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
-	"strings"
 	"time"
 
 	"github.com/aerospike/aerospike-client-go/v8"
@@ -16,69 +16,51 @@ var errInputType = errors.New("wrong variable provided")
 
 // Marshal converts struct into bin map using "as" tags as bin names.
 func Marshal(v any) (aerospike.BinMap, error) {
-	if v == nil {
-		return aerospike.BinMap{}, nil
-	}
-
-	rv := reflect.ValueOf(v)
-	indirect := reflect.Indirect(rv)
-	if rv.Kind() != reflect.Pointer || indirect.Kind() != reflect.Struct || rv.IsNil() {
-		return aerospike.BinMap{}, fmt.Errorf("the provided variable must be a non-nil pointer to a struct: %w", errInputType)
-	}
-
-	binMap, err := marshalStruct(indirect)
-	if err != nil {
-		return nil, err
-	}
-
-	return binMap, nil
+	return defaultCodec.Marshal(v)
 }
 
-func marshalStruct(v reflect.Value) (map[string]any, error) {
+func (c *Codec) marshalStruct(v reflect.Value) (map[string]any, error) {
 	if v.Kind() == reflect.Pointer {
 		v = reflect.Indirect(v)
 	}
 
-	out := make(map[string]any)
-	var err error
-	for i := range v.Type().NumField() {
-		tag := v.Type().Field(i).Tag.Get(structTag)
-		if tag == "" {
-			continue
-		}
-
-		omit := false
-		opts := strings.SplitN(tag, ",", 1)
-		if len(opts) == 2 && opts[1] == "omitempty" {
-			omit = true
-		}
+	fields, err := c.structFields(v.Type())
+	if err != nil {
+		return nil, err
+	}
 
-		field := v.Field(i)
-		if field.IsZero() && omit {
+	out := make(map[string]any, len(fields))
+	for _, f := range fields {
+		field := v.Field(f.index)
+		if field.IsZero() && f.tag.omitEmpty {
 			continue
 		}
 
-		if field.Kind() == reflect.Ptr {
-			field = reflect.Indirect(field)
+		if field.Kind() == reflect.Ptr && !c.isRegistered(field.Type()) {
+			if field.IsNil() {
+				out[f.tag.name] = nil
+				continue
+			}
+			field = field.Elem()
 		}
 
-		out[tag], err = convertValue(field)
+		out[f.tag.name], err = c.convertValue(field)
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert field %v: %w", v.Type().NumField(), err)
+			return nil, fmt.Errorf("failed to convert field %s: %w", v.Type().Field(f.index).Name, err)
 		}
 	}
 
 	return out, nil
 }
 
-func convertValue(v reflect.Value) (any, error) {
+func (c *Codec) convertValue(v reflect.Value) (any, error) {
+	if encoded, ok, err := c.encodeCustom(v); ok {
+		return encoded, err
+	}
+
 	realValue := v.Interface()
 	if timeVal, ok := realValue.(time.Time); ok {
-		if timeVal.IsZero() {
-			return 0, nil
-		}
-
-		return timeVal.Unix(), nil
+		return c.encodeTime(timeVal), nil
 	}
 
 	switch v.Kind() {
@@ -88,17 +70,31 @@ func convertValue(v reflect.Value) (any, error) {
 		return v.Int(), nil
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		unsigned := v.Uint()
+		if c.uintPolicy == UintReject && unsigned > math.MaxInt64 {
+			return nil, fmt.Errorf("unsigned integer %d overflows int64: %w", unsigned, errInputType)
+		}
 		return int64(unsigned), nil //nolint:gosec
 	case reflect.Float32, reflect.Float64:
 		return v.Float(), nil
 	case reflect.String:
 		return v.String(), nil
 	case reflect.Map:
-		return marshalMap(v)
+		if v.IsNil() && c.nilPolicy == NilAsNil {
+			return nil, nil
+		}
+		return c.marshalMap(v)
 	case reflect.Slice:
-		return marshalSlice(v)
+		if v.IsNil() && c.nilPolicy == NilAsNil {
+			return nil, nil
+		}
+		return c.marshalSlice(v)
 	case reflect.Struct:
-		return marshalStruct(v)
+		return c.marshalStruct(v)
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return c.convertValue(v.Elem())
 	default:
 		return nil, fmt.Errorf(
 			"type %s is not supported: %w",
@@ -108,11 +104,11 @@ func convertValue(v reflect.Value) (any, error) {
 	}
 }
 
-func marshalSlice(v reflect.Value) ([]any, error) {
+func (c *Codec) marshalSlice(v reflect.Value) ([]any, error) {
 	out := make([]any, v.Len())
 	var err error
 	for i := range v.Len() {
-		out[i], err = convertValue(v.Index(i))
+		out[i], err = c.convertValue(v.Index(i))
 		if err != nil {
 			return nil, fmt.Errorf("failed to unmarshal slice: %w", err)
 		}
@@ -121,15 +117,15 @@ func marshalSlice(v reflect.Value) ([]any, error) {
 	return out, nil
 }
 
-func marshalMap(in reflect.Value) (any, error) {
+func (c *Codec) marshalMap(in reflect.Value) (any, error) {
 	iter := in.MapRange()
 	out := make(map[any]any, len(in.MapKeys()))
 	for iter.Next() {
-		iterKey, err := getMapKey(iter.Key())
+		iterKey, err := c.getMapKey(iter.Key())
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal map key %v: %w", iter.Key().Interface(), err)
 		}
-		iterVal, err := convertValue(iter.Value())
+		iterVal, err := c.convertValue(iter.Value())
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal map value in key %s %v: %w", iter.Key().Interface(), iter.Value().Interface(), err)
 		}
@@ -140,11 +136,18 @@ func marshalMap(in reflect.Value) (any, error) {
 	return out, nil
 }
 
-func getMapKey(val reflect.Value) (any, error) {
+func (c *Codec) getMapKey(val reflect.Value) (any, error) {
+	if encoded, ok, err := c.encodeCustom(val); ok {
+		return encoded, err
+	}
+
 	switch val.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		return val.Int(), nil
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if c.uintPolicy == UintReject && val.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("unsigned integer %d overflows int64: %w", val.Uint(), errInputType)
+		}
 		return val.Uint(), nil
 	case reflect.String:
 		return val.String(), nil
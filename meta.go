@@ -0,0 +1,144 @@
+package aerospike
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+)
+
+// metaFields holds indexes of struct fields tagged with record metadata options,
+// -1 for the ones the struct does not have.
+type metaFields struct {
+	key        int
+	generation int
+	ttl        int
+}
+
+func findMetaFields(typ reflect.Type) metaFields {
+	meta := metaFields{key: -1, generation: -1, ttl: -1}
+	if typ.Kind() != reflect.Struct {
+		return meta
+	}
+	for i := range typ.NumField() {
+		tag := parseTag(typ.Field(i))
+		fieldType := typ.Field(i).Type
+		switch {
+		case tag.key && meta.key < 0:
+			meta.key = i
+		case tag.generation && meta.generation < 0 && isIntegerKind(fieldType.Kind()):
+			meta.generation = i
+		case tag.ttl && meta.ttl < 0 && isIntegerKind(fieldType.Kind()):
+			meta.ttl = i
+		}
+	}
+
+	return meta
+}
+
+// keyValue converts the key field of v into a value accepted by aerospike.NewKey.
+func (m metaFields) keyValue(v reflect.Value) (any, error) {
+	if m.key < 0 {
+		return nil, fmt.Errorf("%s: %w", v.Type(), errNoKeyField)
+	}
+
+	field := v.Field(m.key)
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(field.Uint()), nil //nolint:gosec
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.Uint8 {
+			return field.Bytes(), nil
+		}
+	default:
+	}
+
+	return nil, fmt.Errorf("key type %s is not supported: %w", field.Type(), errInputType)
+}
+
+// setKey puts the user key into the key field of v.
+func (m metaFields) setKey(v reflect.Value, key any) error {
+	if m.key < 0 {
+		return nil
+	}
+
+	field := v.Field(m.key)
+	keyVal := reflect.ValueOf(key)
+	if !keyVal.CanConvert(field.Type()) || (field.Kind() == reflect.String) != (keyVal.Kind() == reflect.String) {
+		return fmt.Errorf("cannot convert key %v to %v: %w", key, field.Type(), errInputType)
+	}
+	field.Set(keyVal.Convert(field.Type()))
+
+	return nil
+}
+
+// generationOf returns the value of the generation field of v, 0 if there is none.
+func (m metaFields) generationOf(v reflect.Value) uint32 {
+	if m.generation < 0 {
+		return 0
+	}
+
+	return uint32(integerValue(v.Field(m.generation))) //nolint:gosec
+}
+
+// expirationOf returns the value of the TTL field of v in seconds, 0 if there is none.
+// Negative values follow aerospike conventions: -1 means never expire and -2 means do not update TTL.
+func (m metaFields) expirationOf(v reflect.Value) uint32 {
+	if m.ttl < 0 {
+		return 0
+	}
+
+	field := v.Field(m.ttl)
+	if field.Type() == reflect.TypeFor[time.Duration]() {
+		return uint32(time.Duration(field.Int()) / time.Second) //nolint:gosec
+	}
+
+	return uint32(integerValue(field)) //nolint:gosec
+}
+
+// setRecordMeta fills the generation and TTL fields of v from the record.
+func (m metaFields) setRecordMeta(v reflect.Value, record *aerospike.Record) {
+	if m.generation >= 0 {
+		setIntegerValue(v.Field(m.generation), int64(record.Generation))
+	}
+	if m.ttl >= 0 {
+		field := v.Field(m.ttl)
+		if field.Type() == reflect.TypeFor[time.Duration]() {
+			field.SetInt(int64(time.Duration(record.Expiration) * time.Second))
+			return
+		}
+		setIntegerValue(field, int64(record.Expiration))
+	}
+}
+
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+func integerValue(v reflect.Value) int64 {
+	if v.CanUint() {
+		return int64(v.Uint()) //nolint:gosec
+	}
+
+	return v.Int()
+}
+
+func setIntegerValue(v reflect.Value, i int64) {
+	if v.CanUint() {
+		v.SetUint(uint64(i)) //nolint:gosec
+		return
+	}
+
+	v.SetInt(i)
+}
@@ -0,0 +1,110 @@
+package aerospike
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindMetaFields(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		in   any
+		want metaFields
+	}{
+		{
+			name: "no metadata",
+			in:   innerStruct{},
+			want: metaFields{key: -1, generation: -1, ttl: -1},
+		},
+		{
+			name: "all metadata",
+			in: struct {
+				Bin string        `as:"bin"`
+				ID  string        `as:"id,key"`
+				Gen uint32        `as:",generation"`
+				TTL time.Duration `as:",ttl"`
+			}{},
+			want: metaFields{key: 1, generation: 2, ttl: 3},
+		},
+		{
+			name: "non-integer generation is ignored",
+			in: struct {
+				Gen string `as:",generation"`
+			}{},
+			want: metaFields{key: -1, generation: -1, ttl: -1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, findMetaFields(reflect.TypeOf(tt.in)))
+		})
+	}
+}
+
+func TestMetaKeyValue(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		in      any
+		want    any
+		wantErr bool
+	}{
+		{name: "string", in: "key", want: "key"},
+		{name: "int", in: 1, want: int64(1)},
+		{name: "uint32", in: uint32(2), want: int64(2)},
+		{name: "bytes", in: []byte{1, 2}, want: []byte{1, 2}},
+		{name: "float", in: 1.5, wantErr: true},
+		{name: "slice", in: []int{1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			v := reflect.New(reflect.StructOf([]reflect.StructField{{
+				Name: "Key",
+				Type: reflect.TypeOf(tt.in),
+				Tag:  `as:",key"`,
+			}})).Elem()
+			v.Field(0).Set(reflect.ValueOf(tt.in))
+			got, err := findMetaFields(v.Type()).keyValue(v)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := findMetaFields(reflect.TypeFor[innerStruct]()).keyValue(reflect.ValueOf(innerStruct{}))
+	require.ErrorIs(t, err, errNoKeyField)
+}
+
+func TestMetaRecordFields(t *testing.T) {
+	t.Parallel()
+	type durationTTL struct {
+		Gen int           `as:",generation"`
+		TTL time.Duration `as:",ttl"`
+	}
+	type secondsTTL struct {
+		TTL int32 `as:",ttl"`
+	}
+	record := &aerospike.Record{Generation: 4, Expiration: 60}
+
+	var withDuration durationTTL
+	v := reflect.ValueOf(&withDuration).Elem()
+	meta := findMetaFields(v.Type())
+	meta.setRecordMeta(v, record)
+	require.Equal(t, durationTTL{Gen: 4, TTL: time.Minute}, withDuration)
+	require.Equal(t, uint32(4), meta.generationOf(v))
+	require.Equal(t, uint32(60), meta.expirationOf(v))
+
+	withSeconds := secondsTTL{TTL: -1}
+	v = reflect.ValueOf(&withSeconds).Elem()
+	meta = findMetaFields(v.Type())
+	require.Equal(t, uint32(aerospike.TTLDontExpire), meta.expirationOf(v))
+	meta.setRecordMeta(v, record)
+	require.Equal(t, secondsTTL{TTL: 60}, withSeconds)
+	require.Zero(t, meta.generationOf(v))
+}
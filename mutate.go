@@ -0,0 +1,219 @@
+package aerospike
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/aerospike/aerospike-client-go/v8/types"
+)
+
+const (
+	defaultMutateMaxRetries = 5
+	defaultMutateMinBackoff = 5 * time.Millisecond
+	defaultMutateMaxBackoff = 200 * time.Millisecond
+)
+
+// MutateOption configures Mutate.
+type MutateOption func(*mutateOptions)
+
+type mutateOptions struct {
+	readPolicy    *aerospike.BasePolicy
+	writePolicy   *aerospike.WritePolicy
+	createMissing bool
+	maxRetries    int
+	minBackoff    time.Duration
+	maxBackoff    time.Duration
+}
+
+// MutateCreateIfMissing makes Mutate create the record from the zero value of T if it does not exist.
+// Without it, Mutate fails with ErrNotFound.
+func MutateCreateIfMissing() MutateOption {
+	return func(o *mutateOptions) {
+		o.createMissing = true
+	}
+}
+
+// MutateMaxRetries sets how many times Mutate retries after a concurrent modification. Default is 5.
+func MutateMaxRetries(retries int) MutateOption {
+	return func(o *mutateOptions) {
+		o.maxRetries = retries
+	}
+}
+
+// MutateBackoff sets the bounds of the exponential backoff between retries. Default is 5ms to 200ms.
+func MutateBackoff(minBackoff, maxBackoff time.Duration) MutateOption {
+	return func(o *mutateOptions) {
+		o.minBackoff = minBackoff
+		o.maxBackoff = maxBackoff
+	}
+}
+
+// MutateReadPolicy sets the policy used to read the record.
+func MutateReadPolicy(policy *aerospike.BasePolicy) MutateOption {
+	return func(o *mutateOptions) {
+		o.readPolicy = policy
+	}
+}
+
+// MutateWritePolicy sets the policy used to write the record back.
+// Its record exists action and generation settings are overridden.
+func MutateWritePolicy(policy *aerospike.WritePolicy) MutateOption {
+	return func(o *mutateOptions) {
+		o.writePolicy = policy
+	}
+}
+
+// Mutate performs an optimistic read-modify-write of the record under key.
+// It reads the record into T, calls fn and writes back only the bins that fn has changed,
+// expecting the generation of the record to be the same as when it was read.
+// If the record was modified concurrently, the whole cycle is retried with backoff,
+// and ErrConflict is returned when retries are exhausted. Error returned by fn aborts Mutate as is.
+func Mutate[T any](
+	ctx context.Context,
+	client Client,
+	key *aerospike.Key,
+	fn func(*T) error,
+	opts ...MutateOption,
+) (T, error) {
+	options := mutateOptions{
+		readPolicy:  aerospike.NewPolicy(),
+		writePolicy: aerospike.NewWritePolicy(0, 0),
+		maxRetries:  defaultMutateMaxRetries,
+		minBackoff:  defaultMutateMinBackoff,
+		maxBackoff:  defaultMutateMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var zero T
+	binNames, err := GetBinKeys(&zero)
+	if err != nil {
+		return zero, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		v, conflict, err := mutateOnce(ctx, client, key, binNames, fn, &options)
+		if err == nil {
+			return v, nil
+		}
+		if !conflict {
+			return zero, err
+		}
+		if attempt >= options.maxRetries {
+			return zero, fmt.Errorf("%w: gave up after %d attempts: %w", ErrConflict, attempt+1, err)
+		}
+
+		timer := time.NewTimer(backoff(attempt, options.minBackoff, options.maxBackoff))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// mutateOnce performs a single read-modify-write cycle and reports whether it failed
+// because of a concurrent modification.
+func mutateOnce[T any](
+	ctx context.Context,
+	client Client,
+	key *aerospike.Key,
+	binNames []string,
+	fn func(*T) error,
+	options *mutateOptions,
+) (T, bool, error) {
+	var v T
+	readPolicy := *options.readPolicy
+	if err := applyContext(ctx, &readPolicy); err != nil {
+		return v, false, err
+	}
+
+	exists := true
+	record, aerr := client.Get(&readPolicy, key, binNames...)
+	switch {
+	case aerr != nil && aerr.Matches(types.KEY_NOT_FOUND_ERROR) && options.createMissing:
+		exists = false
+	case aerr != nil:
+		return v, false, mapError(aerr)
+	default:
+		if err := Unmarshal(record, &v); err != nil {
+			return v, false, err
+		}
+	}
+
+	before, err := Marshal(&v)
+	if err != nil {
+		return v, false, err
+	}
+	if err = fn(&v); err != nil {
+		return v, false, err
+	}
+	after, err := Marshal(&v)
+	if err != nil {
+		return v, false, err
+	}
+
+	bins := diffBins(before, after)
+	if exists && len(bins) == 0 {
+		return v, false, nil
+	}
+
+	writePolicy := *options.writePolicy
+	if err = applyContext(ctx, &writePolicy.BasePolicy); err != nil {
+		return v, false, err
+	}
+	if exists {
+		writePolicy.RecordExistsAction = aerospike.UPDATE_ONLY
+		writePolicy.GenerationPolicy = aerospike.EXPECT_GEN_EQUAL
+		writePolicy.Generation = record.Generation
+	} else {
+		writePolicy.RecordExistsAction = aerospike.CREATE_ONLY
+		writePolicy.GenerationPolicy = aerospike.NONE
+		bins = after
+	}
+	if aerr = client.Put(&writePolicy, key, bins); aerr != nil {
+		// the record was changed, created or deleted since it was read
+		conflict := aerr.Matches(types.GENERATION_ERROR, types.KEY_EXISTS_ERROR, types.KEY_NOT_FOUND_ERROR)
+		return v, conflict, mapError(aerr)
+	}
+
+	return v, false, nil
+}
+
+// diffBins returns bins of after that differ from before.
+// Bins that are missing in after are set to nil, so that they are removed from the record.
+func diffBins(before, after aerospike.BinMap) aerospike.BinMap {
+	out := make(aerospike.BinMap)
+	for name, val := range after {
+		if prev, ok := before[name]; !ok || !reflect.DeepEqual(prev, val) {
+			out[name] = val
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			out[name] = nil
+		}
+	}
+
+	return out
+}
+
+// backoff returns exponentially growing delay with jitter for the given attempt,
+// saturating at maxBackoff.
+func backoff(attempt int, minBackoff, maxBackoff time.Duration) time.Duration {
+	delay := maxBackoff
+	if attempt < 63 && minBackoff <= maxBackoff>>attempt {
+		delay = minBackoff << attempt
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay/2 + rand.N(delay/2+1) //nolint:gosec
+}
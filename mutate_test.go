@@ -0,0 +1,62 @@
+package aerospike
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffBins(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		before aerospike.BinMap
+		after  aerospike.BinMap
+		want   aerospike.BinMap
+	}{
+		{
+			name:   "nothing changed",
+			before: aerospike.BinMap{"int": int64(1), "map": map[any]any{"key": "value"}},
+			after:  aerospike.BinMap{"int": int64(1), "map": map[any]any{"key": "value"}},
+			want:   aerospike.BinMap{},
+		},
+		{
+			name:   "changed and added bins",
+			before: aerospike.BinMap{"int": int64(1), "text": "same"},
+			after:  aerospike.BinMap{"int": int64(2), "text": "same", "new": []any{int64(1)}},
+			want:   aerospike.BinMap{"int": int64(2), "new": []any{int64(1)}},
+		},
+		{
+			name:   "omitted bins are removed",
+			before: aerospike.BinMap{"int": int64(1), "text": "removed"},
+			after:  aerospike.BinMap{"int": int64(1)},
+			want:   aerospike.BinMap{"text": nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, diffBins(tt.before, tt.after))
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+	minBackoff, maxBackoff := 10*time.Millisecond, 100*time.Millisecond
+	for attempt := range 64 {
+		want := min(maxBackoff, minBackoff<<min(attempt, 10))
+		got := backoff(attempt, minBackoff, maxBackoff)
+		require.GreaterOrEqual(t, got, want/2)
+		require.LessOrEqual(t, got, want)
+	}
+	require.Zero(t, backoff(3, 0, 0))
+
+	for attempt := range 64 {
+		got := backoff(attempt, time.Hour, 2*time.Hour)
+		require.GreaterOrEqual(t, got, time.Hour/2, "attempt %d", attempt)
+		require.LessOrEqual(t, got, 2*time.Hour, "attempt %d", attempt)
+	}
+}
@@ -0,0 +1,143 @@
+package aerospike
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxBinNameLength is the longest bin name in bytes the server accepts.
+const maxBinNameLength = 15
+
+// shortNameHashLength is the length of the hash suffix of shortened bin and index names.
+const shortNameHashLength = 6
+
+var errBinName = errors.New("invalid bin name")
+
+// NamingStrategy returns the bin name of an exported struct field without a tag,
+// or an empty string to skip the field.
+type NamingStrategy func(field reflect.StructField) string
+
+// SnakeCase names bins in snake case, e.g. UserID becomes user_id and HTTPServer http_server.
+func SnakeCase(field reflect.StructField) string {
+	words := splitWords(field.Name)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+
+	return strings.Join(words, "_")
+}
+
+// CamelCase names bins in camel case, e.g. UserID becomes userID and HTTPServer httpServer.
+func CamelCase(field reflect.StructField) string {
+	words := splitWords(field.Name)
+	if len(words) == 0 {
+		return ""
+	}
+	words[0] = strings.ToLower(words[0])
+
+	return strings.Join(words, "")
+}
+
+// JSONNames names bins by json tags, skipping fields tagged `json:"-"`, and fields without
+// a json tag name by the fallback strategy, or by the field name if it is nil.
+func JSONNames(fallback NamingStrategy) NamingStrategy {
+	return func(field reflect.StructField) string {
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			name, _, _ := strings.Cut(tag, ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		if fallback == nil {
+			return field.Name
+		}
+
+		return fallback(field)
+	}
+}
+
+// WithNaming stores exported fields without a tag as bins named by the strategy,
+// e.g. SnakeCase or JSONNames(CamelCase). By default they are not stored.
+// Fields tagged with "-" are never stored.
+func WithNaming(strategy NamingStrategy) CodecOption {
+	return func(c *Codec) {
+		c.naming = strategy
+	}
+}
+
+// WithShortNames shortens bin names longer than the server limit of 15 bytes to their prefix followed
+// by an underscore and a hash of the full name, e.g. "notification_settings" becomes
+// "notifica_27fb09". By default such names are an error.
+func WithShortNames() CodecOption {
+	return func(c *Codec) {
+		c.shortNames = true
+	}
+}
+
+// fieldName returns the tag of the field with its bin name, which is empty for fields
+// that are not stored as bins.
+func (c *Codec) fieldName(field reflect.StructField) fieldTag {
+	tagValue, tagged := field.Tag.Lookup(c.tagName)
+	tag := parseTagValue(tagValue)
+	if tag.name == "-" {
+		return fieldTag{}
+	}
+	if !tagged && c.naming != nil && field.IsExported() {
+		tag.name = c.naming(field)
+	}
+	if len(tag.name) > maxBinNameLength && c.shortNames {
+		tag.name = shortName(tag.name, maxBinNameLength)
+	}
+
+	return tag
+}
+
+// shortName shortens the name to the limit of the server keeping its prefix.
+func shortName(name string, limit int) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+
+	prefix := name[:limit-shortNameHashLength-1]
+	for !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+
+	return fmt.Sprintf("%s_%0*x", prefix, shortNameHashLength, h.Sum32()>>(32-4*shortNameHashLength))
+}
+
+// splitWords splits the Go identifier into words at case changes, keeping acronyms together.
+func splitWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		boundary := cur == '_' ||
+			unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev)) ||
+			unicode.IsUpper(cur) && unicode.IsUpper(prev) && unicode.IsLower(next)
+		if !boundary {
+			continue
+		}
+		if word := strings.Trim(string(runes[start:i]), "_"); word != "" {
+			words = append(words, word)
+		}
+		start = i
+	}
+	if word := strings.Trim(string(runes[start:]), "_"); word != "" {
+		words = append(words, word)
+	}
+
+	return words
+}
@@ -0,0 +1,132 @@
+package aerospike
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamingStrategies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		field     string
+		wantSnake string
+		wantCamel string
+	}{
+		{field: "Name", wantSnake: "name", wantCamel: "name"},
+		{field: "ID", wantSnake: "id", wantCamel: "id"},
+		{field: "UserID", wantSnake: "user_id", wantCamel: "userID"},
+		{field: "HTTPServer", wantSnake: "http_server", wantCamel: "httpServer"},
+		{field: "Address2Line", wantSnake: "address2_line", wantCamel: "address2Line"},
+		{field: "Already_Snake", wantSnake: "already_snake", wantCamel: "alreadySnake"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			t.Parallel()
+
+			field := reflect.StructField{Name: tt.field}
+			require.Equal(t, tt.wantSnake, SnakeCase(field))
+			require.Equal(t, tt.wantCamel, CamelCase(field))
+		})
+	}
+}
+
+type namedStruct struct {
+	UserID     int
+	FullName   string `json:"name"`
+	Skipped    string `json:"-"`
+	Tagged     string `as:"tag"`
+	Ignored    string `as:"-"`
+	unexported string //nolint:unused
+}
+
+type longNamedStruct struct {
+	NotificationSettings string
+	NotificationChannels string
+}
+
+type duplicateNamedStruct struct {
+	UserID int
+	UserId int `json:"user_id"` //nolint:revive,stylecheck
+}
+
+func TestCodecNaming(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		codec   *Codec
+		v       any
+		want    aerospike.BinMap
+		wantErr error
+	}{
+		{
+			name:  "untagged fields are not stored",
+			codec: NewCodec(),
+			v:     &namedStruct{Tagged: "a"},
+			want:  aerospike.BinMap{"tag": "a"},
+		},
+		{
+			name:  "snake case",
+			codec: NewCodec(WithNaming(SnakeCase)),
+			v:     &namedStruct{UserID: 1, FullName: "b", Skipped: "c", Tagged: "a"},
+			want:  aerospike.BinMap{"user_id": int64(1), "full_name": "b", "skipped": "c", "tag": "a"},
+		},
+		{
+			name:  "json names",
+			codec: NewCodec(WithNaming(JSONNames(CamelCase))),
+			v:     &namedStruct{UserID: 1, FullName: "b", Tagged: "a"},
+			want:  aerospike.BinMap{"userID": int64(1), "name": "b", "tag": "a"},
+		},
+		{
+			name:    "long names",
+			codec:   NewCodec(WithNaming(SnakeCase)),
+			v:       &longNamedStruct{},
+			wantErr: errBinName,
+		},
+		{
+			name:  "short names",
+			codec: NewCodec(WithNaming(SnakeCase), WithShortNames()),
+			v:     &longNamedStruct{NotificationSettings: "a", NotificationChannels: "b"},
+			want:  aerospike.BinMap{"notifica_27fb09": "a", "notifica_993ab3": "b"},
+		},
+		{
+			name:    "duplicate names",
+			codec:   NewCodec(WithNaming(JSONNames(SnakeCase))),
+			v:       &duplicateNamedStruct{},
+			wantErr: errBinName,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.codec.Marshal(tt.v)
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.want, got)
+			if err != nil {
+				return
+			}
+
+			out := reflect.New(reflect.TypeOf(tt.v).Elem())
+			require.NoError(t, tt.codec.Unmarshal(&aerospike.Record{Bins: Normalize(got)}, out.Interface()))
+			require.Equal(t, tt.v, out.Interface())
+		})
+	}
+}
+
+func TestCodecNestedLongNames(t *testing.T) {
+	t.Parallel()
+
+	type outer struct {
+		Inner longNamedStruct `as:"inner"`
+	}
+	got, err := NewCodec(WithNaming(SnakeCase)).Marshal(&outer{Inner: longNamedStruct{NotificationSettings: "a"}})
+	require.NoError(t, err)
+	require.Equal(t, aerospike.BinMap{
+		"inner": map[string]any{"notification_settings": "a", "notification_channels": ""},
+	}, got)
+}
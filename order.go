@@ -0,0 +1,252 @@
+package aerospike
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+)
+
+// Ranks of value types in the order the server sorts values of different types.
+const (
+	rankNil = iota
+	rankBool
+	rankInt
+	rankString
+	rankList
+	rankMap
+	rankBytes
+	rankFloat
+	rankGeoJSON
+)
+
+// Normalize converts bins, e.g. the output of Marshal, into the shape the client returns them in from Get:
+// integers become int, floats float64, slices and arrays []any, maps and structs map[any]any,
+// time.Time Unix seconds. Bins with nil values are dropped, as the server does not store them.
+// Map keys that normalize to lists, maps or bytes, which cannot be keys of Go maps, become their
+// fmt representation.
+func Normalize(bins aerospike.BinMap) aerospike.BinMap {
+	return defaultCodec.Normalize(bins)
+}
+
+// Normalize is like the package function Normalize, but converts times and custom types the way the codec
+// marshals them.
+func (c *Codec) Normalize(bins aerospike.BinMap) aerospike.BinMap {
+	out := make(aerospike.BinMap, len(bins))
+	for name, v := range bins {
+		if v = clientValue(c.normalizeValue(v)); v != nil {
+			out[name] = v
+		}
+	}
+
+	return out
+}
+
+// Compare compares values in the order the server sorts list elements and map keys:
+// nil < bool < int < string < list < map < bytes < float < GeoJSON. Values of the same type are
+// compared naturally, lists element by element and then by length, maps by size and then by entries
+// sorted by key. Go values are normalized first, so int32(1) equals int64(1) and a struct
+// is compared as the map it is marshaled to.
+func Compare(a, b any) int {
+	return defaultCodec.Compare(a, b)
+}
+
+// Compare is like the package function Compare, but normalizes values the way the codec marshals them.
+func (c *Codec) Compare(a, b any) int {
+	return compareValues(c.normalizeValue(a), c.normalizeValue(b))
+}
+
+// normalizeValue converts v into the representation the server returns it in: integers become int64,
+// floats float64, slices and arrays []any, maps and structs map[any]any, time.Time Unix seconds,
+// values of registered types what they are encoded to.
+// Byte slices, strings, booleans, nil and aerospike.GeoJSONValue keep their types.
+func (c *Codec) normalizeValue(v any) any {
+	switch val := v.(type) {
+	case nil, bool, int64, float64, string, []byte, aerospike.GeoJSONValue:
+		return v
+	case time.Time:
+		return c.normalizeValue(c.encodeTime(val))
+	}
+
+	rv := reflect.ValueOf(v)
+	if encoded, ok, err := c.encodeCustom(rv); ok && err == nil {
+		return c.normalizeValue(encoded)
+	}
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return c.normalizeValue(rv.Elem().Interface())
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return integerValue(rv)
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Slice:
+		if rv.IsNil() {
+			return nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Bytes()
+		}
+		fallthrough
+	case reflect.Array:
+		out := make([]any, rv.Len())
+		for i := range out {
+			out[i] = c.normalizeValue(rv.Index(i).Interface())
+		}
+		return out
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil
+		}
+		out := make(map[any]any, rv.Len())
+		for iter := rv.MapRange(); iter.Next(); {
+			out[mapKey(c.normalizeValue(iter.Key().Interface()))] = c.normalizeValue(iter.Value().Interface())
+		}
+		return out
+	case reflect.Struct:
+		bins, err := c.marshalStruct(rv)
+		if err != nil {
+			return v
+		}
+		return c.normalizeValue(bins)
+	default:
+		return v
+	}
+}
+
+// mapKey returns the normalized key, or its fmt representation if it cannot be a key of a Go map.
+func mapKey(key any) any {
+	if key != nil && !reflect.TypeOf(key).Comparable() {
+		return fmt.Sprint(key)
+	}
+
+	return key
+}
+
+// clientValue converts integers of the normalized value into int, the type the client reads them back in.
+// Lists and maps are copied.
+func clientValue(v any) any {
+	switch val := v.(type) {
+	case int64:
+		return int(val)
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = clientValue(item)
+		}
+		return out
+	case map[any]any:
+		out := make(map[any]any, len(val))
+		for key, item := range val {
+			out[clientValue(key)] = clientValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// valueRank returns the rank of the normalized value type in the server ordering.
+func valueRank(v any) int {
+	switch v.(type) {
+	case nil:
+		return rankNil
+	case bool:
+		return rankBool
+	case int64:
+		return rankInt
+	case string:
+		return rankString
+	case []any:
+		return rankList
+	case map[any]any:
+		return rankMap
+	case []byte:
+		return rankBytes
+	case float64:
+		return rankFloat
+	default:
+		return rankGeoJSON
+	}
+}
+
+// compareValues compares normalized values the way the server orders them: values of different types
+// are ordered by type, lists element by element and then by length, maps by size and then by entries
+// sorted by key.
+func compareValues(a, b any) int {
+	if c := cmp.Compare(valueRank(a), valueRank(b)); c != 0 {
+		return c
+	}
+
+	switch a := a.(type) {
+	case bool:
+		return compareBool(a, b.(bool)) //nolint:forcetypeassert
+	case int64:
+		return cmp.Compare(a, b.(int64)) //nolint:forcetypeassert
+	case string:
+		return cmp.Compare(a, b.(string)) //nolint:forcetypeassert
+	case []any:
+		return slices.CompareFunc(a, b.([]any), compareValues) //nolint:forcetypeassert
+	case map[any]any:
+		return compareMaps(a, b.(map[any]any)) //nolint:forcetypeassert
+	case []byte:
+		return bytes.Compare(a, b.([]byte)) //nolint:forcetypeassert
+	case float64:
+		return cmp.Compare(a, b.(float64)) //nolint:forcetypeassert
+	case aerospike.GeoJSONValue:
+		bGeo, _ := b.(aerospike.GeoJSONValue)
+		return cmp.Compare(a, bGeo)
+	default:
+		return 0
+	}
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case b:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func compareMaps(a, b map[any]any) int {
+	if c := cmp.Compare(len(a), len(b)); c != 0 {
+		return c
+	}
+
+	aKeys, bKeys := sortedKeys(a), sortedKeys(b)
+	for i := range aKeys {
+		if c := compareValues(aKeys[i], bKeys[i]); c != 0 {
+			return c
+		}
+		if c := compareValues(a[aKeys[i]], b[bKeys[i]]); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+func sortedKeys(m map[any]any) []any {
+	keys := make([]any, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, compareValues)
+
+	return keys
+}
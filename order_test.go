@@ -0,0 +1,176 @@
+package aerospike
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeValue(t *testing.T) {
+	t.Parallel()
+	type nested struct {
+		A int8 `as:"a"`
+	}
+	tests := []struct {
+		name string
+		in   any
+		want any
+	}{
+		{name: "nil", in: nil, want: nil},
+		{name: "int", in: 5, want: int64(5)},
+		{name: "uint", in: uint16(5), want: int64(5)},
+		{name: "float32", in: float32(1.5), want: 1.5},
+		{name: "named string", in: aerospike.StringValue("a"), want: "a"},
+		{name: "bytes", in: []byte{1}, want: []byte{1}},
+		{name: "time", in: time.Unix(100, 0), want: int64(100)},
+		{name: "nil pointer", in: (*int)(nil), want: nil},
+		{name: "slice", in: []int32{1, 2}, want: []any{int64(1), int64(2)}},
+		{name: "map", in: map[string]uint{"a": 1}, want: map[any]any{"a": int64(1)}},
+		{name: "struct", in: nested{A: 1}, want: map[any]any{"a": int64(1)}},
+		{name: "geojson", in: aerospike.GeoJSONValue(`{}`), want: aerospike.GeoJSONValue(`{}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, defaultCodec.normalizeValue(tt.in))
+		})
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	t.Parallel()
+	ordered := []any{
+		nil,
+		false,
+		true,
+		int64(-5),
+		int64(3),
+		"",
+		"a",
+		"b",
+		[]any{},
+		[]any{int64(1)},
+		[]any{int64(1), int64(2)},
+		[]any{int64(2)},
+		map[any]any{},
+		map[any]any{"a": int64(2)},
+		map[any]any{"b": int64(1)},
+		map[any]any{"a": int64(1), "b": int64(1)},
+		[]byte{},
+		[]byte{1},
+		-1.5,
+		2.5,
+		aerospike.GeoJSONValue(`{"type":"Point"}`),
+	}
+	for i := range ordered {
+		for j := range ordered {
+			want := 0
+			switch {
+			case i < j:
+				want = -1
+			case i > j:
+				want = 1
+			}
+			require.Equal(t, want, compareValues(ordered[i], ordered[j]), "%v vs %v", ordered[i], ordered[j])
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+	type nested struct {
+		A uint8 `as:"a"`
+	}
+	v := &struct {
+		Int     int64            `as:"int"`
+		Float   float32          `as:"float"`
+		Time    time.Time        `as:"time"`
+		Tags    []int16          `as:"tags"`
+		Counts  map[uint]string  `as:"counts"`
+		Nested  nested           `as:"nested"`
+		Bytes   []byte           `as:"bytes"`
+		Missing map[string][]int `as:"missing"`
+	}{
+		Int:    -1,
+		Float:  1.5,
+		Time:   time.Unix(100, 0),
+		Tags:   []int16{1, 2},
+		Counts: map[uint]string{3: "c"},
+		Nested: nested{A: 4},
+		Bytes:  []byte{5},
+	}
+	bins, err := Marshal(v)
+	require.NoError(t, err)
+	bins["nil"] = nil
+
+	require.Equal(t, aerospike.BinMap{
+		"int":     -1,
+		"float":   1.5,
+		"time":    100,
+		"tags":    []any{1, 2},
+		"counts":  map[any]any{3: "c"},
+		"nested":  map[any]any{"a": 4},
+		"bytes":   []any{5}, // Marshal stores byte slices as lists
+		"missing": map[any]any{},
+	}, Normalize(bins))
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		a, b any
+		want int
+	}{
+		{name: "int types", a: int32(1), b: int64(1), want: 0},
+		{name: "int and float", a: 100, b: 1.5, want: -1},
+		{name: "string and list", a: "z", b: []string{"a"}, want: -1},
+		{name: "lists", a: []int{1, 2}, b: []any{1, 3}, want: -1},
+		{name: "map and bytes", a: map[string]int{"a": 1}, b: []byte{}, want: -1},
+		{name: "nil and bool", a: true, b: nil, want: 1},
+		{name: "struct and map", a: struct {
+			A int `as:"a"`
+		}{A: 1}, b: map[string]int{"a": 1}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, Compare(tt.a, tt.b))
+		})
+	}
+}
+
+func TestNormalizeUnhashableKeys(t *testing.T) {
+	t.Parallel()
+	bins := aerospike.BinMap{
+		"arrays": map[[2]int]string{{1, 2}: "a"},
+		"bytes":  map[[1]byte]int{{3}: 1},
+	}
+
+	require.Equal(t, aerospike.BinMap{
+		"arrays": map[any]any{"[1 2]": "a"},
+		"bytes":  map[any]any{"[3]": 1},
+	}, Normalize(bins))
+}
+
+func TestNormalizeCopies(t *testing.T) {
+	t.Parallel()
+	list := []any{int64(1), []any{int64(2)}}
+	bins := aerospike.BinMap{"list": list}
+
+	require.Equal(t, aerospike.BinMap{"list": []any{1, []any{2}}}, Normalize(bins))
+	require.Equal(t, []any{int64(1), []any{int64(2)}}, list)
+}
+
+func TestCodecNormalize(t *testing.T) {
+	t.Parallel()
+	codec := NewCodec(WithTimeFormat(TimeUnixMilli))
+	ts := time.UnixMilli(1500)
+
+	require.Equal(t, aerospike.BinMap{"time": 1500}, codec.Normalize(aerospike.BinMap{"time": ts}))
+	require.Equal(t, aerospike.BinMap{"time": 1}, Normalize(aerospike.BinMap{"time": ts}))
+	require.Equal(t, 1, codec.Compare(ts, time.UnixMilli(1000)))
+	require.Equal(t, 0, Compare(ts, time.UnixMilli(1000)))
+}
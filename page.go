@@ -0,0 +1,201 @@
+package aerospike
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"fmt"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+)
+
+const (
+	cursorVersion = 1
+	cursorMACSize = 16
+)
+
+// ErrInvalidCursor is returned when a page cursor is malformed, was altered,
+// was issued for another query or by an incompatible version of the library.
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+var errPageSize = errors.New("invalid page size")
+
+// Page is a single page of query results.
+type Page[T any] struct {
+	// Items are the records of the page decoded into T.
+	Items []T
+	// Cursor is an opaque URL-safe token that resumes the query right after the last item of the page.
+	// It is empty when there are no more records.
+	Cursor string
+}
+
+// PageOption configures QueryPage.
+type PageOption func(*pageOptions)
+
+type pageOptions struct {
+	secret []byte
+}
+
+// PageSecret sets the key used to sign cursors, so that clients cannot forge them.
+// Without it, cursors are only protected against accidental corruption.
+func PageSecret(secret []byte) PageOption {
+	return func(o *pageOptions) {
+		o.secret = secret
+	}
+}
+
+// cursorState is the serialized state of aerospike.PartitionFilter.
+type cursorState struct {
+	Begin      int
+	Count      int
+	Digest     []byte
+	Partitions []byte
+}
+
+// QueryPage executes the statement over all partitions and returns up to pageSize records decoded into T,
+// pageSize must be positive.
+// Pass the cursor of the previous page to get the next one, or an empty string to start from the beginning.
+// Page size is approximate, because aerospike splits it between the nodes of the cluster,
+// so a page may be smaller, or even empty, while the cursor is not.
+func QueryPage[T any](
+	ctx context.Context,
+	client Client,
+	policy *aerospike.QueryPolicy,
+	stmt *aerospike.Statement,
+	pageSize int,
+	cursor string,
+	opts ...PageOption,
+) (Page[T], error) {
+	if pageSize <= 0 {
+		return Page[T]{}, fmt.Errorf("page size %d is not positive: %w", pageSize, errPageSize)
+	}
+
+	var options pageOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var page Page[T]
+	filter := aerospike.NewPartitionFilterAll()
+	if cursor != "" {
+		var err error
+		filter, err = decodeCursor(cursor, stmt, options.secret)
+		if err != nil {
+			return page, err
+		}
+	}
+
+	queryPolicy := aerospike.NewQueryPolicy()
+	if policy != nil {
+		*queryPolicy = *policy
+	}
+	queryPolicy.MaxRecords = int64(pageSize)
+	if err := applyContext(ctx, &queryPolicy.BasePolicy); err != nil {
+		return page, err
+	}
+	statement := *stmt
+	if len(statement.BinNames) == 0 {
+		binNames, err := GetBinKeys(new(T))
+		if err != nil {
+			return page, err
+		}
+		statement.BinNames = binNames
+	}
+
+	rs, aerr := client.Query(queryPolicy, &statement, filter)
+	if aerr != nil {
+		return page, mapError(aerr)
+	}
+	var err error
+	decodeRecordset(ctx, rs, func(v T, itemErr error) bool {
+		if itemErr != nil {
+			err = itemErr
+			return false
+		}
+		page.Items = append(page.Items, v)
+		return true
+	})
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	if !filter.IsDone() {
+		page.Cursor, err = encodeCursor(filter, stmt, options.secret)
+		if err != nil {
+			return Page[T]{}, err
+		}
+	}
+
+	return page, nil
+}
+
+// encodeCursor serializes the partition filter into a token: version, MAC and gob-encoded state.
+// The MAC covers the namespace and set of the statement, so the token cannot be used for another query.
+func encodeCursor(filter *aerospike.PartitionFilter, stmt *aerospike.Statement, secret []byte) (string, error) {
+	partitions, aerr := filter.EncodeCursor()
+	if aerr != nil {
+		return "", aerr
+	}
+
+	var payload bytes.Buffer
+	err := gob.NewEncoder(&payload).Encode(cursorState{
+		Begin:      filter.Begin,
+		Count:      filter.Count,
+		Digest:     filter.Digest,
+		Partitions: partitions,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+
+	token := make([]byte, 0, 1+cursorMACSize+payload.Len())
+	token = append(token, cursorVersion)
+	token = append(token, cursorMAC(payload.Bytes(), stmt, secret)...)
+	token = append(token, payload.Bytes()...)
+
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+func decodeCursor(cursor string, stmt *aerospike.Statement, secret []byte) (*aerospike.PartitionFilter, error) {
+	token, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	if len(token) < 1+cursorMACSize {
+		return nil, fmt.Errorf("%w: too short", ErrInvalidCursor)
+	}
+	if token[0] != cursorVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidCursor, token[0])
+	}
+	mac, payload := token[1:1+cursorMACSize], token[1+cursorMACSize:]
+	if !hmac.Equal(mac, cursorMAC(payload, stmt, secret)) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidCursor)
+	}
+
+	var state cursorState
+	if err = gob.NewDecoder(bytes.NewReader(payload)).Decode(&state); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	filter := aerospike.NewPartitionFilterByRange(state.Begin, state.Count)
+	filter.Digest = state.Digest
+	if aerr := filter.DecodeCursor(state.Partitions); aerr != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, aerr)
+	}
+
+	return filter, nil
+}
+
+func cursorMAC(payload []byte, stmt *aerospike.Statement, secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	for _, part := range []string{stmt.Namespace, stmt.SetName} {
+		mac.Write([]byte(part))
+		mac.Write([]byte{0})
+	}
+	mac.Write(payload)
+
+	return mac.Sum(nil)[:cursorMACSize]
+}
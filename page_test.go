@@ -0,0 +1,74 @@
+package aerospike
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryPageSize(t *testing.T) {
+	t.Parallel()
+	stmt := aerospike.NewStatement("test", "users")
+	for _, size := range []int{0, -1} {
+		_, err := QueryPage[struct{}](context.Background(), nil, nil, stmt, size, "")
+		require.ErrorIs(t, err, errPageSize)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	t.Parallel()
+	stmt := aerospike.NewStatement("test", "set")
+	filter := aerospike.NewPartitionFilterByRange(10, 20)
+	filter.Partitions = []*aerospike.PartitionStatus{
+		{Id: 10, BVal: 5, Digest: []byte{1, 2, 3}},
+		{Id: 11, Retry: true},
+	}
+	secret := []byte("secret")
+
+	cursor, err := encodeCursor(filter, stmt, secret)
+	require.NoError(t, err)
+	require.NotContains(t, cursor, "=")
+	require.NotContains(t, cursor, "/")
+	require.NotContains(t, cursor, "+")
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+		got, err := decodeCursor(cursor, stmt, secret)
+		require.NoError(t, err)
+		require.Equal(t, filter.Begin, got.Begin)
+		require.Equal(t, filter.Count, got.Count)
+		require.Equal(t, filter.Partitions, got.Partitions)
+	})
+
+	tests := []struct {
+		name   string
+		cursor string
+		stmt   *aerospike.Statement
+		secret []byte
+	}{
+		{name: "not base64", cursor: "%%%", stmt: stmt, secret: secret},
+		{name: "too short", cursor: "AQ", stmt: stmt, secret: secret},
+		{name: "unknown version", cursor: "Ag" + cursor[2:], stmt: stmt, secret: secret},
+		{name: "altered", cursor: cursor[:len(cursor)-2] + flipChar(cursor[len(cursor)-2:]), stmt: stmt, secret: secret},
+		{name: "other query", cursor: cursor, stmt: aerospike.NewStatement("test", "other"), secret: secret},
+		{name: "other secret", cursor: cursor, stmt: stmt, secret: []byte("other")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := decodeCursor(tt.cursor, tt.stmt, tt.secret)
+			require.ErrorIs(t, err, ErrInvalidCursor)
+		})
+	}
+}
+
+func flipChar(s string) string {
+	if strings.HasPrefix(s, "A") {
+		return "B" + s[1:]
+	}
+
+	return "A" + s[1:]
+}
@@ -0,0 +1,295 @@
+package aerospike
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+)
+
+const pathSeparator = "."
+
+var errInvalidPath = errors.New("invalid projection path")
+
+// projectionStep is a single resolved segment of a projection path.
+type projectionStep struct {
+	// name is a bin name or a nested struct field tag.
+	name string
+	// mapKey is set when the step selects a key of a map.
+	mapKey any
+	// index is set when the step selects an element of a slice.
+	index int
+	kind  stepKind
+	typ   reflect.Type
+}
+
+type stepKind int
+
+const (
+	stepField stepKind = iota
+	stepMapKey
+	stepListIndex
+)
+
+// ProjectOps builds operations that read only the nested fields of T addressed by paths.
+// A path is a dot-separated list of segments: the first one is a bin name, the following ones
+// are nested struct tags, map keys or list indexes, e.g. "profile.address.city" or "items.0.price".
+// The result of client.Operate with these operations can be decoded with UnmarshalProjection.
+func ProjectOps[T any](paths ...string) ([]*aerospike.Operation, error) {
+	resolved, err := resolvePaths[T](paths)
+	if err != nil {
+		return nil, err
+	}
+
+	ops := make([]*aerospike.Operation, 0, len(resolved))
+	for _, steps := range resolved {
+		ops = append(ops, projectionOp(steps))
+	}
+
+	return ops, nil
+}
+
+// UnmarshalProjection puts values read with ProjectOps operations into the nested fields of v.
+// Paths must be the same and in the same order as the ones passed to ProjectOps.
+// Fields that are not addressed by paths are left untouched.
+func UnmarshalProjection[T any](record *aerospike.Record, v *T, paths ...string) error {
+	if record == nil {
+		return nil
+	}
+	if v == nil {
+		return fmt.Errorf("the provided variable must be a non-nil pointer to a struct: %w", errInputType)
+	}
+
+	resolved, err := resolvePaths[T](paths)
+	if err != nil {
+		return err
+	}
+
+	opsPerBin := make(map[string]int, len(resolved))
+	for _, steps := range resolved {
+		opsPerBin[steps[0].name]++
+	}
+
+	seen := make(map[string]int, len(resolved))
+	for i, steps := range resolved {
+		bin := steps[0].name
+		val, ok := record.Bins[bin]
+		if !ok {
+			continue
+		}
+		if opsPerBin[bin] > 1 {
+			results, ok := val.(aerospike.OpResults)
+			if !ok || seen[bin] >= len(results) {
+				return fmt.Errorf("unexpected result for bin %s: %w", bin, errInputType)
+			}
+			val = results[seen[bin]]
+			seen[bin]++
+		}
+		if val == nil {
+			continue
+		}
+
+		err = defaultCodec.setPath(reflect.ValueOf(v).Elem(), steps, val)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal path %s: %w", paths[i], err)
+		}
+	}
+
+	return nil
+}
+
+func resolvePaths[T any](paths []string) ([][]projectionStep, error) {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("projection target must be a struct: %w", errInputType)
+	}
+
+	resolved := make([][]projectionStep, len(paths))
+	for i, path := range paths {
+		steps, err := defaultCodec.resolvePath(typ, path)
+		if err != nil {
+			return nil, err
+		}
+		resolved[i] = steps
+	}
+
+	return resolved, nil
+}
+
+func (c *Codec) resolvePath(typ reflect.Type, path string) ([]projectionStep, error) {
+	segments := strings.Split(path, pathSeparator)
+	steps := make([]projectionStep, 0, len(segments))
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, fmt.Errorf("empty segment in %q: %w", path, errInvalidPath)
+		}
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+
+		step, err := c.resolveStep(typ, segment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %q: %w", path, err)
+		}
+		if len(steps) == 0 && step.kind != stepField {
+			return nil, fmt.Errorf("%q must start with a bin name: %w", path, errInvalidPath)
+		}
+		steps = append(steps, step)
+		typ = step.typ
+	}
+
+	return steps, nil
+}
+
+// resolveStep resolves the segment selecting a value of typ, which is a field name of structs
+// the codec stores as maps, a map key or a list index.
+func (c *Codec) resolveStep(typ reflect.Type, segment string) (projectionStep, error) {
+	switch {
+	case c.storesAsMap(typ):
+		if field, ok := c.fieldByName(typ, segment); ok {
+			return projectionStep{name: segment, kind: stepField, typ: field.Type}, nil
+		}
+		return projectionStep{}, fmt.Errorf("no field tagged %q in %s: %w", segment, typ, errInvalidPath)
+	case typ.Kind() == reflect.Map:
+		key, err := parseMapKey(typ.Key(), segment)
+		if err != nil {
+			return projectionStep{}, err
+		}
+		return projectionStep{name: segment, mapKey: key, kind: stepMapKey, typ: typ.Elem()}, nil
+	case typ.Kind() == reflect.Slice:
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 {
+			return projectionStep{}, fmt.Errorf("%q is not a valid list index: %w", segment, errInvalidPath)
+		}
+		return projectionStep{name: segment, index: index, kind: stepListIndex, typ: typ.Elem()}, nil
+	default:
+		return projectionStep{}, fmt.Errorf("cannot select %q from %s: %w", segment, typ, errInvalidPath)
+	}
+}
+
+// parseMapKey converts path segment into the value Marshal would use as a key of the map.
+func parseMapKey(typ reflect.Type, segment string) (any, error) {
+	switch typ.Kind() {
+	case reflect.String:
+		return segment, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		key, err := strconv.ParseInt(segment, 10, typ.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid %s map key: %w", segment, typ, errInvalidPath)
+		}
+		return key, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		key, err := strconv.ParseUint(segment, 10, typ.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid %s map key: %w", segment, typ, errInvalidPath)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("type %s is not supported: %w", typ.Kind().String(), errInputType)
+	}
+}
+
+func projectionOp(steps []projectionStep) *aerospike.Operation {
+	bin := steps[0].name
+	if len(steps) == 1 {
+		return aerospike.GetBinOp(bin)
+	}
+
+	ctx := make([]*aerospike.CDTContext, 0, len(steps)-2)
+	for _, step := range steps[1 : len(steps)-1] {
+		ctx = append(ctx, stepContext(step))
+	}
+
+	last := steps[len(steps)-1]
+	if last.kind == stepListIndex {
+		return aerospike.ListGetByIndexOp(bin, last.index, aerospike.ListReturnTypeValue, ctx...)
+	}
+
+	return aerospike.MapGetByKeyOp(bin, stepMapKeyValue(last), aerospike.MapReturnType.VALUE, ctx...)
+}
+
+func stepContext(step projectionStep) *aerospike.CDTContext {
+	if step.kind == stepListIndex {
+		return aerospike.CtxListIndex(step.index)
+	}
+
+	return aerospike.CtxMapKey(aerospike.NewValue(stepMapKeyValue(step)))
+}
+
+// stepMapKeyValue returns the key nested structs and maps are stored under.
+func stepMapKeyValue(step projectionStep) any {
+	if step.kind == stepField {
+		return step.name
+	}
+
+	return step.mapKey
+}
+
+// setPath walks dst along steps, allocating pointers, maps and slice elements on the way,
+// and unmarshals val into the last one.
+func (c *Codec) setPath(dst reflect.Value, steps []projectionStep, val any) error {
+	if len(steps) == 0 {
+		return c.unmarshalField(dst, val)
+	}
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		dst = dst.Elem()
+	}
+
+	step := steps[0]
+	switch step.kind {
+	case stepField:
+		if field, ok := c.fieldByName(dst.Type(), step.name); ok {
+			return c.setPath(dst.FieldByIndex(field.Index), steps[1:], val)
+		}
+		return fmt.Errorf("no field tagged %q in %s: %w", step.name, dst.Type(), errInvalidPath)
+	case stepMapKey:
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(dst.Type()))
+		}
+		key := reflect.ValueOf(step.mapKey).Convert(dst.Type().Key())
+		elem := reflect.New(dst.Type().Elem()).Elem()
+		if existing := dst.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		err := c.setPath(elem, steps[1:], val)
+		if err != nil {
+			return err
+		}
+		dst.SetMapIndex(key, elem)
+		return nil
+	default:
+		if step.index >= dst.Len() {
+			grown := reflect.MakeSlice(dst.Type(), step.index+1, step.index+1)
+			reflect.Copy(grown, dst)
+			dst.Set(grown)
+		}
+		return c.setPath(dst.Index(step.index), steps[1:], val)
+	}
+}
+
+// storesAsMap reports whether the codec stores values of the struct type as maps of their fields.
+func (c *Codec) storesAsMap(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && !isTimeType(typ) && !c.isRegistered(typ) && !c.implementsFallback(typ)
+}
+
+// fieldByName returns the field of the struct type the codec stores under the name.
+func (c *Codec) fieldByName(typ reflect.Type, name string) (reflect.StructField, bool) {
+	fields, _ := c.structFields(typ)
+	for _, f := range fields {
+		if f.tag.name == name {
+			return typ.Field(f.index), true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+func isTimeType(typ reflect.Type) bool {
+	return typ.PkgPath() == "time" && typ.Name() == "Time"
+}
@@ -0,0 +1,162 @@
+package aerospike
+
+import (
+	"testing"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/stretchr/testify/require"
+)
+
+type projectionAddress struct {
+	City   string `as:"city"`
+	Street string `as:"street"`
+}
+
+type projectionProfile struct {
+	Name    string             `as:"name"`
+	Address projectionAddress  `as:"address"`
+	Scores  map[int]int        `as:"scores"`
+	Tags    []string           `as:"tags"`
+	Home    *projectionAddress `as:"home"`
+}
+
+type projectionStruct struct {
+	ID      int               `as:"id"`
+	Profile projectionProfile `as:"profile"`
+}
+
+func TestProjectOps(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		paths   []string
+		wantLen int
+		wantErr bool
+	}{
+		{
+			name:    "whole bins",
+			paths:   []string{"id", "profile"},
+			wantLen: 2,
+		},
+		{
+			name:    "nested fields",
+			paths:   []string{"profile.address.city", "profile.scores.1", "profile.tags.2", "profile.home.street"},
+			wantLen: 4,
+		},
+		{
+			name:    "unknown bin",
+			paths:   []string{"unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown nested field",
+			paths:   []string{"profile.address.zip"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid map key",
+			paths:   []string{"profile.scores.one"},
+			wantErr: true,
+		},
+		{
+			name:    "negative list index",
+			paths:   []string{"profile.tags.-1"},
+			wantErr: true,
+		},
+		{
+			name:    "scalar cannot be traversed",
+			paths:   []string{"id.value"},
+			wantErr: true,
+		},
+		{
+			name:    "empty segment",
+			paths:   []string{"profile..city"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ProjectOps[projectionStruct](tt.paths...)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Len(t, got, tt.wantLen)
+		})
+	}
+}
+
+func TestUnmarshalProjection(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		paths []string
+		bins  aerospike.BinMap
+		in    projectionStruct
+		want  projectionStruct
+	}{
+		{
+			name:  "single nested field per bin",
+			paths: []string{"id", "profile.address.city"},
+			bins: aerospike.BinMap{
+				"id":      1,
+				"profile": "Berlin",
+			},
+			want: projectionStruct{
+				ID: 1,
+				Profile: projectionProfile{
+					Address: projectionAddress{City: "Berlin"},
+				},
+			},
+		},
+		{
+			name:  "several nested fields of one bin",
+			paths: []string{"profile.name", "profile.scores.7", "profile.tags.1", "profile.home.street"},
+			bins: aerospike.BinMap{
+				"profile": aerospike.OpResults{"john", 42, "b", "Main st."},
+			},
+			want: projectionStruct{
+				Profile: projectionProfile{
+					Name:   "john",
+					Scores: map[int]int{7: 42},
+					Tags:   []string{"", "b"},
+					Home:   &projectionAddress{Street: "Main st."},
+				},
+			},
+		},
+		{
+			name:  "nested struct as a whole",
+			paths: []string{"profile.address"},
+			bins: aerospike.BinMap{
+				"profile": map[any]any{"city": "Paris", "street": "Rivoli"},
+			},
+			want: projectionStruct{
+				Profile: projectionProfile{
+					Address: projectionAddress{City: "Paris", Street: "Rivoli"},
+				},
+			},
+		},
+		{
+			name:  "missing values keep existing fields",
+			paths: []string{"id", "profile.name"},
+			bins: aerospike.BinMap{
+				"profile": nil,
+			},
+			in: projectionStruct{
+				ID:      5,
+				Profile: projectionProfile{Name: "kept", Scores: map[int]int{1: 1}},
+			},
+			want: projectionStruct{
+				ID:      5,
+				Profile: projectionProfile{Name: "kept", Scores: map[int]int{1: 1}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := tt.in
+			err := UnmarshalProjection(&aerospike.Record{Bins: tt.bins}, &got, tt.paths...)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package aerospike
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"reflect"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+)
+
+// Query executes the statement and yields its records decoded into T.
+// If the statement has no bin names, only bins of T are requested.
+// The recordset is closed when the loop exits, including early breaks, and when ctx is done,
+// in which case the context error is yielded last. Server errors and decode failures wrapping ErrDecode
+// are yielded as errors, and the iteration continues unless the loop breaks.
+func Query[T any](
+	ctx context.Context,
+	client Client,
+	policy *aerospike.QueryPolicy,
+	stmt *aerospike.Statement,
+) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		queryPolicy := aerospike.NewQueryPolicy()
+		if policy != nil {
+			*queryPolicy = *policy
+		}
+		if err := applyContext(ctx, &queryPolicy.BasePolicy); err != nil {
+			yield(zero, err)
+			return
+		}
+		statement := *stmt
+		if len(statement.BinNames) == 0 {
+			binNames, err := GetBinKeys(&zero)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			statement.BinNames = binNames
+		}
+
+		rs, err := client.Query(queryPolicy, &statement, aerospike.NewPartitionFilterAll())
+		if err != nil {
+			yield(zero, mapError(err))
+			return
+		}
+		decodeRecordset(ctx, rs, yield)
+	}
+}
+
+// Scan reads all records of the set and yields them decoded into T.
+// Only bins of T are requested. Iteration follows the same rules as Query.
+func Scan[T any](
+	ctx context.Context,
+	client Client,
+	policy *aerospike.ScanPolicy,
+	namespace, set string,
+) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		scanPolicy := aerospike.NewScanPolicy()
+		if policy != nil {
+			*scanPolicy = *policy
+		}
+		if err := applyContext(ctx, &scanPolicy.BasePolicy); err != nil {
+			yield(zero, err)
+			return
+		}
+		binNames, err := GetBinKeys(&zero)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		rs, aerr := client.Scan(scanPolicy, aerospike.NewPartitionFilterAll(), namespace, set, binNames...)
+		if aerr != nil {
+			yield(zero, mapError(aerr))
+			return
+		}
+		decodeRecordset(ctx, rs, yield)
+	}
+}
+
+// decodeRecordset yields records of the recordset decoded into T until it is exhausted,
+// the consumer stops or ctx is done. The recordset is always closed.
+func decodeRecordset[T any](ctx context.Context, rs Recordset, yield func(T, error) bool) {
+	defer rs.Close() //nolint:errcheck
+
+	var zero T
+	meta := findMetaFields(reflect.TypeFor[T]())
+	results := rs.Results()
+	for {
+		select {
+		case <-ctx.Done():
+			yield(zero, ctx.Err())
+			return
+		case res, ok := <-results:
+			if !ok {
+				return
+			}
+			if res.Err != nil {
+				if !yield(zero, mapError(res.Err)) {
+					return
+				}
+				continue
+			}
+
+			v, err := decodeRecord[T](res.Record, meta)
+			if !yield(v, err) {
+				return
+			}
+		}
+	}
+}
+
+// decodeRecord unmarshals the record into T and fills its metadata fields.
+// The key field is set only if the record carries the user key.
+func decodeRecord[T any](record *aerospike.Record, meta metaFields) (T, error) {
+	var v T
+	if err := Unmarshal(record, &v); err != nil {
+		return v, fmt.Errorf("%w: %w", ErrDecode, err)
+	}
+
+	rv := reflect.ValueOf(&v).Elem()
+	if key := userKey(record.Key); key != nil {
+		if err := meta.setKey(rv, key); err != nil {
+			return v, fmt.Errorf("%w: %w", ErrDecode, err)
+		}
+	}
+	meta.setRecordMeta(rv, record)
+
+	return v, nil
+}
+
+// userKey returns the user key of the record key, nil if only the digest is known.
+func userKey(key *aerospike.Key) any {
+	if key == nil || key.Value() == nil {
+		return nil
+	}
+
+	return key.Value().GetObject()
+}
@@ -0,0 +1,58 @@
+package aerospike
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeRecord(t *testing.T) {
+	t.Parallel()
+	type record struct {
+		ID   int    `as:",key"`
+		Gen  int    `as:",generation"`
+		Name string `as:"name"`
+	}
+	meta := findMetaFields(reflect.TypeFor[record]())
+	key, err := aerospike.NewKey("test", "set", 7)
+	require.NoError(t, err)
+	digestOnly, err := aerospike.NewKeyWithDigest("test", "set", nil, key.Digest())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		in      *aerospike.Record
+		want    record
+		wantErr error
+	}{
+		{
+			name: "with user key",
+			in:   &aerospike.Record{Key: key, Bins: aerospike.BinMap{"name": "seven"}, Generation: 2},
+			want: record{ID: 7, Gen: 2, Name: "seven"},
+		},
+		{
+			name: "digest only",
+			in:   &aerospike.Record{Key: digestOnly, Bins: aerospike.BinMap{"name": "seven"}, Generation: 1},
+			want: record{Gen: 1, Name: "seven"},
+		},
+		{
+			name:    "decode failure",
+			in:      &aerospike.Record{Key: key, Bins: aerospike.BinMap{"name": map[any]any{}}},
+			wantErr: ErrDecode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := decodeRecord[record](tt.in, meta)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
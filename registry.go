@@ -0,0 +1,126 @@
+package aerospike
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// typeConverter holds the conversions of a registered type.
+type typeConverter struct {
+	encode func(reflect.Value) (any, error)
+	decode func(any) (reflect.Value, error)
+}
+
+func newTypeConverter[T any](encode func(T) (any, error), decode func(any) (T, error)) typeConverter {
+	return typeConverter{
+		encode: func(v reflect.Value) (any, error) {
+			return encode(v.Interface().(T)) //nolint:forcetypeassert
+		},
+		decode: func(v any) (reflect.Value, error) {
+			decoded, err := decode(v)
+			return reflect.ValueOf(&decoded).Elem(), err
+		},
+	}
+}
+
+// RegisterType registers conversions of values of T, a type the library does not support
+// or should store differently, with the default codec. They are used for struct fields, slice elements,
+// map keys and map values of type T or *T at any depth, by Marshal, Unmarshal and the helpers built on them.
+// Use WithType to register them with other codecs.
+//
+// encode returns a value the client can store: nil, bool, int64, float64, string, []byte,
+// []any or map[any]any. Nil values are not stored. decode gets the value the way the client reads it
+// back, e.g. integers as int, and is not called for nil values, which leave the zero T.
+// Registering T again replaces its conversions. Types are expected to be registered before use,
+// e.g. in init.
+func RegisterType[T any](encode func(T) (any, error), decode func(any) (T, error)) {
+	defaultCodec.register(reflect.TypeFor[T](), newTypeConverter(encode, decode))
+}
+
+func (c *Codec) register(typ reflect.Type, conv typeConverter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.types[typ] = conv
+	c.plain.Clear()
+}
+
+func (c *Codec) lookupType(typ reflect.Type) (typeConverter, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	conv, ok := c.types[typ]
+
+	return conv, ok
+}
+
+// isRegistered reports whether conversions of the type are registered.
+func (c *Codec) isRegistered(typ reflect.Type) bool {
+	_, ok := c.lookupType(typ)
+	return ok
+}
+
+// encodeRegistered converts v with the conversions registered for its type.
+// ok is false if the type is not registered.
+func (c *Codec) encodeRegistered(v reflect.Value) (encoded any, ok bool, err error) {
+	conv, ok := c.lookupType(v.Type())
+	if !ok {
+		return nil, false, nil
+	}
+
+	encoded, err = conv.encode(v)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to encode %s: %w", v.Type(), err)
+	}
+
+	return encoded, true, nil
+}
+
+// decodeRegistered sets v from val with the conversions registered for the type of v,
+// or the type v points to. ok is false if neither is registered.
+func (c *Codec) decodeRegistered(v reflect.Value, val any) (ok bool, err error) {
+	typ := v.Type()
+	conv, ok := c.lookupType(typ)
+	if !ok && typ.Kind() == reflect.Pointer {
+		conv, ok = c.lookupType(typ.Elem())
+	}
+	if !ok {
+		return false, nil
+	}
+	if val == nil {
+		return true, nil
+	}
+
+	decoded, err := conv.decode(val)
+	if err != nil {
+		return true, fmt.Errorf("failed to decode %s from %v: %w", decoded.Type(), val, err)
+	}
+	if decoded.Type() != typ {
+		ptr := reflect.New(decoded.Type())
+		ptr.Elem().Set(decoded)
+		decoded = ptr
+	}
+	v.Set(decoded)
+
+	return true, nil
+}
+
+// encodeCustom converts v with the conversions registered for its type, or the first fallback
+// interface it implements. ok is false if there are none.
+func (c *Codec) encodeCustom(v reflect.Value) (encoded any, ok bool, err error) {
+	if encoded, ok, err = c.encodeRegistered(v); ok {
+		return encoded, ok, err
+	}
+
+	return c.encodeFallback(v)
+}
+
+// decodeCustom sets v from val with the conversions registered for its type, or the first
+// fallback interface it implements. ok is false if there are none.
+func (c *Codec) decodeCustom(v reflect.Value, val any) (ok bool, err error) {
+	if ok, err = c.decodeRegistered(v, val); ok {
+		return ok, err
+	}
+
+	return c.decodeFallback(v, val)
+}
@@ -0,0 +1,69 @@
+package aerospike
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/stretchr/testify/require"
+)
+
+type point struct {
+	x, y int
+}
+
+var errPoint = errors.New("invalid point")
+
+func TestRegisterType(t *testing.T) {
+	t.Parallel()
+	RegisterType(
+		func(p point) (any, error) {
+			return fmt.Sprintf("%d:%d", p.x, p.y), nil
+		},
+		func(v any) (point, error) {
+			var p point
+			s, _ := v.(string)
+			if _, err := fmt.Sscanf(s, "%d:%d", &p.x, &p.y); err != nil {
+				return point{}, fmt.Errorf("%q: %w", s, errPoint)
+			}
+			return p, nil
+		},
+	)
+
+	type nested struct {
+		Point point `as:"point"`
+	}
+	type shape struct {
+		Point   point           `as:"point"`
+		Pointer *point          `as:"pointer"`
+		Points  []point         `as:"points"`
+		Moves   map[point]point `as:"moves"`
+		Nested  nested          `as:"nested"`
+	}
+	v := &shape{
+		Point:   point{1, 2},
+		Pointer: &point{3, 4},
+		Points:  []point{{5, 6}},
+		Moves:   map[point]point{{7, 8}: {9, 10}},
+		Nested:  nested{Point: point{11, 12}},
+	}
+
+	bins, err := Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, aerospike.BinMap{
+		"point":   "1:2",
+		"pointer": "3:4",
+		"points":  []any{"5:6"},
+		"moves":   map[any]any{"7:8": "9:10"},
+		"nested":  map[string]any{"point": "11:12"},
+	}, bins)
+	require.Equal(t, 0, Compare(point{1, 2}, "1:2"))
+
+	var got shape
+	require.NoError(t, Unmarshal(&aerospike.Record{Bins: Normalize(bins)}, &got))
+	require.Equal(t, v, &got)
+
+	err = Unmarshal(&aerospike.Record{Bins: aerospike.BinMap{"points": []any{"a"}}}, &got)
+	require.ErrorIs(t, err, errPoint)
+}
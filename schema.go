@@ -0,0 +1,285 @@
+package aerospike
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+)
+
+// ParticleType is the type the server stores a value as.
+type ParticleType string
+
+// Particle types of stored values. A value of an empty particle type cannot be stored,
+// or is of a registered type whose encoding returns nil for the zero value.
+const (
+	ParticleBool    ParticleType = "bool"
+	ParticleInteger ParticleType = "integer"
+	ParticleFloat   ParticleType = "float"
+	ParticleString  ParticleType = "string"
+	ParticleBlob    ParticleType = "blob"
+	ParticleList    ParticleType = "list"
+	ParticleMap     ParticleType = "map"
+	ParticleGeoJSON ParticleType = "geojson"
+	ParticleHLL     ParticleType = "hll"
+)
+
+// Encodings of values that are not stored as they are.
+const (
+	EncodingUnix       = "unix"
+	EncodingUnixMilli  = "unix-milli"
+	EncodingUnixNano   = "unix-nano"
+	EncodingRFC3339    = "rfc3339"
+	EncodingText       = "text"
+	EncodingBinary     = "binary"
+	EncodingSQL        = "sql"
+	EncodingRegistered = "registered"
+)
+
+// Schema describes how a struct type is stored by a codec.
+type Schema struct {
+	// Type is the Go type, e.g. "models.User".
+	Type string `json:"type"`
+	// Key describes the field holding the user key, nil if there is none.
+	Key *KeySchema `json:"key,omitempty"`
+	// Generation and TTL are the fields holding record metadata, empty if there are none.
+	Generation string `json:"generation,omitempty"`
+	TTL        string `json:"ttl,omitempty"`
+	// Bins are the bins in field order.
+	Bins []BinSchema `json:"bins"`
+}
+
+// KeySchema describes the field holding the user key.
+type KeySchema struct {
+	Field string       `json:"field"`
+	Type  ParticleType `json:"type"`
+}
+
+// BinSchema describes a bin, or an entry of the map a nested struct is stored as.
+type BinSchema struct {
+	// Name is the bin name or the map key.
+	Name string `json:"name"`
+	// Field is the Go field name.
+	Field string `json:"field"`
+	ValueSchema
+	OmitEmpty bool `json:"omitEmpty,omitempty"`
+	// Index and Collection are the secondary index declared on the bin, empty if there is none.
+	Index      string `json:"index,omitempty"`
+	Collection string `json:"collection,omitempty"`
+}
+
+// ValueSchema describes a stored value.
+type ValueSchema struct {
+	Type ParticleType `json:"type"`
+	// GoType is the Go type, e.g. "*int64", and Kind its kind, the kind of the element for pointers,
+	// e.g. "int64".
+	GoType string `json:"goType"`
+	Kind   string `json:"kind"`
+	// Encoding is the way values are converted into the particle type, one of the Encoding constants,
+	// empty if they are stored as they are.
+	Encoding string `json:"encoding,omitempty"`
+	// Nullable reports whether values may be stored as nil, e.g. nil pointers.
+	Nullable bool `json:"nullable,omitempty"`
+	// Key and Elem describe map keys and values, or list elements.
+	Key  *ValueSchema `json:"key,omitempty"`
+	Elem *ValueSchema `json:"elem,omitempty"`
+	// Fields are the entries of nested structs.
+	Fields []BinSchema `json:"fields,omitempty"`
+	// Ref is the type of a nested struct containing itself, whose fields are described above it.
+	Ref string `json:"ref,omitempty"`
+}
+
+// Describe returns the schema of T, or the struct T points to, with the default codec.
+// Values of registered and fallback types are described by encoding their zero values.
+// Use ValidateType to find fields that cannot be stored.
+func Describe[T any]() Schema {
+	return defaultCodec.Describe(reflect.TypeFor[T]())
+}
+
+// Describe returns the schema of the struct type, or the struct it points to, with the codec,
+// see the package-level Describe.
+func (c *Codec) Describe(typ reflect.Type) Schema {
+	typ = derefType(typ)
+	schema := Schema{Type: typ.String(), Bins: []BinSchema{}}
+	if typ.Kind() != reflect.Struct {
+		return schema
+	}
+
+	meta := findMetaFields(typ)
+	if meta.key >= 0 {
+		field := typ.Field(meta.key)
+		schema.Key = &KeySchema{Field: field.Name, Type: keyParticle(field.Type)}
+	}
+	if meta.generation >= 0 {
+		schema.Generation = typ.Field(meta.generation).Name
+	}
+	if meta.ttl >= 0 {
+		schema.TTL = typ.Field(meta.ttl).Name
+	}
+	schema.Bins = c.describeStruct(typ, map[reflect.Type]bool{})
+
+	return schema
+}
+
+func (c *Codec) describeStruct(typ reflect.Type, active map[reflect.Type]bool) []BinSchema {
+	active[typ] = true
+	defer delete(active, typ)
+
+	fields, _ := c.structFields(typ)
+	bins := make([]BinSchema, 0, len(fields))
+	for _, f := range fields {
+		bins = append(bins, BinSchema{
+			Name:        f.tag.name,
+			Field:       typ.Field(f.index).Name,
+			ValueSchema: c.describeValue(typ.Field(f.index).Type, active),
+			OmitEmpty:   f.tag.omitEmpty,
+			Index:       f.tag.index,
+			Collection:  f.tag.collection,
+		})
+	}
+
+	return bins
+}
+
+func (c *Codec) describeValue(typ reflect.Type, active map[reflect.Type]bool) ValueSchema {
+	schema := ValueSchema{GoType: typ.String(), Kind: derefType(typ).Kind().String()}
+	if typ.Kind() == reflect.Pointer && !c.isRegistered(typ) && !c.implementsFallback(typ) {
+		elem := c.describeValue(typ.Elem(), active)
+		schema.Type, schema.Encoding = elem.Type, elem.Encoding
+		schema.Key, schema.Elem, schema.Fields, schema.Ref = elem.Key, elem.Elem, elem.Fields, elem.Ref
+		schema.Nullable = true
+		return schema
+	}
+
+	if encoded, encoding, ok := c.encodeZero(typ); ok {
+		schema.Type, schema.Encoding = valueParticle(encoded), encoding
+		schema.Nullable = typ.Kind() == reflect.Pointer
+		return schema
+	}
+	if isTimeType(typ) {
+		schema.Type = valueParticle(c.encodeTime(time.Time{}))
+		schema.Encoding = c.timeEncoding()
+		return schema
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice:
+		elem := c.describeValue(typ.Elem(), active)
+		schema.Type, schema.Elem, schema.Nullable = ParticleList, &elem, c.nilPolicy == NilAsNil
+	case reflect.Map:
+		key, elem := c.describeValue(typ.Key(), active), c.describeValue(typ.Elem(), active)
+		schema.Type, schema.Key, schema.Elem, schema.Nullable = ParticleMap, &key, &elem, c.nilPolicy == NilAsNil
+	case reflect.Struct:
+		schema.Type = ParticleMap
+		if active[typ] {
+			schema.Ref = typ.String()
+		} else {
+			schema.Fields = c.describeStruct(typ, active)
+		}
+	default:
+		schema.Type = kindParticle(typ.Kind())
+	}
+
+	return schema
+}
+
+// encodeZero encodes the zero value of a registered or fallback type, or a pointer to it
+// for pointer types. ok is false for other types.
+func (c *Codec) encodeZero(typ reflect.Type) (encoded any, encoding string, ok bool) {
+	zero := reflect.New(typ).Elem()
+	if typ.Kind() == reflect.Pointer {
+		zero = reflect.New(typ.Elem())
+	}
+
+	if c.isRegistered(typ) {
+		encoded, _, _ = c.encodeRegistered(zero)
+		return encoded, EncodingRegistered, true
+	}
+	if !c.implementsFallback(typ) {
+		return nil, "", false
+	}
+	for _, fallback := range c.fallbackOrder() {
+		marshaler, _ := fallback.interfaces()
+		target := zero
+		if !typ.Implements(marshaler) {
+			target = reflect.New(typ)
+			target.Elem().Set(zero)
+			if !target.Type().Implements(marshaler) {
+				continue
+			}
+		}
+		encoded, _ = c.marshalFallback(fallback, target.Interface())
+		return encoded, fallback.encoding(), true
+	}
+
+	return nil, "", false
+}
+
+func (f Fallback) encoding() string {
+	switch f {
+	case FallbackText:
+		return EncodingText
+	case FallbackBinary:
+		return EncodingBinary
+	default:
+		return EncodingSQL
+	}
+}
+
+func (c *Codec) timeEncoding() string {
+	switch c.timeFormat {
+	case TimeUnixMilli:
+		return EncodingUnixMilli
+	case TimeUnixNano:
+		return EncodingUnixNano
+	case TimeRFC3339:
+		return EncodingRFC3339
+	default:
+		return EncodingUnix
+	}
+}
+
+// valueParticle returns the particle type the client stores the value as.
+func valueParticle(v any) ParticleType {
+	switch v.(type) {
+	case nil:
+		return ""
+	case aerospike.GeoJSONValue:
+		return ParticleGeoJSON
+	case aerospike.HLLValue:
+		return ParticleHLL
+	case []byte:
+		return ParticleBlob
+	default:
+		return kindParticle(reflect.TypeOf(v).Kind())
+	}
+}
+
+func kindParticle(kind reflect.Kind) ParticleType {
+	switch kind {
+	case reflect.Bool:
+		return ParticleBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return ParticleInteger
+	case reflect.Float32, reflect.Float64:
+		return ParticleFloat
+	case reflect.String:
+		return ParticleString
+	case reflect.Slice, reflect.Array:
+		return ParticleList
+	case reflect.Map, reflect.Struct:
+		return ParticleMap
+	default:
+		return ""
+	}
+}
+
+// keyParticle returns the particle type of user keys held by fields of the type, see metaFields.keyValue.
+func keyParticle(typ reflect.Type) ParticleType {
+	if typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8 {
+		return ParticleBlob
+	}
+
+	return kindParticle(typ.Kind())
+}
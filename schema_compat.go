@@ -0,0 +1,169 @@
+package aerospike
+
+import "fmt"
+
+// IncompatibilityKind is the kind of a breaking change between schemas.
+type IncompatibilityKind string
+
+// Kinds of breaking changes.
+const (
+	// IncompatibleKey is a removed user key field or a change of its particle type, which changes record digests.
+	IncompatibleKey IncompatibilityKind = "key"
+	// IncompatibleRemoved is a removed bin, whose stored values are no longer read.
+	IncompatibleRemoved IncompatibilityKind = "removed"
+	// IncompatibleRenamed is a field stored under another bin name.
+	IncompatibleRenamed IncompatibilityKind = "renamed"
+	// IncompatibleType is a change of the particle type of stored values.
+	IncompatibleType IncompatibilityKind = "type"
+	// IncompatibleNarrowed is a numeric kind that cannot hold every stored value, e.g. int64 changed to int32.
+	IncompatibleNarrowed IncompatibilityKind = "narrowed"
+	// IncompatibleEncoding is a change of the encoding of stored values, e.g. of times.
+	IncompatibleEncoding IncompatibilityKind = "encoding"
+	// IncompatibleRequired is an added bin that is neither nullable nor omitempty,
+	// so records stored before cannot be told from ones holding the zero value.
+	IncompatibleRequired IncompatibilityKind = "required"
+)
+
+// Incompatibility is a breaking change between schemas.
+type Incompatibility struct {
+	// Path is the bin, e.g. "address.city" for fields of nested structs, "tags[]" for list elements,
+	// "attrs{}" for map values and "attrs{key}" for map keys. It is empty for the user key.
+	Path    string              `json:"path"`
+	Kind    IncompatibilityKind `json:"kind"`
+	Message string              `json:"message"`
+}
+
+func (i Incompatibility) String() string {
+	if i.Path == "" {
+		return i.Message
+	}
+
+	return i.Path + ": " + i.Message
+}
+
+// CheckCompatibility returns the changes breaking reads of records stored with the old schema
+// by the code described by the current one, in bin order, nil if there are none.
+func CheckCompatibility(old, current Schema) []Incompatibility {
+	var c compatibilityChecker
+	switch {
+	case old.Key == nil:
+	case current.Key == nil:
+		c.report("", IncompatibleKey, "key field %s was removed", old.Key.Field)
+	case old.Key.Type != current.Key.Type:
+		c.report("", IncompatibleKey, "key type changed from %s to %s", old.Key.Type, current.Key.Type)
+	}
+	c.compareBins("", old.Bins, current.Bins)
+
+	return c.found
+}
+
+type compatibilityChecker struct {
+	found []Incompatibility
+}
+
+func (c *compatibilityChecker) report(path string, kind IncompatibilityKind, format string, args ...any) {
+	c.found = append(c.found, Incompatibility{Path: path, Kind: kind, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *compatibilityChecker) compareBins(prefix string, old, current []BinSchema) {
+	oldNames := make(map[string]bool, len(old))
+	for _, bin := range old {
+		oldNames[bin.Name] = true
+	}
+	byName := make(map[string]BinSchema, len(current))
+	byField := make(map[string]BinSchema, len(current))
+	for _, bin := range current {
+		byName[bin.Name] = bin
+		byField[bin.Field] = bin
+	}
+
+	renamed := make(map[string]bool)
+	for _, bin := range old {
+		if cur, ok := byName[bin.Name]; ok {
+			c.compareValues(prefix+bin.Name, bin.ValueSchema, cur.ValueSchema)
+			continue
+		}
+		if cur, ok := byField[bin.Field]; ok && !oldNames[cur.Name] {
+			renamed[cur.Name] = true
+			c.report(prefix+bin.Name, IncompatibleRenamed, "field %s is stored as %q", bin.Field, prefix+cur.Name)
+			continue
+		}
+		c.report(prefix+bin.Name, IncompatibleRemoved, "bin of field %s was removed", bin.Field)
+	}
+
+	for _, bin := range current {
+		if oldNames[bin.Name] || renamed[bin.Name] || bin.Nullable || bin.OmitEmpty {
+			continue
+		}
+		c.report(prefix+bin.Name, IncompatibleRequired,
+			"added bin of field %s has no default, make it nullable or omitempty", bin.Field)
+	}
+}
+
+func (c *compatibilityChecker) compareValues(path string, old, current ValueSchema) {
+	if old.Type != current.Type {
+		c.report(path, IncompatibleType, "particle type changed from %s to %s", old.Type, current.Type)
+		return
+	}
+	if old.Encoding != current.Encoding {
+		c.report(path, IncompatibleEncoding, "encoding changed from %s to %s",
+			encodingName(old.Encoding), encodingName(current.Encoding))
+		return
+	}
+	if narrowed(old.Kind, current.Kind) {
+		c.report(path, IncompatibleNarrowed, "%s narrowed to %s", old.Kind, current.Kind)
+	}
+
+	if old.Key != nil && current.Key != nil {
+		c.compareValues(path+"{key}", *old.Key, *current.Key)
+	}
+	if old.Elem != nil && current.Elem != nil {
+		suffix := "[]"
+		if old.Type == ParticleMap {
+			suffix = "{}"
+		}
+		c.compareValues(path+suffix, *old.Elem, *current.Elem)
+	}
+	if old.Fields != nil && current.Fields != nil {
+		c.compareBins(path+".", old.Fields, current.Fields)
+	}
+}
+
+func encodingName(encoding string) string {
+	if encoding == "" {
+		return "none"
+	}
+
+	return encoding
+}
+
+// numericKinds holds the sizes of numeric kinds, int and uint are stored as 64-bit integers.
+var numericKinds = map[string]struct {
+	bits   int
+	signed bool
+}{
+	"int": {64, true}, "int8": {8, true}, "int16": {16, true}, "int32": {32, true}, "int64": {64, true},
+	"uint": {64, false}, "uint8": {8, false}, "uint16": {16, false}, "uint32": {32, false}, "uint64": {64, false},
+	"float32": {32, true}, "float64": {64, true},
+}
+
+// narrowed reports whether the current numeric kind cannot hold every value of the old one.
+func narrowed(old, current string) bool {
+	from, ok := numericKinds[old]
+	if !ok {
+		return false
+	}
+	to, ok := numericKinds[current]
+	if !ok {
+		return false
+	}
+
+	switch {
+	case from.signed == to.signed:
+		return to.bits < from.bits
+	case from.signed:
+		return true
+	default:
+		return to.bits <= from.bits
+	}
+}
@@ -0,0 +1,123 @@
+package aerospike
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	t.Parallel()
+
+	type address struct {
+		City string `as:"city"`
+	}
+	type v1 struct {
+		ID      string           `as:",key"`
+		Name    string           `as:"name"`
+		Age     int32            `as:"age"`
+		Count   uint32           `as:"count"`
+		At      time.Time        `as:"at"`
+		Tags    []int64          `as:"tags"`
+		Scores  map[string]int64 `as:"scores"`
+		Address address          `as:"address"`
+	}
+	old := Describe[v1]()
+
+	tests := []struct {
+		name    string
+		current Schema
+		want    []Incompatibility
+	}{
+		{
+			name:    "same",
+			current: old,
+		},
+		{
+			name: "compatible changes",
+			current: Describe[struct {
+				ID      string           `as:",key"`
+				Name    *string          `as:"name"`
+				Age     int64            `as:"age"`
+				Count   int64            `as:"count"`
+				At      *time.Time       `as:"at"`
+				Tags    []int64          `as:"tags"`
+				Scores  map[string]int64 `as:"scores"`
+				Address *address         `as:"address"`
+				Email   string           `as:"email,omitempty"`
+				Phone   *string          `as:"phone"`
+			}](),
+		},
+		{
+			name: "breaking changes",
+			current: Describe[struct {
+				ID      int64             `as:",key"`
+				Name    string            `as:"full_name"`
+				Age     int16             `as:"age"`
+				Count   int32             `as:"count"`
+				At      time.Time         `as:"at"`
+				Tags    []string          `as:"tags"`
+				Scores  map[string]uint64 `as:"scores"`
+				Address struct {
+					Town string `as:"town"`
+				} `as:"address"`
+				Email string `as:"email"`
+			}](),
+			want: []Incompatibility{
+				{Kind: IncompatibleKey, Message: "key type changed from string to integer"},
+				{Path: "name", Kind: IncompatibleRenamed, Message: `field Name is stored as "full_name"`},
+				{Path: "age", Kind: IncompatibleNarrowed, Message: "int32 narrowed to int16"},
+				{Path: "count", Kind: IncompatibleNarrowed, Message: "uint32 narrowed to int32"},
+				{Path: "tags[]", Kind: IncompatibleType, Message: "particle type changed from integer to string"},
+				{Path: "scores{}", Kind: IncompatibleNarrowed, Message: "int64 narrowed to uint64"},
+				{Path: "address.city", Kind: IncompatibleRemoved, Message: "bin of field City was removed"},
+				{
+					Path: "address.town", Kind: IncompatibleRequired,
+					Message: "added bin of field Town has no default, make it nullable or omitempty",
+				},
+				{
+					Path: "email", Kind: IncompatibleRequired,
+					Message: "added bin of field Email has no default, make it nullable or omitempty",
+				},
+			},
+		},
+		{
+			name: "removed key and bins",
+			current: Describe[struct {
+				Name string `as:"name"`
+			}](),
+			want: []Incompatibility{
+				{Kind: IncompatibleKey, Message: "key field ID was removed"},
+				{Path: "age", Kind: IncompatibleRemoved, Message: "bin of field Age was removed"},
+				{Path: "count", Kind: IncompatibleRemoved, Message: "bin of field Count was removed"},
+				{Path: "at", Kind: IncompatibleRemoved, Message: "bin of field At was removed"},
+				{Path: "tags", Kind: IncompatibleRemoved, Message: "bin of field Tags was removed"},
+				{Path: "scores", Kind: IncompatibleRemoved, Message: "bin of field Scores was removed"},
+				{Path: "address", Kind: IncompatibleRemoved, Message: "bin of field Address was removed"},
+			},
+		},
+		{
+			name:    "time encoding",
+			current: NewCodec(WithTimeFormat(TimeUnixMilli)).Describe(reflect.TypeFor[v1]()),
+			want: []Incompatibility{
+				{Path: "at", Kind: IncompatibleEncoding, Message: "encoding changed from unix to unix-milli"},
+			},
+		},
+		{
+			name:    "time type",
+			current: NewCodec(WithTimeFormat(TimeRFC3339)).Describe(reflect.TypeFor[v1]()),
+			want: []Incompatibility{
+				{Path: "at", Kind: IncompatibleType, Message: "particle type changed from integer to string"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, CheckCompatibility(old, tt.current))
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package aerospike
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema renders the schema as a JSON Schema of the bins of records. Particle types, encodings,
+// Go types and index declarations are kept in "x-aerospike-*" and "x-go-*" annotations.
+func (s Schema) JSONSchema() ([]byte, error) {
+	r := &jsonSchemaRenderer{top: s.Type, defs: make(map[string]map[string]any), refs: make(map[string]bool)}
+	doc := r.object(s.Bins)
+	doc["$schema"] = jsonSchemaDialect
+	doc["title"] = s.Type
+	if s.Key != nil {
+		doc["x-aerospike-key"] = map[string]any{"field": s.Key.Field, "type": s.Key.Type}
+	}
+	if s.Generation != "" {
+		doc["x-aerospike-generation"] = s.Generation
+	}
+	if s.TTL != "" {
+		doc["x-aerospike-ttl"] = s.TTL
+	}
+
+	defs := make(map[string]any)
+	for ref := range r.refs {
+		if ref != s.Type {
+			defs[ref] = r.defs[ref]
+		}
+	}
+	if len(defs) > 0 {
+		doc["$defs"] = defs
+	}
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render JSON schema of %s: %w", s.Type, err)
+	}
+
+	return out, nil
+}
+
+type jsonSchemaRenderer struct {
+	top string
+	// defs holds the rendered nested structs by Go type, refs the ones referenced recursively.
+	defs map[string]map[string]any
+	refs map[string]bool
+}
+
+func (r *jsonSchemaRenderer) object(bins []BinSchema) map[string]any {
+	properties := make(map[string]any, len(bins))
+	for _, bin := range bins {
+		property := r.value(bin.ValueSchema)
+		property["x-go-field"] = bin.Field
+		if bin.Index != "" {
+			property["x-aerospike-index"] = bin.Index
+		}
+		if bin.Collection != "" {
+			property["x-aerospike-collection"] = bin.Collection
+		}
+		properties[bin.Name] = property
+	}
+
+	return map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+}
+
+func (r *jsonSchemaRenderer) value(v ValueSchema) map[string]any {
+	out := make(map[string]any)
+	var typ string
+	switch v.Type {
+	case ParticleBool:
+		typ = "boolean"
+	case ParticleInteger:
+		typ = "integer"
+	case ParticleFloat:
+		typ = "number"
+	case ParticleString:
+		typ = "string"
+		if v.Encoding == EncodingRFC3339 {
+			out["format"] = "date-time"
+		}
+	case ParticleBlob, ParticleHLL:
+		typ = "string"
+		out["contentEncoding"] = "base64"
+	case ParticleGeoJSON:
+		typ = "string"
+		out["contentMediaType"] = "application/geo+json"
+	case ParticleList:
+		typ = "array"
+		if v.Elem != nil {
+			out["items"] = r.value(*v.Elem)
+		}
+	case ParticleMap:
+		switch {
+		case v.Ref != "":
+			out = r.ref(v.Ref)
+		case v.Fields != nil || v.Key == nil:
+			out = r.object(v.Fields)
+			r.defs[strings.TrimPrefix(v.GoType, "*")] = out
+		default:
+			typ = "object"
+			out["additionalProperties"] = r.value(*v.Elem)
+			out["x-aerospike-key-type"] = v.Key.Type
+		}
+	}
+
+	if typ != "" {
+		out["type"] = typ
+	}
+	if v.Nullable {
+		if typ == "" {
+			out = map[string]any{"anyOf": []any{out, map[string]any{"type": "null"}}}
+		} else {
+			out["type"] = []string{typ, "null"}
+		}
+	}
+	out["x-aerospike-type"] = v.Type
+	out["x-go-type"] = v.GoType
+	if v.Encoding != "" {
+		out["x-aerospike-encoding"] = v.Encoding
+	}
+
+	return out
+}
+
+func (r *jsonSchemaRenderer) ref(typ string) map[string]any {
+	if typ == r.top {
+		return map[string]any{"$ref": "#"}
+	}
+	r.refs[typ] = true
+
+	return map[string]any{"$ref": "#/$defs/" + typ}
+}
+
+// Markdown renders the schema as a Markdown table of bins, with rows for fields of nested structs
+// named like "address.city", "items[].name" and "attrs{}.name" for lists and maps of structs.
+func (s Schema) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", s.Type)
+
+	var meta []string
+	if s.Key != nil {
+		meta = append(meta, fmt.Sprintf("Key: field `%s`, %s.", s.Key.Field, s.Key.Type))
+	}
+	if s.Generation != "" {
+		meta = append(meta, fmt.Sprintf("Generation: field `%s`.", s.Generation))
+	}
+	if s.TTL != "" {
+		meta = append(meta, fmt.Sprintf("TTL: field `%s`.", s.TTL))
+	}
+	if len(meta) > 0 {
+		fmt.Fprintf(&b, "%s\n\n", strings.Join(meta, " "))
+	}
+
+	b.WriteString("| Bin | Type | Go type | Field | Notes |\n| --- | --- | --- | --- | --- |\n")
+	markdownRows(&b, s.Bins, "", "")
+
+	return b.String()
+}
+
+func markdownRows(b *strings.Builder, bins []BinSchema, prefix, fieldPrefix string) {
+	for _, bin := range bins {
+		name, field := prefix+bin.Name, fieldPrefix+bin.Field
+		notes := markdownNotes(bin.ValueSchema)
+		if bin.OmitEmpty {
+			notes = append([]string{"omitempty"}, notes...)
+		}
+		if bin.Index != "" {
+			notes = append(notes, "index="+bin.Index)
+		}
+		if bin.Collection != "" {
+			notes = append(notes, "collection="+bin.Collection)
+		}
+		fmt.Fprintf(b, "| `%s` | %s | `%s` | `%s` | %s |\n",
+			name, markdownType(bin.ValueSchema), bin.GoType, field, strings.Join(notes, ", "))
+
+		switch nested := bin.ValueSchema; {
+		case nested.Fields != nil:
+			markdownRows(b, nested.Fields, name+".", field+".")
+		case nested.Type == ParticleList && nested.Elem != nil && nested.Elem.Fields != nil:
+			markdownRows(b, nested.Elem.Fields, name+"[].", field+"[].")
+		case nested.Type == ParticleMap && nested.Elem != nil && nested.Elem.Fields != nil:
+			markdownRows(b, nested.Elem.Fields, name+"{}.", field+"{}.")
+		}
+	}
+}
+
+func markdownType(v ValueSchema) string {
+	switch {
+	case v.Type == "":
+		return "unsupported"
+	case v.Type == ParticleList && v.Elem != nil:
+		return "list of " + markdownType(*v.Elem)
+	case v.Type == ParticleMap && v.Key != nil && v.Elem != nil:
+		return "map of " + markdownType(*v.Key) + " to " + markdownType(*v.Elem)
+	default:
+		return string(v.Type)
+	}
+}
+
+func markdownNotes(v ValueSchema) []string {
+	var notes []string
+	if v.Nullable {
+		notes = append(notes, "nullable")
+	}
+	if v.Encoding != "" {
+		notes = append(notes, "encoding="+v.Encoding)
+	}
+	if v.Ref != "" {
+		notes = append(notes, "recursive "+v.Ref)
+	}
+
+	return notes
+}
@@ -0,0 +1,209 @@
+package aerospike
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaAddress struct {
+	City string `as:"city"`
+}
+
+type schemaNode struct {
+	Name     string       `as:"name"`
+	Children []schemaNode `as:"children"`
+}
+
+type schemaRecord struct {
+	ID       string                   `as:",key"`
+	Gen      uint32                   `as:",generation"`
+	TTL      time.Duration            `as:",ttl"`
+	Email    string                   `as:"email,index=string"`
+	Age      *int8                    `as:"age,omitempty"`
+	At       time.Time                `as:"at"`
+	Data     []byte                   `as:"data"`
+	Tags     []string                 `as:"tags,index=string,collection=list"`
+	Scores   map[string]float64       `as:"scores"`
+	Level    level                    `as:"level"`
+	Checksum checksum                 `as:"checksum"`
+	Location string                   `as:"-"`
+	Address  schemaAddress            `as:"address"`
+	Homes    map[int64]*schemaAddress `as:"homes"`
+	Tree     schemaNode               `as:"tree"`
+}
+
+func TestDescribe(t *testing.T) {
+	t.Parallel()
+
+	str := func(goType string) ValueSchema {
+		return ValueSchema{Type: ParticleString, GoType: goType, Kind: "string"}
+	}
+	address := []BinSchema{{Name: "city", Field: "City", ValueSchema: str("string")}}
+	want := Schema{
+		Type:       "aerospike.schemaRecord",
+		Key:        &KeySchema{Field: "ID", Type: ParticleString},
+		Generation: "Gen",
+		TTL:        "TTL",
+		Bins: []BinSchema{
+			{Name: "email", Field: "Email", ValueSchema: str("string"), Index: "string"},
+			{
+				Name: "age", Field: "Age", OmitEmpty: true,
+				ValueSchema: ValueSchema{Type: ParticleInteger, GoType: "*int8", Kind: "int8", Nullable: true},
+			},
+			{
+				Name: "at", Field: "At",
+				ValueSchema: ValueSchema{Type: ParticleInteger, GoType: "time.Time", Kind: "struct", Encoding: EncodingUnix},
+			},
+			{
+				Name: "data", Field: "Data",
+				ValueSchema: ValueSchema{
+					Type: ParticleList, GoType: "[]uint8", Kind: "slice",
+					Elem: &ValueSchema{Type: ParticleInteger, GoType: "uint8", Kind: "uint8"},
+				},
+			},
+			{
+				Name: "tags", Field: "Tags", Index: "string", Collection: "list",
+				ValueSchema: ValueSchema{Type: ParticleList, GoType: "[]string", Kind: "slice", Elem: ptr(str("string"))},
+			},
+			{
+				Name: "scores", Field: "Scores",
+				ValueSchema: ValueSchema{
+					Type: ParticleMap, GoType: "map[string]float64", Kind: "map", Key: ptr(str("string")),
+					Elem: &ValueSchema{Type: ParticleFloat, GoType: "float64", Kind: "float64"},
+				},
+			},
+			{
+				Name: "level", Field: "Level",
+				ValueSchema: ValueSchema{Type: ParticleString, GoType: "aerospike.level", Kind: "struct", Encoding: EncodingText},
+			},
+			{
+				Name: "checksum", Field: "Checksum",
+				ValueSchema: ValueSchema{Type: ParticleBlob, GoType: "aerospike.checksum", Kind: "struct", Encoding: EncodingBinary},
+			},
+			{
+				Name: "address", Field: "Address",
+				ValueSchema: ValueSchema{Type: ParticleMap, GoType: "aerospike.schemaAddress", Kind: "struct", Fields: address},
+			},
+			{
+				Name: "homes", Field: "Homes",
+				ValueSchema: ValueSchema{
+					Type: ParticleMap, GoType: "map[int64]*aerospike.schemaAddress", Kind: "map",
+					Key: &ValueSchema{Type: ParticleInteger, GoType: "int64", Kind: "int64"},
+					Elem: &ValueSchema{
+						Type: ParticleMap, GoType: "*aerospike.schemaAddress", Kind: "struct", Nullable: true, Fields: address,
+					},
+				},
+			},
+			{
+				Name: "tree", Field: "Tree",
+				ValueSchema: ValueSchema{
+					Type: ParticleMap, GoType: "aerospike.schemaNode", Kind: "struct",
+					Fields: []BinSchema{
+						{Name: "name", Field: "Name", ValueSchema: str("string")},
+						{
+							Name: "children", Field: "Children",
+							ValueSchema: ValueSchema{
+								Type: ParticleList, GoType: "[]aerospike.schemaNode", Kind: "slice",
+								Elem: &ValueSchema{
+									Type: ParticleMap, GoType: "aerospike.schemaNode", Kind: "struct", Ref: "aerospike.schemaNode",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	require.Equal(t, want, Describe[schemaRecord]())
+	require.Equal(t, want, Describe[*schemaRecord]())
+}
+
+func TestCodecDescribe(t *testing.T) {
+	t.Parallel()
+
+	type place struct {
+		Location string    `db:"location"`
+		At       time.Time `db:"at"`
+		Tags     []string  `db:"tags"`
+	}
+	codec := NewCodec(
+		WithTagName("db"),
+		WithTimeFormat(TimeRFC3339),
+		WithNilPolicy(NilAsNil),
+		WithType(
+			func(s string) (any, error) { return aerospike.NewGeoJSONValue(s), nil },
+			func(v any) (string, error) { return v.(string), nil }, //nolint:forcetypeassert
+		),
+	)
+
+	bins := codec.Describe(reflect.TypeFor[place]()).Bins
+	require.Len(t, bins, 3)
+	require.Equal(t, ValueSchema{
+		Type: ParticleGeoJSON, GoType: "string", Kind: "string", Encoding: EncodingRegistered,
+	}, bins[0].ValueSchema)
+	require.Equal(t, ValueSchema{
+		Type: ParticleString, GoType: "time.Time", Kind: "struct", Encoding: EncodingRFC3339,
+	}, bins[1].ValueSchema)
+	require.True(t, bins[2].Nullable)
+}
+
+func TestSchemaJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	out, err := Describe[schemaRecord]().JSONSchema()
+	require.NoError(t, err)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(out, &doc))
+	require.Equal(t, jsonSchemaDialect, doc["$schema"])
+	require.Equal(t, map[string]any{"field": "ID", "type": "string"}, doc["x-aerospike-key"])
+
+	properties := doc["properties"].(map[string]any) //nolint:forcetypeassert
+	require.Equal(t, map[string]any{
+		"type": []any{"integer", "null"}, "x-aerospike-type": "integer", "x-go-type": "*int8", "x-go-field": "Age",
+	}, properties["age"])
+	require.Equal(t, map[string]any{
+		"type": "array", "items": map[string]any{"type": "string", "x-aerospike-type": "string", "x-go-type": "string"},
+		"x-aerospike-type": "list", "x-go-type": "[]string", "x-go-field": "Tags",
+		"x-aerospike-index": "string", "x-aerospike-collection": "list",
+	}, properties["tags"])
+
+	children := properties["tree"].(map[string]any)["properties"].(map[string]any)["children"] //nolint:forcetypeassert
+	require.Equal(t, map[string]any{"$ref": "#/$defs/aerospike.schemaNode", "x-aerospike-type": "map", "x-go-type": "aerospike.schemaNode"},
+		children.(map[string]any)["items"]) //nolint:forcetypeassert
+	require.Contains(t, doc["$defs"], "aerospike.schemaNode")
+}
+
+func TestSchemaMarkdown(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		ID      string           `as:",key"`
+		Name    string           `as:"name,omitempty"`
+		At      time.Time        `as:"at"`
+		Emails  []string         `as:"emails,index=string,collection=list"`
+		Address *schemaAddress   `as:"address"`
+		Homes   []schemaAddress  `as:"homes"`
+		Scores  map[string]int32 `as:"scores"`
+	}
+
+	require.Equal(t, "## aerospike.user\n\n"+
+		"Key: field `ID`, string.\n\n"+
+		"| Bin | Type | Go type | Field | Notes |\n"+
+		"| --- | --- | --- | --- | --- |\n"+
+		"| `name` | string | `string` | `Name` | omitempty |\n"+
+		"| `at` | integer | `time.Time` | `At` | encoding=unix |\n"+
+		"| `emails` | list of string | `[]string` | `Emails` | index=string, collection=list |\n"+
+		"| `address` | map | `*aerospike.schemaAddress` | `Address` | nullable |\n"+
+		"| `address.city` | string | `string` | `Address.City` |  |\n"+
+		"| `homes` | list of map | `[]aerospike.schemaAddress` | `Homes` |  |\n"+
+		"| `homes[].city` | string | `string` | `Homes[].City` |  |\n"+
+		"| `scores` | map of string to integer | `map[string]int32` | `Scores` |  |\n",
+		Describe[user]().Markdown())
+}
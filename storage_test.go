@@ -161,3 +161,294 @@ func setupAerospike() (*aerospike.Client, func(), error) {
 
 	return client, cleanup, nil
 }
+
+func TestAerospikeStore(t *testing.T) {
+	t.Parallel()
+	client, cleanup, err := setupAerospike()
+	require.NoError(t, err)
+	defer cleanup()
+
+	store, err := NewStore[storeStruct](WrapClient(client), "test", "store")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	want := storeStruct{ID: uuid.NewString(), Name: "John", Age: 30}
+	require.NoError(t, store.Put(ctx, &want))
+
+	got, err := store.Get(ctx, want.ID)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+
+	exists, err := store.Exists(ctx, want.ID)
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.NoError(t, store.Touch(ctx, want.ID))
+
+	many, err := store.GetMany(ctx, []any{want.ID, uuid.NewString()})
+	require.NoError(t, err)
+	require.Equal(t, []*storeStruct{&want, nil}, many)
+
+	existed, err := store.Delete(ctx, want.ID)
+	require.NoError(t, err)
+	require.True(t, existed)
+
+	exists, err = store.Exists(ctx, want.ID)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestAerospikeStoreSemantics(t *testing.T) {
+	t.Parallel()
+	client, cleanup, err := setupAerospike()
+	require.NoError(t, err)
+	defer cleanup()
+
+	type versioned struct {
+		ID   string `as:",key"`
+		Gen  uint32 `as:",generation"`
+		Name string `as:"name"`
+	}
+	store, err := NewStore[versioned](WrapClient(client), "test", "semantics")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	v := versioned{ID: uuid.NewString(), Name: "first"}
+	require.ErrorIs(t, store.Update(ctx, &v), ErrNotFound)
+	require.ErrorIs(t, store.Replace(ctx, &v), ErrNotFound)
+	require.NoError(t, store.Create(ctx, &v))
+	require.ErrorIs(t, store.Create(ctx, &v), ErrAlreadyExists)
+
+	got, err := store.Get(ctx, v.ID)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), got.Gen)
+
+	got.Name = "second"
+	require.NoError(t, store.Update(ctx, &got))
+	got.Name = "stale"
+	require.ErrorIs(t, store.Update(ctx, &got), ErrConflict)
+
+	got, err = store.Get(ctx, v.ID)
+	require.NoError(t, err)
+	require.Equal(t, "second", got.Name)
+	require.NoError(t, store.Upsert(ctx, &versioned{ID: v.ID, Name: "third"}))
+
+	_, err = store.Get(ctx, uuid.NewString())
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestAerospikeMutate(t *testing.T) {
+	t.Parallel()
+	client, cleanup, err := setupAerospike()
+	require.NoError(t, err)
+	defer cleanup()
+
+	type counter struct {
+		Value int    `as:"value"`
+		Note  string `as:"note,omitempty"`
+	}
+	ctx := context.Background()
+	key, err := aerospike.NewKey("test", "mutate", uuid.NewString())
+	require.NoError(t, err)
+
+	_, err = Mutate(ctx, WrapClient(client), key, func(*counter) error { return nil })
+	require.ErrorIs(t, err, ErrNotFound)
+
+	const workers = 8
+	errs := make(chan error, workers)
+	for range workers {
+		go func() {
+			_, err := Mutate(ctx, WrapClient(client), key, func(c *counter) error {
+				c.Value++
+				return nil
+			}, MutateCreateIfMissing(), MutateMaxRetries(100))
+			errs <- err
+		}()
+	}
+	for range workers {
+		require.NoError(t, <-errs)
+	}
+
+	got, err := Mutate(ctx, WrapClient(client), key, func(c *counter) error {
+		c.Note = "done"
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, counter{Value: workers, Note: "done"}, got)
+}
+
+func TestAerospikeBatchDecode(t *testing.T) {
+	t.Parallel()
+	client, cleanup, err := setupAerospike()
+	require.NoError(t, err)
+	defer cleanup()
+
+	keys := make([]*aerospike.Key, 3)
+	for i := range keys {
+		keys[i], err = aerospike.NewKey("test", "batch", uuid.NewString())
+		require.NoError(t, err)
+	}
+	bins, err := Marshal(&allFieldsStruct)
+	require.NoError(t, err)
+	require.NoError(t, client.Put(nil, keys[0], bins))
+	require.NoError(t, client.Put(nil, keys[2], aerospike.BinMap{"time": "not a time"}))
+
+	got, err := BatchDecode[testStruct](context.Background(), WrapClient(client), nil, keys)
+	require.NoError(t, err)
+	require.Len(t, got, len(keys))
+	require.NoError(t, got[0].Err)
+	require.Equal(t, allFieldsStruct, got[0].Value)
+	require.ErrorIs(t, got[1].Err, ErrNotFound)
+	require.ErrorIs(t, got[2].Err, ErrDecode)
+}
+
+func TestAerospikeBatchPut(t *testing.T) {
+	t.Parallel()
+	client, cleanup, err := setupAerospike()
+	require.NoError(t, err)
+	defer cleanup()
+
+	items := make([]batchPutStruct, 25)
+	for i := range items {
+		items[i] = batchPutStruct{ID: i, TTL: time.Hour, Name: uuid.NewString()}
+	}
+
+	report, err := BatchPut(context.Background(), WrapClient(client), "test", "batch_put", items,
+		BatchPutChunkSize(4), BatchPutConcurrency(2))
+	require.NoError(t, err)
+	require.Empty(t, report.Failed())
+
+	items[3].Gen = 100
+	report, err = BatchPut(context.Background(), WrapClient(client), "test", "batch_put", items[2:4])
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, report.Failed())
+	require.Equal(t, []int{1}, report.Permanent())
+	require.ErrorIs(t, report.Results[1].Err, ErrConflict)
+
+	store, err := NewStore[batchPutStruct](WrapClient(client), "test", "batch_put")
+	require.NoError(t, err)
+	got, err := store.Get(context.Background(), 5)
+	require.NoError(t, err)
+	require.Equal(t, items[5].Name, got.Name)
+	require.Equal(t, uint32(1), got.Gen)
+	require.InDelta(t, time.Hour.Seconds(), got.TTL.Seconds(), 5)
+}
+
+func TestAerospikeQueryScan(t *testing.T) {
+	t.Parallel()
+	client, cleanup, err := setupAerospike()
+	require.NoError(t, err)
+	defer cleanup()
+
+	items := make([]batchPutStruct, 10)
+	for i := range items {
+		items[i] = batchPutStruct{ID: i, Name: fmt.Sprint(i)}
+	}
+	report, err := BatchPut(context.Background(), WrapClient(client), "test", "query", items)
+	require.NoError(t, err)
+	require.Empty(t, report.Failed())
+
+	ctx := context.Background()
+	var scanned []batchPutStruct
+	for v, err := range Scan[batchPutStruct](ctx, WrapClient(client), nil, "test", "query") {
+		require.NoError(t, err)
+		scanned = append(scanned, v)
+	}
+	require.Len(t, scanned, len(items))
+
+	var queried int
+	for _, err := range Query[batchPutStruct](ctx, WrapClient(client), nil, aerospike.NewStatement("test", "query")) {
+		require.NoError(t, err)
+		queried++
+		if queried == 3 {
+			break
+		}
+	}
+	require.Equal(t, 3, queried)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	for _, err := range Scan[batchPutStruct](canceled, WrapClient(client), nil, "test", "query") {
+		require.ErrorIs(t, err, context.Canceled)
+	}
+}
+
+func TestAerospikeEnsureIndexes(t *testing.T) {
+	t.Parallel()
+	client, cleanup, err := setupAerospike()
+	require.NoError(t, err)
+	defer cleanup()
+
+	type indexed struct {
+		ID    string   `as:"id,key"`
+		Email string   `as:"email,index=string"`
+		Age   int      `as:"age,index=numeric"`
+		Tags  []string `as:"tags,index=string,collection=list"`
+	}
+
+	ctx := context.Background()
+	_, aerr := client.CreateIndex(nil, "test", "indexed", "manual", "name", aerospike.STRING)
+	require.NoError(t, aerr)
+
+	report, err := EnsureIndexes[indexed](ctx, client, "test", "indexed")
+	require.NoError(t, err)
+	require.Len(t, report.Created, 3)
+	require.Empty(t, report.Present)
+	require.True(t, report.HasDrift())
+	require.Len(t, report.Undeclared, 1)
+	require.Equal(t, "manual", report.Undeclared[0].Name)
+
+	report, err = EnsureIndexes[indexed](ctx, client, "test", "indexed")
+	require.NoError(t, err)
+	require.Empty(t, report.Created)
+	require.Len(t, report.Present, 3)
+}
+
+func TestAerospikeCondParity(t *testing.T) {
+	t.Parallel()
+	client, cleanup, err := setupAerospike()
+	require.NoError(t, err)
+	defer cleanup()
+
+	records := []aerospike.BinMap{
+		{"id": 0, "age": 30, "name": "Alice", "tags": []any{"go", 1}},
+		{"id": 1, "age": "30", "name": "bob", "attrs": map[any]any{"color": "red"}},
+		{"id": 2, "score": 1.5, "tags": []any{1.0}},
+		{"id": 3},
+	}
+	for i, bins := range records {
+		key, err := aerospike.NewKey("test", "cond", i)
+		require.NoError(t, err)
+		require.NoError(t, client.Put(nil, key, bins))
+	}
+
+	conds := []Cond{
+		CondEq(CondBin("age"), 30),
+		CondNot(CondEq(CondBin("age"), 30)),
+		CondOr(CondNot(CondEq(CondBin("age"), 1)), CondBinExists("score")),
+		CondAnd(CondGt(CondBin("name"), "B"), CondRegex("name", "^b", aerospike.ExpRegexFlagNONE)),
+		CondListContains("tags", 1),
+		CondNot(CondListContains("tags", 1.0)),
+		CondMapContainsKey("attrs", "color"),
+		CondLt(CondBin("tags"), []any{"go", 2}),
+		CondNot(CondBinExists("age")),
+	}
+	for i, cond := range conds {
+		exp, err := cond.Expression()
+		require.NoError(t, err)
+		policy := aerospike.NewQueryPolicy()
+		policy.FilterExpression = exp
+		rs, aerr := client.Query(policy, aerospike.NewStatement("test", "cond"))
+		require.NoError(t, aerr)
+
+		server := map[int]bool{}
+		for res := range rs.Results() {
+			require.NoError(t, res.Err)
+			server[res.Record.Bins["id"].(int)] = true
+		}
+		for id, bins := range records {
+			local, err := cond.Match(bins)
+			require.NoError(t, err)
+			require.Equal(t, server[id], local, "condition %d, record %d", i, id)
+		}
+	}
+}
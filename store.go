@@ -0,0 +1,340 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/aerospike/aerospike-client-go/v8/types"
+)
+
+var errNoKeyField = errors.New("struct has no key field")
+
+// Store is a typed repository of records of type T kept in a single set.
+// The user key of a record is taken from the field tagged with the "key" option,
+// e.g. `as:",key"` or `as:"id,key"` if the key should be stored in a bin as well.
+// An optional integer field tagged `as:",generation"` receives the generation of read records,
+// and if it is non-zero, writes fail with ErrConflict when the record was modified in between.
+// An optional integer or time.Duration field tagged `as:",ttl"` receives the TTL of read records,
+// and if it is non-zero, it overrides the expiration of the write policy.
+//
+// Errors with KEY_EXISTS_ERROR, KEY_NOT_FOUND_ERROR and GENERATION_ERROR result codes
+// match ErrAlreadyExists, ErrNotFound and ErrConflict respectively.
+type Store[T any] struct {
+	client    Client
+	namespace string
+	set       string
+	binNames  []string
+	meta      metaFields
+
+	readPolicy  *aerospike.BasePolicy
+	writePolicy *aerospike.WritePolicy
+	batchPolicy *aerospike.BatchPolicy
+}
+
+// StoreOption configures a Store.
+type StoreOption func(*storeOptions)
+
+type storeOptions struct {
+	readPolicy  *aerospike.BasePolicy
+	writePolicy *aerospike.WritePolicy
+	batchPolicy *aerospike.BatchPolicy
+}
+
+// WithReadPolicy sets the default policy for Get and Exists calls.
+func WithReadPolicy(policy *aerospike.BasePolicy) StoreOption {
+	return func(o *storeOptions) {
+		o.readPolicy = policy
+	}
+}
+
+// WithWritePolicy sets the default policy for Put, Delete and Touch calls.
+func WithWritePolicy(policy *aerospike.WritePolicy) StoreOption {
+	return func(o *storeOptions) {
+		o.writePolicy = policy
+	}
+}
+
+// WithBatchPolicy sets the default policy for GetMany calls.
+func WithBatchPolicy(policy *aerospike.BatchPolicy) StoreOption {
+	return func(o *storeOptions) {
+		o.batchPolicy = policy
+	}
+}
+
+// NewStore creates a Store of T records in the given namespace and set.
+// T must be a struct with a key field.
+func NewStore[T any](client Client, namespace, set string, opts ...StoreOption) (*Store[T], error) {
+	options := storeOptions{
+		readPolicy:  aerospike.NewPolicy(),
+		writePolicy: aerospike.NewWritePolicy(0, 0),
+		batchPolicy: aerospike.NewBatchPolicy(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("store type must be a struct: %w", errInputType)
+	}
+	meta := findMetaFields(typ)
+	if meta.key < 0 {
+		return nil, fmt.Errorf("%s: %w", typ, errNoKeyField)
+	}
+	binNames, err := GetBinKeys(new(T))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store[T]{
+		client:      client,
+		namespace:   namespace,
+		set:         set,
+		binNames:    binNames,
+		meta:        meta,
+		readPolicy:  options.readPolicy,
+		writePolicy: options.writePolicy,
+		batchPolicy: options.batchPolicy,
+	}, nil
+}
+
+// Get reads the record stored under the user key and decodes it into T.
+// Only bins of T are requested from the server.
+func (s *Store[T]) Get(ctx context.Context, key any) (T, error) {
+	var out T
+	policy := *s.readPolicy
+	if err := applyContext(ctx, &policy); err != nil {
+		return out, err
+	}
+	asKey, err := s.key(key)
+	if err != nil {
+		return out, err
+	}
+
+	record, aerr := s.client.Get(&policy, asKey, s.binNames...)
+	if aerr != nil {
+		return out, mapError(aerr)
+	}
+	if err = s.decode(record, key, &out); err != nil {
+		return out, err
+	}
+
+	return out, nil
+}
+
+// GetMany reads records stored under the user keys in a single batch.
+// Result is in the order of keys, records that were not found or were filtered out
+// by the filter expression of the batch policy are nil. Other failures of records fail the call.
+func (s *Store[T]) GetMany(ctx context.Context, keys []any) ([]*T, error) {
+	policy := *s.batchPolicy
+	if err := applyContext(ctx, &policy.BasePolicy); err != nil {
+		return nil, err
+	}
+	asKeys := make([]*aerospike.Key, len(keys))
+	for i := range keys {
+		var err error
+		asKeys[i], err = s.key(keys[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	results, aerr := batchDecode(s.client, &policy, asKeys, s.binNames, func(i int, record *aerospike.Record, v *T) error {
+		return s.decode(record, keys[i], v)
+	})
+	if aerr != nil {
+		return nil, mapError(aerr)
+	}
+
+	out := make([]*T, len(results))
+	for i := range results {
+		switch {
+		case errors.Is(results[i].Err, ErrNotFound), errors.Is(results[i].Err, ErrFilteredOut):
+			continue
+		case results[i].Err != nil:
+			return nil, results[i].Err
+		}
+		out[i] = &results[i].Value
+	}
+
+	return out, nil
+}
+
+// Put writes v under the user key taken from its key field
+// using the record exists action of the write policy.
+func (s *Store[T]) Put(ctx context.Context, v *T) error {
+	return s.write(ctx, v, s.writePolicy.RecordExistsAction)
+}
+
+// Create writes v only if there is no record under its key, otherwise it fails with ErrAlreadyExists.
+func (s *Store[T]) Create(ctx context.Context, v *T) error {
+	return s.write(ctx, v, aerospike.CREATE_ONLY)
+}
+
+// Replace replaces all bins of the existing record with the bins of v.
+// It fails with ErrNotFound if there is no record under the key of v.
+func (s *Store[T]) Replace(ctx context.Context, v *T) error {
+	return s.write(ctx, v, aerospike.REPLACE_ONLY)
+}
+
+// Update writes the bins of v into the existing record, keeping the other bins intact.
+// It fails with ErrNotFound if there is no record under the key of v.
+func (s *Store[T]) Update(ctx context.Context, v *T) error {
+	return s.write(ctx, v, aerospike.UPDATE_ONLY)
+}
+
+// Upsert creates the record or writes the bins of v into the existing one.
+func (s *Store[T]) Upsert(ctx context.Context, v *T) error {
+	return s.write(ctx, v, aerospike.UPDATE)
+}
+
+func (s *Store[T]) write(ctx context.Context, v *T, action aerospike.RecordExistsAction) error {
+	policy := *s.writePolicy
+	if err := applyContext(ctx, &policy.BasePolicy); err != nil {
+		return err
+	}
+	key, bins, err := s.encode(v)
+	if err != nil {
+		return err
+	}
+
+	policy.RecordExistsAction = action
+	if gen := s.meta.generationOf(reflect.ValueOf(v).Elem()); gen != 0 && action != aerospike.CREATE_ONLY {
+		policy.GenerationPolicy = aerospike.EXPECT_GEN_EQUAL
+		policy.Generation = gen
+	}
+	if expiration := s.meta.expirationOf(reflect.ValueOf(v).Elem()); expiration != 0 {
+		policy.Expiration = expiration
+	}
+	if aerr := s.client.Put(&policy, key, bins); aerr != nil {
+		return mapError(aerr)
+	}
+
+	return nil
+}
+
+// Delete removes the record stored under the user key and reports whether it existed.
+func (s *Store[T]) Delete(ctx context.Context, key any) (bool, error) {
+	policy := *s.writePolicy
+	if err := applyContext(ctx, &policy.BasePolicy); err != nil {
+		return false, err
+	}
+	asKey, err := s.key(key)
+	if err != nil {
+		return false, err
+	}
+
+	existed, aerr := s.client.Delete(&policy, asKey)
+	if aerr != nil {
+		return false, mapError(aerr)
+	}
+
+	return existed, nil
+}
+
+// Exists reports whether a record is stored under the user key.
+func (s *Store[T]) Exists(ctx context.Context, key any) (bool, error) {
+	policy := aerospike.NewWritePolicy(0, 0)
+	policy.BasePolicy = *s.readPolicy
+	if err := applyContext(ctx, &policy.BasePolicy); err != nil {
+		return false, err
+	}
+	asKey, err := s.key(key)
+	if err != nil {
+		return false, err
+	}
+
+	_, aerr := s.client.Operate(policy, asKey, aerospike.GetHeaderOp())
+	switch {
+	case aerr == nil:
+		return true, nil
+	case aerr.Matches(types.KEY_NOT_FOUND_ERROR):
+		return false, nil
+	default:
+		return false, mapError(aerr)
+	}
+}
+
+// Touch resets the TTL of the record stored under the user key
+// to the expiration of the write policy and increments its generation.
+// It fails with ErrNotFound if there is no such record.
+func (s *Store[T]) Touch(ctx context.Context, key any) error {
+	policy := *s.writePolicy
+	if err := applyContext(ctx, &policy.BasePolicy); err != nil {
+		return err
+	}
+	asKey, err := s.key(key)
+	if err != nil {
+		return err
+	}
+
+	if _, aerr := s.client.Operate(&policy, asKey, aerospike.TouchOp()); aerr != nil {
+		return mapError(aerr)
+	}
+
+	return nil
+}
+
+func (s *Store[T]) key(value any) (*aerospike.Key, error) {
+	key, err := aerospike.NewKey(s.namespace, s.set, value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key: %w", err)
+	}
+
+	return key, nil
+}
+
+func (s *Store[T]) encode(v *T) (*aerospike.Key, aerospike.BinMap, error) {
+	if v == nil {
+		return nil, nil, fmt.Errorf("the provided variable must be a non-nil pointer to a struct: %w", errInputType)
+	}
+	keyValue, err := s.meta.keyValue(reflect.ValueOf(v).Elem())
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := s.key(keyValue)
+	if err != nil {
+		return nil, nil, err
+	}
+	bins, err := Marshal(v)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, bins, nil
+}
+
+// decode unmarshals the record into v and fills its metadata fields.
+func (s *Store[T]) decode(record *aerospike.Record, key any, v *T) error {
+	if err := Unmarshal(record, v); err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v).Elem()
+	if err := s.meta.setKey(rv, key); err != nil {
+		return err
+	}
+	s.meta.setRecordMeta(rv, record)
+
+	return nil
+}
+
+// applyContext fails if ctx is already done and limits the total timeout of the policy
+// by the deadline of ctx.
+func applyContext(ctx context.Context, policy *aerospike.BasePolicy) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout := time.Until(deadline)
+		if policy.TotalTimeout == 0 || timeout < policy.TotalTimeout {
+			policy.TotalTimeout = timeout
+		}
+	}
+
+	return nil
+}
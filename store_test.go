@@ -0,0 +1,103 @@
+package aerospike
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aerospike/aerospike-client-go/v8"
+	"github.com/stretchr/testify/require"
+)
+
+type storeStruct struct {
+	ID   string `as:",key"`
+	Name string `as:"name"`
+	Age  int    `as:"age,omitempty"`
+}
+
+func TestNewStore(t *testing.T) {
+	t.Parallel()
+	t.Run("bins and key field", func(t *testing.T) {
+		t.Parallel()
+		store, err := NewStore[storeStruct](nil, "test", "users")
+		require.NoError(t, err)
+		require.Equal(t, []string{"name", "age"}, store.binNames)
+		require.Equal(t, metaFields{key: 0, generation: -1, ttl: -1}, store.meta)
+	})
+	t.Run("no key field", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewStore[innerStruct](nil, "test", "users")
+		require.ErrorIs(t, err, errNoKeyField)
+	})
+	t.Run("not a struct", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewStore[int](nil, "test", "users")
+		require.ErrorIs(t, err, errInputType)
+	})
+}
+
+func TestStoreEncodeDecode(t *testing.T) {
+	t.Parallel()
+	store, err := NewStore[storeStruct](nil, "test", "users")
+	require.NoError(t, err)
+
+	key, bins, err := store.encode(&storeStruct{ID: "john", Name: "John"})
+	require.NoError(t, err)
+	require.Equal(t, "john", key.Value().GetObject())
+	require.Equal(t, "users", key.SetName())
+	require.Equal(t, aerospike.BinMap{"name": "John"}, bins)
+
+	var got storeStruct
+	err = store.decode(&aerospike.Record{Bins: aerospike.BinMap{"name": "John", "age": 30}}, "john", &got)
+	require.NoError(t, err)
+	require.Equal(t, storeStruct{ID: "john", Name: "John", Age: 30}, got)
+
+	err = store.decode(&aerospike.Record{Bins: aerospike.BinMap{}}, 65, &got)
+	require.ErrorIs(t, err, errInputType)
+}
+
+func TestStoreGeneration(t *testing.T) {
+	t.Parallel()
+	type genStruct struct {
+		ID  int    `as:",key"`
+		Gen uint32 `as:",generation"`
+		Bin string `as:"bin"`
+	}
+	store, err := NewStore[genStruct](nil, "test", "gen")
+	require.NoError(t, err)
+	require.Equal(t, metaFields{key: 0, generation: 1, ttl: -1}, store.meta)
+
+	var got genStruct
+	err = store.decode(&aerospike.Record{Bins: aerospike.BinMap{"bin": "value"}, Generation: 3}, 1, &got)
+	require.NoError(t, err)
+	require.Equal(t, genStruct{ID: 1, Gen: 3, Bin: "value"}, got)
+	require.Equal(t, uint32(3), store.meta.generationOf(reflect.ValueOf(got)))
+
+	_, bins, err := store.encode(&got)
+	require.NoError(t, err)
+	require.Equal(t, aerospike.BinMap{"bin": "value"}, bins)
+
+	withoutGen, err := NewStore[storeStruct](nil, "test", "gen")
+	require.NoError(t, err)
+	require.Zero(t, withoutGen.meta.generationOf(reflect.ValueOf(storeStruct{})))
+}
+
+func TestApplyContext(t *testing.T) {
+	t.Parallel()
+	t.Run("deadline limits total timeout", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		policy := aerospike.NewPolicy()
+		require.NoError(t, applyContext(ctx, policy))
+		require.LessOrEqual(t, policy.TotalTimeout, time.Second)
+		require.Positive(t, policy.TotalTimeout)
+	})
+	t.Run("done context", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, applyContext(ctx, aerospike.NewPolicy()), context.Canceled)
+	})
+}
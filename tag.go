@@ -0,0 +1,65 @@
+package aerospike
+
+import (
+	"reflect"
+	"strings"
+)
+
+const (
+	tagOptionOmitEmpty  = "omitempty"
+	tagOptionKey        = "key"
+	tagOptionGeneration = "generation"
+	tagOptionTTL        = "ttl"
+	tagOptionIndex      = "index"
+	tagOptionCollection = "collection"
+)
+
+// fieldTag is a parsed "as" struct tag.
+type fieldTag struct {
+	// name is the bin name, empty for fields that are not stored as bins.
+	name      string
+	omitEmpty bool
+	// key marks the field holding the user key of the record.
+	key bool
+	// generation marks the field holding the generation of the record.
+	generation bool
+	// ttl marks the field holding the time-to-live of the record.
+	ttl bool
+	// index is the type of the secondary index declared on the bin, e.g. "string" or "numeric".
+	index string
+	// collection is the collection type of the secondary index, e.g. "list" or "mapkeys".
+	collection string
+}
+
+// parseTag parses "as" tag of the struct field. Tag consists of the bin name
+// followed by comma-separated options, e.g. `as:"name,omitempty"`, `as:",key"` or `as:"email,index=string"`.
+func parseTag(field reflect.StructField) fieldTag {
+	return parseTagValue(field.Tag.Get(structTag))
+}
+
+// parseTagValue parses the value of a struct tag in the format of "as" tags.
+func parseTagValue(tag string) fieldTag {
+	name, opts, _ := strings.Cut(tag, ",")
+	parsed := fieldTag{name: name}
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		opt, value, _ := strings.Cut(opt, "=")
+		switch opt {
+		case tagOptionOmitEmpty:
+			parsed.omitEmpty = true
+		case tagOptionKey:
+			parsed.key = true
+		case tagOptionGeneration:
+			parsed.generation = true
+		case tagOptionTTL:
+			parsed.ttl = true
+		case tagOptionIndex:
+			parsed.index = value
+		case tagOptionCollection:
+			parsed.collection = value
+		}
+	}
+
+	return parsed
+}
@@ -0,0 +1,59 @@
+package aerospike
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTag(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		tag  reflect.StructTag
+		want fieldTag
+	}{
+		{
+			name: "no tag",
+			want: fieldTag{},
+		},
+		{
+			name: "bin name",
+			tag:  `as:"bin"`,
+			want: fieldTag{name: "bin"},
+		},
+		{
+			name: "omitempty",
+			tag:  `as:"bin,omitempty"`,
+			want: fieldTag{name: "bin", omitEmpty: true},
+		},
+		{
+			name: "key only",
+			tag:  `as:",key"`,
+			want: fieldTag{key: true},
+		},
+		{
+			name: "generation",
+			tag:  `as:",generation"`,
+			want: fieldTag{generation: true},
+		},
+		{
+			name: "several options",
+			tag:  `as:"id,key,omitempty"`,
+			want: fieldTag{name: "id", omitEmpty: true, key: true},
+		},
+		{
+			name: "index",
+			tag:  `as:"tags,index=string,collection=list"`,
+			want: fieldTag{name: "tags", index: "string", collection: "list"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := parseTag(reflect.StructField{Tag: tt.tag})
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -3,7 +3,7 @@ package aerospike
 import (
 	"fmt"
 	"reflect"
-	"time"
+	"slices"
 
 	"github.com/aerospike/aerospike-client-go/v8"
 	"golang.org/x/exp/constraints"
@@ -11,85 +11,96 @@ import (
 
 // Unmarshal parses aerospike record into a struct using "as" tags as bin names.
 func Unmarshal(record *aerospike.Record, v any) error {
-	if record == nil {
-		return nil
-	}
+	return defaultCodec.Unmarshal(record, v)
+}
 
-	rv := reflect.ValueOf(v)
-	indirect := reflect.Indirect(rv)
-	if rv.Kind() != reflect.Pointer || indirect.Kind() != reflect.Struct || rv.IsNil() {
+func (c *Codec) unmarshalStruct(field reflect.Value, v any) error {
+	mapVal := reflect.ValueOf(v)
+	if mapVal.Kind() != reflect.Map {
 		return errInputType
 	}
 
-	err := unmarshalStruct(indirect, map[string]any(record.Bins))
+	fields, err := c.structFields(field.Type())
 	if err != nil {
 		return err
 	}
 
+	for _, f := range fields {
+		rawVal := mapVal.MapIndex(reflect.ValueOf(f.tag.name))
+		if !rawVal.IsValid() {
+			continue
+		}
+		err := c.unmarshalField(field.Field(f.index), rawVal.Interface())
+		if err != nil {
+			return fmt.Errorf("error while parsing field %s: %w", field.Type().Field(f.index).Name, err)
+		}
+	}
+	if c.strict {
+		return c.unknownBin(mapVal, fields)
+	}
+
 	return nil
 }
 
-func unmarshalStruct(field reflect.Value, v any) error {
-	mapVal := reflect.ValueOf(v)
-	if mapVal.Kind() != reflect.Map {
-		return errInputType
+// unknownBin returns the error for the first bin of the map without a field.
+func (c *Codec) unknownBin(mapVal reflect.Value, fields []codecField) error {
+	for _, key := range mapVal.MapKeys() {
+		name, _ := key.Interface().(string)
+		if !slices.ContainsFunc(fields, func(f codecField) bool { return f.tag.name == name }) {
+			return fmt.Errorf("bin %v has no field: %w", key.Interface(), errInputType)
+		}
+	}
+
+	return nil
+}
+
+func (c *Codec) unmarshalField(fieldVal reflect.Value, val any) error {
+	if ok, err := c.decodeCustom(fieldVal, val); ok {
+		return err
 	}
 
 	var (
 		marshaled any
 		err       error
 	)
-	for i := range field.NumField() {
-		marshaled = nil
-		tag := field.Type().Field(i).Tag.Get(structTag)
-		if tag == "" {
-			continue
-		}
-		rawVal := mapVal.MapIndex(reflect.ValueOf(tag))
-		if !rawVal.IsValid() {
-			continue
-		}
-		val := rawVal.Interface()
-		fieldVal := field.Field(i)
-		isPointer := fieldVal.Kind() == reflect.Ptr
-		indirect := reflect.Indirect(fieldVal)
-		if isPointer {
-			indirect = reflect.Indirect(reflect.New(fieldVal.Type().Elem()))
-		}
-		switch {
-		case indirect.Type().PkgPath() == "time" && indirect.Type().Name() == "Time":
-			marshaled, err = parseTime(val)
-		case indirect.Kind() == reflect.Map:
-			marshaled, err = unmarshalMap(indirect, val)
-		case indirect.Kind() == reflect.Slice:
-			marshaled, err = unmarshalSlice(indirect, val)
-		case indirect.Kind() == reflect.Struct:
-			err = unmarshalStruct(indirect, val)
-		default:
-			marshaled, err = unmarshalScalarToKind(indirect.Kind(), val)
-		}
-		if err != nil {
-			return fmt.Errorf("error while parsing field %s: %w", field.Type().Field(i).Name, err)
-		}
-		if marshaled == nil {
-			continue
-		}
-		if isPointer {
-			fieldVal.Set(reflect.New(fieldVal.Type().Elem()))
+	isPointer := fieldVal.Kind() == reflect.Ptr
+	indirect := reflect.Indirect(fieldVal)
+	if isPointer {
+		indirect = reflect.Indirect(reflect.New(fieldVal.Type().Elem()))
+	}
+	switch {
+	case indirect.Type().PkgPath() == "time" && indirect.Type().Name() == "Time":
+		marshaled, err = c.decodeTime(val)
+	case indirect.Kind() == reflect.Map:
+		marshaled, err = c.unmarshalMap(indirect, val)
+	case indirect.Kind() == reflect.Slice:
+		marshaled, err = c.unmarshalSlice(indirect, val)
+	case indirect.Kind() == reflect.Struct:
+		err = c.unmarshalStruct(indirect, val)
+	default:
+		marshaled, err = c.unmarshalScalar(indirect.Type(), val)
+	}
+	if err != nil {
+		return err
+	}
+	if marshaled == nil {
+		return nil
+	}
+	if isPointer {
+		fieldVal.Set(reflect.New(fieldVal.Type().Elem()))
 
-			fieldVal.Elem().Set(reflect.ValueOf(marshaled).Convert(fieldVal.Type().Elem()))
-			continue
-		}
-		if !reflect.ValueOf(marshaled).CanConvert(fieldVal.Type()) {
-			return fmt.Errorf("cannot convert %v to %v: %w", marshaled, fieldVal.Type(), errInputType)
-		}
-		fieldVal.Set(reflect.ValueOf(marshaled).Convert(fieldVal.Type()))
+		fieldVal.Elem().Set(reflect.ValueOf(marshaled).Convert(fieldVal.Type().Elem()))
+		return nil
+	}
+	if !reflect.ValueOf(marshaled).CanConvert(fieldVal.Type()) {
+		return fmt.Errorf("cannot convert %v to %v: %w", marshaled, fieldVal.Type(), errInputType)
 	}
+	fieldVal.Set(reflect.ValueOf(marshaled).Convert(fieldVal.Type()))
 
 	return nil
 }
 
-func unmarshalSlice(field reflect.Value, v any) (any, error) {
+func (c *Codec) unmarshalSlice(field reflect.Value, v any) (any, error) {
 	if v == nil {
 		return nil, nil //nolint:nilnil
 	}
@@ -97,6 +108,17 @@ func unmarshalSlice(field reflect.Value, v any) (any, error) {
 
 	slice := reflect.MakeSlice(field.Type(), val.Len(), val.Cap())
 	for i := range val.Len() {
+		if ok, err := c.decodeCustom(slice.Index(i), val.Index(i).Interface()); ok {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if c.strict {
+			if err := checkScalar(slice.Index(i).Type(), val.Index(i).Interface()); err != nil {
+				return nil, err
+			}
+		}
 		if !val.Index(i).Elem().CanConvert(slice.Index(i).Type()) {
 			return nil, fmt.Errorf("cannot convert %v to %v: %w",
 				val.Index(i).Type(),
@@ -111,7 +133,7 @@ func unmarshalSlice(field reflect.Value, v any) (any, error) {
 	return slice.Interface(), nil
 }
 
-func unmarshalMap(field reflect.Value, v any) (any, error) {
+func (c *Codec) unmarshalMap(field reflect.Value, v any) (any, error) {
 	if v == nil {
 		return nil, nil //nolint:nilnil
 	}
@@ -120,22 +142,82 @@ func unmarshalMap(field reflect.Value, v any) (any, error) {
 	out := reflect.MakeMapWithSize(field.Type(), mapVal.Len())
 	iter := mapVal.MapRange()
 	for iter.Next() {
-		key, err := unmarshalScalarToKind(field.Type().Key().Kind(), iter.Key().Elem().Interface())
+		key, err := c.unmarshalMapEntry(field.Type().Key(), iter.Key().Elem().Interface())
 		if err != nil {
 			return nil, err
 		}
-		val, err := unmarshalScalarToKind(field.Type().Elem().Kind(), iter.Value().Elem().Interface())
+		val, err := c.unmarshalMapEntry(field.Type().Elem(), iter.Value().Elem().Interface())
 		if err != nil {
 			return nil, err
 		}
 
-		out.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(val))
+		out.SetMapIndex(key, val)
 	}
 	field.Set(out)
 
 	return out.Interface(), nil
 }
 
+// unmarshalMapEntry converts a map key or value into the type, which is registered or scalar.
+func (c *Codec) unmarshalMapEntry(typ reflect.Type, value any) (reflect.Value, error) {
+	out := reflect.New(typ).Elem()
+	if ok, err := c.decodeCustom(out, value); ok {
+		return out, err
+	}
+
+	scalar, err := c.unmarshalScalar(typ, value)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if scalar == nil || !reflect.TypeOf(scalar).ConvertibleTo(typ) {
+		return reflect.ValueOf(scalar), nil
+	}
+
+	return reflect.ValueOf(scalar).Convert(typ), nil
+}
+
+// unmarshalScalar converts the value into the scalar type, checking that it fits for strict codecs.
+func (c *Codec) unmarshalScalar(typ reflect.Type, value any) (any, error) {
+	if c.strict {
+		if err := checkScalar(typ, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return unmarshalScalarToKind(typ.Kind(), value)
+}
+
+// checkScalar fails if the value is not of the type the client reads values of the scalar type in,
+// or does not fit it.
+func checkScalar(typ reflect.Type, value any) error {
+	if value == nil {
+		return nil
+	}
+
+	fits := true
+	switch typ.Kind() {
+	case reflect.Bool:
+		_, fits = value.(bool)
+	case reflect.String:
+		_, fits = value.(string)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := value.(int)
+		fits = ok && !reflect.Zero(typ).OverflowInt(int64(i))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, ok := value.(int)
+		// Negative values are unsigned integers above math.MaxInt64 stored with the same bits.
+		fits = ok && (i >= 0 && !reflect.Zero(typ).OverflowUint(uint64(i)) || i < 0 && typ.Bits() == 64)
+	case reflect.Float32, reflect.Float64:
+		_, fits = value.(float64)
+	default:
+	}
+	if !fits {
+		return fmt.Errorf("value %v of type %T does not fit %s: %w", value, value, typ, errInputType)
+	}
+
+	return nil
+}
+
 func unmarshalScalarToKind(kind reflect.Kind, value any) (any, error) {
 	switch kind {
 	case reflect.Bool:
@@ -180,15 +262,3 @@ func toIntVal[T constraints.Integer](value any) T { //nolint:ireturn
 	intVal, _ := value.(int)
 	return T(intVal)
 }
-
-func parseTime(v any) (time.Time, error) {
-	if v == nil {
-		return time.Time{}, nil
-	}
-	timestamp, ok := v.(int)
-	if !ok {
-		return time.Time{}, fmt.Errorf("time must be an int: %w", errInputType)
-	}
-
-	return time.Unix(int64(timestamp), 0).UTC(), nil
-}
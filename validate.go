@@ -0,0 +1,186 @@
+package aerospike
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var errInvalidTag = errors.New("invalid tag")
+
+// ValidateType reports every problem with storing T, or the struct T points to, with the default codec,
+// e.g. invalid or duplicate bin names, unsupported field and map key types and invalid tag options.
+// Types containing themselves through pointers, slices or maps, e.g. trees, are valid. Problems are joined into one error, nil if there are none.
+// Call it in init for persisted types to find them at startup rather than on the first write.
+func ValidateType[T any]() error {
+	return defaultCodec.Validate(reflect.TypeFor[T]())
+}
+
+// ValidateReflectType is ValidateType for the type given as reflect.Type.
+func ValidateReflectType(typ reflect.Type) error {
+	return defaultCodec.Validate(typ)
+}
+
+// Validate reports every problem with storing the struct type, or the struct it points to,
+// with the codec, see ValidateType.
+func (c *Codec) Validate(typ reflect.Type) error {
+	if typ == nil || derefType(typ).Kind() != reflect.Struct {
+		return fmt.Errorf("type %v must be a struct or a pointer to a struct: %w", typ, errInputType)
+	}
+
+	v := &validator{codec: c, active: make(map[reflect.Type]bool)}
+	v.walkStruct(derefType(typ), derefType(typ).String(), true)
+
+	return errors.Join(v.problems...)
+}
+
+// validator walks the types a codec stores collecting problems.
+type validator struct {
+	codec    *Codec
+	problems []error
+	// active holds the structs being walked, so that recursive types are walked once.
+	active map[reflect.Type]bool
+}
+
+func (v *validator) report(path string, err error) {
+	v.problems = append(v.problems, fmt.Errorf("%s: %w", path, err))
+}
+
+// walkStruct checks the fields of the struct type, bins is true for the top-level struct,
+// whose field names are bin names rather than map keys.
+func (v *validator) walkStruct(typ reflect.Type, path string, bins bool) {
+	if v.active[typ] {
+		return
+	}
+	v.active[typ] = true
+	defer delete(v.active, typ)
+
+	names := make(map[string]string, typ.NumField())
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		fieldPath := path + "." + field.Name
+		if raw, ok := field.Tag.Lookup(v.codec.tagName); ok {
+			v.checkTag(field, raw, fieldPath)
+		}
+
+		tag := v.codec.fieldName(field)
+		if tag.name == "" {
+			if field.Anonymous && derefType(field.Type).Kind() == reflect.Struct &&
+				len(v.storedFields(derefType(field.Type))) > 0 {
+				v.report(fieldPath, fmt.Errorf("fields of embedded %s are not promoted, name the field to store it as a map: %w",
+					field.Type, errInvalidTag))
+			}
+			continue
+		}
+		if !field.IsExported() {
+			v.report(fieldPath, fmt.Errorf("unexported field cannot be stored as %q: %w", tag.name, errInvalidTag))
+			continue
+		}
+		if bins && len(tag.name) > maxBinNameLength {
+			v.report(fieldPath, fmt.Errorf("bin name %q is longer than %d bytes: %w", tag.name, maxBinNameLength, errBinName))
+		}
+		if other, ok := names[tag.name]; ok {
+			v.report(fieldPath, fmt.Errorf("name %q is used by %s too: %w", tag.name, other, errBinName))
+		}
+		names[tag.name] = field.Name
+
+		v.walkType(field.Type, fieldPath)
+	}
+}
+
+// storedFields returns the fields of the struct type the codec would store if it was named.
+func (v *validator) storedFields(typ reflect.Type) []codecField {
+	fields, _ := v.codec.structFields(typ)
+	return fields
+}
+
+func (v *validator) walkType(typ reflect.Type, path string) {
+	if v.codec.isRegistered(typ) || isTimeType(typ) || v.codec.implementsFallback(typ) {
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	case reflect.Pointer, reflect.Slice:
+		v.walkType(typ.Elem(), path)
+	case reflect.Map:
+		v.checkMapKey(typ.Key(), path)
+		v.walkType(typ.Elem(), path)
+	case reflect.Struct:
+		v.walkStruct(typ, path, false)
+	default:
+		v.report(path, fmt.Errorf("type %s is not supported: %w", typ, errInputType))
+	}
+}
+
+func (v *validator) checkMapKey(typ reflect.Type, path string) {
+	if v.codec.isRegistered(typ) || v.codec.implementsFallback(typ) {
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		v.report(path, fmt.Errorf("map key type %s is not supported: %w", typ, errInputType))
+	}
+}
+
+// checkTag reports unknown tag options, options with invalid values and record metadata options
+// on fields of unsupported types.
+func (v *validator) checkTag(field reflect.StructField, raw, path string) {
+	name, opts, _ := strings.Cut(raw, ",")
+	tag := parseTagValue(raw)
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		key, value, hasValue := strings.Cut(opt, "=")
+		switch key {
+		case tagOptionOmitEmpty, tagOptionKey, tagOptionGeneration, tagOptionTTL:
+			if hasValue {
+				v.report(path, fmt.Errorf("option %q takes no value: %w", key, errInvalidTag))
+			}
+		case tagOptionIndex:
+			if _, ok := indexTypes[value]; !ok {
+				v.report(path, fmt.Errorf("unknown index type %q: %w", value, errInvalidIndex))
+			}
+		case tagOptionCollection:
+			if _, ok := indexCollectionTypes[value]; !ok {
+				v.report(path, fmt.Errorf("unknown collection type %q: %w", value, errInvalidIndex))
+			}
+			if tag.index == "" {
+				v.report(path, fmt.Errorf("collection without index type: %w", errInvalidIndex))
+			}
+		default:
+			v.report(path, fmt.Errorf("unknown option %q: %w", opt, errInvalidTag))
+		}
+	}
+
+	if (tag.index != "" || tag.collection != "") && (name == "" || name == "-") {
+		v.report(path, fmt.Errorf("index on a field without bin name: %w", errInvalidIndex))
+	}
+	if tag.key && !isKeyType(field.Type) {
+		v.report(path, fmt.Errorf("key type %s is not supported: %w", field.Type, errInvalidTag))
+	}
+	if (tag.generation || tag.ttl) && !isIntegerKind(field.Type.Kind()) {
+		v.report(path, fmt.Errorf("generation and TTL fields must be integers, got %s: %w", field.Type, errInvalidTag))
+	}
+}
+
+// isKeyType reports whether values of the type can be user keys, see metaFields.keyValue.
+func isKeyType(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	case reflect.Slice:
+		return typ.Elem().Kind() == reflect.Uint8
+	default:
+		return false
+	}
+}
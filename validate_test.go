@@ -0,0 +1,107 @@
+package aerospike
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type validRecord struct {
+	ID       string            `as:",key"`
+	Gen      uint32            `as:",generation"`
+	Name     string            `as:"name,omitempty"`
+	Email    string            `as:"email,index=string"`
+	Tags     []string          `as:"tags,index=string,collection=list"`
+	At       time.Time         `as:"at"`
+	Level    level             `as:"level"`
+	Attrs    map[int]*validRef `as:"attrs"`
+	Next     *validRecord      `as:"next"`
+	Tree     treeItem          `as:"tree"`
+	internal chan int          //nolint:unused
+}
+
+type validRef struct {
+	Parent *validRecord `as:"parent"`
+}
+
+type invalidEmbedded struct {
+	Note string `as:"note"`
+}
+
+type invalidRecord struct {
+	invalidEmbedded
+
+	ID       float64           `as:",key"`
+	Gen      string            `as:",generation"`
+	Name     string            `as:"name,omitempty=true"`
+	Alias    string            `as:"name"`
+	LongName string            `as:"a_very_long_bin_name"`
+	Email    string            `as:"email,index=text,unique"`
+	Tags     []string          `as:"tags,collection=list"`
+	Done     chan bool         `as:"done"`
+	ByPoint  map[[2]int]string `as:"by_point"`
+	Hidden   string            `as:"-,index=string"`
+	secret   string            `as:"secret"` //nolint:unused
+	Nested   struct {
+		Fn func() `as:"fn"`
+	} `as:"nested"`
+}
+
+type treeItem struct {
+	Children []treeItem          `as:"children"`
+	ByName   map[string]treeItem `as:"by_name"`
+}
+
+func TestValidateType(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidateType[validRecord]())
+	require.NoError(t, ValidateType[*validRecord]())
+	require.ErrorIs(t, ValidateType[int](), errInputType)
+
+	err := ValidateReflectType(reflect.TypeFor[invalidRecord]())
+	for _, target := range []error{errBinName, errInputType, errInvalidTag, errInvalidIndex} {
+		require.ErrorIs(t, err, target)
+	}
+
+	var joined interface{ Unwrap() []error }
+	require.True(t, errors.As(err, &joined))
+	problems := make([]string, 0, len(joined.Unwrap()))
+	for _, problem := range joined.Unwrap() {
+		problems = append(problems, strings.TrimPrefix(problem.Error(), "aerospike.invalidRecord."))
+	}
+	require.Equal(t, []string{
+		"invalidEmbedded: fields of embedded aerospike.invalidEmbedded are not promoted, " +
+			"name the field to store it as a map: invalid tag",
+		"ID: key type float64 is not supported: invalid tag",
+		"Gen: generation and TTL fields must be integers, got string: invalid tag",
+		`Name: option "omitempty" takes no value: invalid tag`,
+		`Alias: name "name" is used by Name too: invalid bin name`,
+		`LongName: bin name "a_very_long_bin_name" is longer than 15 bytes: invalid bin name`,
+		`Email: unknown index type "text": invalid index declaration`,
+		`Email: unknown option "unique": invalid tag`,
+		"Tags: collection without index type: invalid index declaration",
+		"Done: type chan bool is not supported: wrong variable provided",
+		"ByPoint: map key type [2]int is not supported: wrong variable provided",
+		"Hidden: index on a field without bin name: invalid index declaration",
+		`secret: unexported field cannot be stored as "secret": invalid tag`,
+		"Nested.Fn: type func() is not supported: wrong variable provided",
+	}, problems)
+}
+
+func TestCodecValidate(t *testing.T) {
+	t.Parallel()
+
+	type untagged struct {
+		NotificationSettings string
+	}
+	typ := reflect.TypeFor[untagged]()
+
+	require.NoError(t, NewCodec().Validate(typ))
+	require.ErrorIs(t, NewCodec(WithNaming(SnakeCase)).Validate(typ), errBinName)
+	require.NoError(t, NewCodec(WithNaming(SnakeCase), WithShortNames()).Validate(typ))
+}